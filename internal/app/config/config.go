@@ -1,6 +1,75 @@
 package config
 
-import "os"
+import (
+	"flag"
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Database drivers.
+const (
+	// DriverPgx is PostgreSQL driver.
+	DriverPgx = "pgx"
+	// DriverMemory is in-memory storage driver.
+	DriverMemory = "memory"
+	// DriverSQLite is embedded SQLite driver.
+	DriverSQLite = "sqlite"
+)
+
+// RateLimit is token bucket limit of Count requests per Period, zero count
+// disables limit.
+type RateLimit struct {
+	Count  int
+	Period time.Duration
+}
+
+// ParseRateLimit parses rate limit like 60/1m, off disables limit.
+func ParseRateLimit(s string) (RateLimit, error) {
+	if s == "off" {
+		return RateLimit{}, nil
+	}
+	count, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q", s)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 1 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit count %q", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit period %q", s)
+	}
+	return RateLimit{Count: n, Period: d}, nil
+}
+
+// parsePrefixes parses comma separated addresses or prefixes.
+func parsePrefixes(s string) ([]netip.Prefix, error) {
+	var res []netip.Prefix
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); len(v) == 0 {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			a, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, netip.PrefixFrom(a, a.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, p.Masked())
+	}
+	return res, nil
+}
 
 // Config provides server configuration parameters.
 type Config struct {
@@ -8,6 +77,37 @@ type Config struct {
 	DBURI        string
 	DBDriver     string
 	MusicInfoURL string
+	// RetentionDays is days after which deleted songs are purged, zero
+	// keeps them forever.
+	RetentionDays int
+	// JWKSURL is JWKS file or URL verifying bearer tokens, empty disables
+	// bearer tokens.
+	JWKSURL string
+	// JWTAudience is required bearer token audience, empty skips check.
+	JWTAudience string
+	// JWTRoleClaim is bearer token claim holding caller role or roles.
+	JWTRoleClaim string
+	// JWKSRefresh is positive interval of JWKS reload.
+	JWKSRefresh time.Duration
+	// RateLimitRead limits reading requests of client.
+	RateLimitRead RateLimit
+	// RateLimitWrite limits changing requests of client.
+	RateLimitWrite RateLimit
+	// RateLimitLookup limits song add requests of client calling music
+	// info API.
+	RateLimitLookup RateLimit
+	// TrustedProxies are proxies whose X-Forwarded-For header is used to
+	// find client address.
+	TrustedProxies []netip.Prefix
+	// MetricsURI is admin listener address serving metrics, empty serves
+	// them with API to admins only.
+	MetricsURI string
+	// TraceExporter is otlp, stdout or file://path exporting trace spans,
+	// empty only propagates trace context.
+	TraceExporter string
+	// Args are command line arguments left after flags, they run command
+	// instead of server.
+	Args []string
 }
 
 // Setup calculates server configuration parameters.
@@ -25,6 +125,81 @@ func Setup() (*Config, error) {
 		cfg.MusicInfoURL = flagMusicInfoURL
 	}
 
-	cfg.DBDriver = "pgx"
+	cfg.RetentionDays = flagRetentionDays
+	if v := os.Getenv("RETENTION_DAYS"); len(v) > 0 {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RetentionDays = days
+	}
+
+	if cfg.JWKSURL = os.Getenv("JWKS_URL"); len(cfg.JWKSURL) == 0 {
+		cfg.JWKSURL = flagJWKSURL
+	}
+	if cfg.JWTAudience = os.Getenv("JWT_AUDIENCE"); len(cfg.JWTAudience) == 0 {
+		cfg.JWTAudience = flagJWTAudience
+	}
+	if cfg.JWTRoleClaim = os.Getenv("JWT_ROLE_CLAIM"); len(cfg.JWTRoleClaim) == 0 {
+		cfg.JWTRoleClaim = flagJWTRoleClaim
+	}
+	cfg.JWKSRefresh = flagJWKSRefresh
+	if v := os.Getenv("JWKS_REFRESH"); len(v) > 0 {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		cfg.JWKSRefresh = d
+	}
+	if cfg.JWKSRefresh <= 0 {
+		return nil, fmt.Errorf("invalid JWKS refresh interval %v", cfg.JWKSRefresh)
+	}
+
+	var err error
+	for _, v := range []struct {
+		limit *RateLimit
+		env   string
+		flag  string
+	}{
+		{&cfg.RateLimitRead, "RATE_LIMIT_READ", flagRateLimitRead},
+		{&cfg.RateLimitWrite, "RATE_LIMIT_WRITE", flagRateLimitWrite},
+		{&cfg.RateLimitLookup, "RATE_LIMIT_LOOKUP", flagRateLimitLookup},
+	} {
+		s := os.Getenv(v.env)
+		if len(s) == 0 {
+			s = v.flag
+		}
+		if *v.limit, err = ParseRateLimit(s); err != nil {
+			return nil, err
+		}
+	}
+	proxies := os.Getenv("TRUSTED_PROXIES")
+	if len(proxies) == 0 {
+		proxies = flagTrustedProxies
+	}
+	if cfg.TrustedProxies, err = parsePrefixes(proxies); err != nil {
+		return nil, err
+	}
+
+	if cfg.MetricsURI = os.Getenv("METRICS_URI"); len(cfg.MetricsURI) == 0 {
+		cfg.MetricsURI = flagMetricsURI
+	}
+	if cfg.TraceExporter = os.Getenv("TRACE_EXPORTER"); len(cfg.TraceExporter) == 0 {
+		cfg.TraceExporter = flagTraceExporter
+	}
+
+	cfg.Args = flag.Args()
+	cfg.DBDriver = driver(cfg.DBURI)
 	return &cfg, nil
 }
+
+// driver chooses database driver by database URI scheme.
+func driver(uri string) string {
+	switch {
+	case strings.HasPrefix(uri, "memory://"):
+		return DriverMemory
+	case strings.HasPrefix(uri, "sqlite://"):
+		return DriverSQLite
+	}
+	return DriverPgx
+}
@@ -1,18 +1,51 @@
 package config
 
-import "flag"
+import (
+	"flag"
+	"time"
+)
 
-const defaultURI = ":8080"
+const (
+	defaultURI          = ":8080"
+	defaultJWTRoleClaim = "role"
+	defaultJWKSRefresh  = time.Hour
+	defaultRateRead     = "300/1m"
+	defaultRateWrite    = "60/1m"
+	defaultRateLookup   = "10/1m"
+)
 
 var (
-	flagURI          string
-	flagDBURI        string
-	flagMusicInfoURL string
+	flagURI           string
+	flagDBURI         string
+	flagMusicInfoURL  string
+	flagRetentionDays int
+	flagJWKSURL       string
+	flagJWTAudience   string
+	flagJWTRoleClaim  string
+	flagJWKSRefresh   time.Duration
+
+	flagRateLimitRead   string
+	flagRateLimitWrite  string
+	flagRateLimitLookup string
+	flagTrustedProxies  string
+	flagMetricsURI      string
+	flagTraceExporter   string
 )
 
 func parseFlags() {
 	flag.StringVar(&flagURI, "a", defaultURI, "server URI")
-	flag.StringVar(&flagDBURI, "d", "", "database URI")
+	flag.StringVar(&flagDBURI, "d", "", "database URI, sqlite://path for sqlite, memory:// for in-memory storage")
 	flag.StringVar(&flagMusicInfoURL, "i", "", "music info URL")
+	flag.IntVar(&flagRetentionDays, "r", 0, "days to keep deleted songs, 0 keeps forever")
+	flag.StringVar(&flagJWKSURL, "j", "", "JWKS file or URL verifying bearer tokens, empty disables tokens")
+	flag.StringVar(&flagJWTAudience, "jwt-aud", "", "required bearer token audience, empty skips check")
+	flag.StringVar(&flagJWTRoleClaim, "jwt-role-claim", defaultJWTRoleClaim, "bearer token claim holding caller role")
+	flag.DurationVar(&flagJWKSRefresh, "jwks-refresh", defaultJWKSRefresh, "JWKS refresh interval")
+	flag.StringVar(&flagRateLimitRead, "rate-read", defaultRateRead, "reading requests per client like 300/1m, off disables")
+	flag.StringVar(&flagRateLimitWrite, "rate-write", defaultRateWrite, "changing requests per client, off disables")
+	flag.StringVar(&flagRateLimitLookup, "rate-lookup", defaultRateLookup, "song add requests per client, off disables")
+	flag.StringVar(&flagTrustedProxies, "trusted-proxies", "", "comma separated proxy addresses or prefixes")
+	flag.StringVar(&flagMetricsURI, "m", "", "admin URI serving metrics, empty serves them on server URI to admins")
+	flag.StringVar(&flagTraceExporter, "trace", "", "trace exporter otlp, stdout or file://path, empty disables export")
 	flag.Parse()
 }
@@ -0,0 +1,119 @@
+// Package cursor encodes songs list cursors as opaque strings.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// ErrInvalid is returned for malformed or foreign cursor.
+var ErrInvalid = errors.New("invalid cursor")
+
+// Default is songs list sort order used when none is requested.
+var Default = []models.SortKey{{Column: models.SortCreated}}
+
+// Order returns sort order, default one if sort is empty.
+func Order(sort []models.SortKey) []models.SortKey {
+	if len(sort) == 0 {
+		return Default
+	}
+	return sort
+}
+
+// Format returns sort order as comma separated columns, descending
+// ones prefixed with minus.
+func Format(sort []models.SortKey) string {
+	ss := make([]string, 0, len(sort))
+	for _, k := range Order(sort) {
+		if k.Desc {
+			ss = append(ss, "-"+k.Column)
+		} else {
+			ss = append(ss, k.Column)
+		}
+	}
+	return strings.Join(ss, ",")
+}
+
+// isTime reports whether sort column holds time.
+func isTime(column string) bool {
+	switch column {
+	case models.SortReleaseDate, models.SortCreated, models.SortUpdated:
+		return true
+	}
+	return false
+}
+
+// Encode returns opaque cursor string.
+func Encode(c models.Cursor) string {
+	b, _ := json.Marshal(c) // strings only, never fails
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses opaque cursor string issued for sort order.
+func Decode(s string, sort []models.SortKey) (models.Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return models.Cursor{}, ErrInvalid
+	}
+	var c models.Cursor
+	if err := json.Unmarshal(b, &c); err != nil || len(c.ID) == 0 {
+		return models.Cursor{}, ErrInvalid
+	}
+	sort = Order(sort)
+	if c.Sort != Format(sort) || len(c.Keys) != len(sort) {
+		return models.Cursor{}, ErrInvalid
+	}
+	for i, k := range sort {
+		if _, err := time.Parse(time.RFC3339Nano, c.Keys[i]); isTime(k.Column) && err != nil {
+			return models.Cursor{}, ErrInvalid
+		}
+	}
+	return c, nil
+}
+
+// Value returns song sort column value.
+func Value(d models.Song, column string) any {
+	switch column {
+	case models.SortGroup:
+		return d.Group
+	case models.SortSong:
+		return d.Song
+	case models.SortReleaseDate:
+		return d.ReleaseDate
+	case models.SortUpdated:
+		return d.UpdatedAt
+	}
+	return d.CreatedAt
+}
+
+// After returns cursor pointing to song in sort order.
+func After(sort []models.SortKey, d models.Song) models.Cursor {
+	sort = Order(sort)
+	c := models.Cursor{Sort: Format(sort), Keys: make([]string, len(sort)), ID: d.ID}
+	for i, k := range sort {
+		switch v := Value(d, k.Column).(type) {
+		case time.Time:
+			c.Keys[i] = v.UTC().Format(time.RFC3339Nano)
+		case string:
+			c.Keys[i] = v
+		}
+	}
+	return c
+}
+
+// Values returns cursor keys typed as sort columns values.
+func Values(c models.Cursor, sort []models.SortKey) []any {
+	vv := make([]any, len(c.Keys))
+	for i, k := range Order(sort) {
+		vv[i] = c.Keys[i]
+		if isTime(k.Column) {
+			vv[i], _ = time.Parse(time.RFC3339Nano, c.Keys[i]) // validated by Decode
+		}
+	}
+	return vv
+}
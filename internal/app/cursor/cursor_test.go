@@ -0,0 +1,88 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		sort []models.SortKey
+		want string
+	}{
+		{name: "positive test #1", want: "created_at"},
+		{
+			name: "positive test #2",
+			sort: []models.SortKey{{Column: models.SortReleaseDate, Desc: true},
+				{Column: models.SortGroup}, {Column: models.SortSong}},
+			want: "-release_date,group,song",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tt.sort); got != tt.want {
+				t.Errorf("Format() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	sort := []models.SortKey{{Column: models.SortReleaseDate, Desc: true}, {Column: models.SortGroup}}
+	c := models.Cursor{Sort: "-release_date,group", Keys: []string{"2006-07-16T00:00:00Z", "Muse"},
+		ID: "0824f9fb-7397-4f19-95d5-f9ce8bec75de"}
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name    string
+		s       string
+		sort    []models.SortKey
+		want    models.Cursor
+		wantErr bool
+	}{
+		{name: "positive test #1", s: Encode(c), sort: sort, want: c},
+		{
+			name: "positive test #2",
+			s:    raw(`{"s":"created_at","k":["2024-12-24T12:00:00Z"],"id":"1"}`),
+			want: models.Cursor{Sort: "created_at", Keys: []string{"2024-12-24T12:00:00Z"}, ID: "1"},
+		},
+		{name: "negative test #1", s: "!!!", wantErr: true},
+		{name: "negative test #2", s: raw(`[]`), wantErr: true},
+		{name: "negative test #3", s: raw(`{"s":"created_at","k":["2024-12-24T12:00:00Z"]}`), wantErr: true},
+		{name: "negative test #4", s: Encode(c), wantErr: true},
+		{name: "negative test #5", s: raw(`{"s":"created_at","k":["24.12.2024"],"id":"1"}`), wantErr: true},
+		{name: "negative test #6", s: raw(`{"s":"created_at","k":[],"id":"1"}`), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.s, tt.sort)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	sort := []models.SortKey{{Column: models.SortSong, Desc: true}, {Column: models.SortCreated}}
+	created := time.Date(2024, 12, 24, 12, 0, 0, 123456000, time.UTC)
+	d := models.Song{ID: "0824f9fb-7397-4f19-95d5-f9ce8bec75de", Song: "Hysteria", CreatedAt: created}
+	got, err := Decode(Encode(After(sort, d)), sort)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.ID != d.ID {
+		t.Errorf("After() id = %v, want %v", got.ID, d.ID)
+	}
+	if v, want := Values(got, sort), []any{"Hysteria", created}; !reflect.DeepEqual(v, want) {
+		t.Errorf("Values() = %v, want %v", v, want)
+	}
+}
@@ -0,0 +1,58 @@
+// Package diff compares song lyrics line by line.
+package diff
+
+import (
+	"strings"
+
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// Lines returns line based diff which turns text a into text b.
+func Lines(a, b string) []models.DiffLine {
+	x, y := split(a), split(b)
+	// lcs[i][j] is longest common subsequence length of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	d := make([]models.DiffLine, 0, max(len(x), len(y)))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			d = append(d, models.DiffLine{Op: models.DiffEqual, Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			d = append(d, models.DiffLine{Op: models.DiffDelete, Text: x[i]})
+			i++
+		default:
+			d = append(d, models.DiffLine{Op: models.DiffInsert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		d = append(d, models.DiffLine{Op: models.DiffDelete, Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		d = append(d, models.DiffLine{Op: models.DiffInsert, Text: y[j]})
+	}
+	return d
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,59 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+func TestLines(t *testing.T) {
+	type args struct{ a, b string }
+	tests := []struct {
+		name string
+		args args
+		want []models.DiffLine
+	}{
+		{
+			name: "positive test #1",
+			args: args{a: "Ooh baby\nYou set my soul alight", b: "Ooh baby\nYou set my soul alight"},
+			want: []models.DiffLine{
+				{Op: models.DiffEqual, Text: "Ooh baby"},
+				{Op: models.DiffEqual, Text: "You set my soul alight"}},
+		},
+		{
+			name: "positive test #2",
+			args: args{a: "Ooh baby\nYou set my sole alight\nOoh", b: "Ooh baby\nYou set my soul alight\nOoh\nGlaciers melting"},
+			want: []models.DiffLine{
+				{Op: models.DiffEqual, Text: "Ooh baby"},
+				{Op: models.DiffDelete, Text: "You set my sole alight"},
+				{Op: models.DiffInsert, Text: "You set my soul alight"},
+				{Op: models.DiffEqual, Text: "Ooh"},
+				{Op: models.DiffInsert, Text: "Glaciers melting"}},
+		},
+		{
+			name: "positive test #3",
+			args: args{a: "", b: "Ooh"},
+			want: []models.DiffLine{{Op: models.DiffInsert, Text: "Ooh"}},
+		},
+		{
+			name: "positive test #4",
+			args: args{a: "Ooh\nbaby", b: ""},
+			want: []models.DiffLine{
+				{Op: models.DiffDelete, Text: "Ooh"},
+				{Op: models.DiffDelete, Text: "baby"}},
+		},
+		{
+			name: "positive test #5",
+			args: args{a: "", b: ""},
+			want: []models.DiffLine{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.args.a, tt.args.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/service"
+)
+
+// PostAlbum godoc
+// @Summary Add album
+// @Description Add album of artist, artist alias is resolved and missing artist is created
+// @Tags Albums
+// @Accept json
+// @Produce json
+// @Param album body models.RequestAddAlbum true "Add album"
+// @Success 201 {object} models.Album "Album added"
+// @Header 201 {string} Location "Album URI"
+// @Failure 400 "Bad request"
+// @Failure 409 "Album of artist already exists"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums [post]
+func (h *HTTP) PostAlbum(w http.ResponseWriter, r *http.Request) {
+	var req models.RequestAddAlbum
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log.Info("JSON decode error", zap.Error(err))
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if len(req.Title) == 0 || len(req.Artist) == 0 {
+		logger.Log.Info("empty title or artist")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	d, err := h.s.AddAlbum(r.Context(), req)
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	w.Header().Set("Location", "/api/albums/"+d.ID)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&d); err != nil {
+		logger.Log.Info("JSON encode error", zap.Error(err))
+		return
+	}
+}
+
+// writeAlbumError writes status for album or playlist get service error.
+func writeAlbumError(w http.ResponseWriter, err error) {
+	if e, ok := status.FromError(err); ok {
+		switch e.Code() {
+		case codes.NotFound:
+			w.WriteHeader(http.StatusNoContent)
+		case codes.Internal:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// GetAlbum godoc
+// @Summary Get album
+// @Description Get album with artist name
+// @Tags Albums
+// @Produce json
+// @Param id path string true "Album id"
+// @Success 200 {object} models.Album "Album"
+// @Failure 204 "Album not found"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums/{id} [get]
+func (h *HTTP) GetAlbum(w http.ResponseWriter, r *http.Request) {
+	d, err := h.s.GetAlbum(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAlbumError(w, err)
+		return
+	}
+	writeJSON(w, &d, nil)
+}
+
+// GetAlbums godoc
+// @Summary Get albums
+// @Description Get albums ordered by title for certain page and page size
+// @Tags Albums
+// @Produce json
+// @Param artist_id query string false "Artist id"
+// @Param page query int false "Page number" default(1)
+// @Param size query int false "Page size" default(10)
+// @Success 200 {object} models.ResponseGetAlbums "Albums list"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums [get]
+func (h *HTTP) GetAlbums(w http.ResponseWriter, r *http.Request) {
+	page, size := service.DefaultPage, service.DefaultSizeAlbums
+	var err error
+	if v := r.URL.Query().Get("page"); len(v) > 0 {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			logger.Log.Info("invalid page")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("size"); len(v) > 0 {
+		if size, err = strconv.Atoi(v); err != nil || size < 1 {
+			logger.Log.Info("invalid size")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+	d, err := h.s.GetAlbums(r.Context(), r.URL.Query().Get("artist_id"), page, size)
+	writeJSON(w, &d, err)
+}
+
+// GetAlbumTracks godoc
+// @Summary Get album tracks
+// @Description Get album with songs ordered by track number
+// @Tags Albums
+// @Produce json
+// @Param id path string true "Album id"
+// @Success 200 {object} models.ResponseGetTracks "Album tracks"
+// @Failure 204 "Album not found"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums/{id}/tracks [get]
+func (h *HTTP) GetAlbumTracks(w http.ResponseWriter, r *http.Request) {
+	d, err := h.s.GetTracks(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAlbumError(w, err)
+		return
+	}
+	writeJSON(w, &d, nil)
+}
+
+// PostAlbumTrack godoc
+// @Summary Attach album track
+// @Description Place existing song on album position, song is moved from its previous album
+// @Tags Albums
+// @Accept json
+// @Param id path string true "Album id"
+// @Param track body models.RequestAttachTrack true "Attach track"
+// @Success 202 "Track attached"
+// @Failure 204 "Album or song not found"
+// @Failure 400 "Bad request"
+// @Failure 409 "Album position taken by other song"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /albums/{id}/tracks [post]
+func (h *HTTP) PostAlbumTrack(w http.ResponseWriter, r *http.Request) {
+	var req models.RequestAttachTrack
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log.Info("JSON decode error", zap.Error(err))
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if len(req.SongID) == 0 || req.Track < 1 {
+		logger.Log.Info("empty song id or invalid track")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if err := h.s.AttachTrack(r.Context(), r.PathValue("id"), req); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
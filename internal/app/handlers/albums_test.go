@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/service"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestHTTP_PostAlbum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	a := models.Album{Title: "Absolution", ArtistID: "2"}
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		code     int
+		location string
+	}{
+		{
+			name:     "positive test #1",
+			body:     `{"title": "Absolution", "artist": "Muse"}`,
+			code:     http.StatusCreated,
+			location: "/api/albums/1",
+		},
+		{name: "negative test #1", body: `{"title": "", "artist": "Muse"}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `{"title": "Absolution"}`, code: http.StatusBadRequest},
+		{name: "negative test #3", body: `bad json`, code: http.StatusBadRequest},
+		{
+			name: "negative test #4",
+			body: `{"title": "Absolution", "artist": "Muse"}`,
+			err:  storage.ErrUniqueViolation,
+			code: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				d := a
+				d.ID = "1"
+				ms.EXPECT().ResolveArtist(gomock.Any(), "Muse").Return(models.Artist{ID: "2", Name: "Muse"}, nil)
+				ms.EXPECT().AddAlbum(gomock.Any(), a).Return(d, tt.err)
+			}
+			w := httptest.NewRecorder()
+			h.PostAlbum(w, httptest.NewRequest(http.MethodPost, "/api/albums", strings.NewReader(tt.body)))
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+			assert.Equal(t, tt.location, res.Header.Get("Location"))
+		})
+	}
+}
+
+func TestHTTP_GetAlbum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", code: http.StatusOK},
+		{name: "negative test #1", err: sql.ErrNoRows, code: http.StatusNoContent},
+		{name: "negative test #2", err: errors.New("test"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().GetAlbum(gomock.Any(), "1").Return(models.Album{ID: "1"}, tt.err)
+			r := httptest.NewRequest(http.MethodGet, "/api/albums/1", nil)
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.GetAlbum(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_GetAlbums(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name  string
+		query string
+		err   error
+		code  int
+	}{
+		{name: "positive test #1", query: "?artist_id=2&page=2&size=1", code: http.StatusOK},
+		{name: "negative test #1", query: "?page=0", code: http.StatusBadRequest},
+		{name: "negative test #2", query: "?size=bad", code: http.StatusBadRequest},
+		{name: "negative test #3", query: "?artist_id=2&page=2&size=1", err: errors.New("test"),
+			code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().GetAlbums(gomock.Any(), "2", 2, 1).Return(models.ResponseGetAlbums{}, tt.err)
+			}
+			w := httptest.NewRecorder()
+			h.GetAlbums(w, httptest.NewRequest(http.MethodGet, "/api/albums"+tt.query, nil))
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_GetAlbumTracks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", code: http.StatusOK},
+		{name: "negative test #1", err: sql.ErrNoRows, code: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().GetAlbum(gomock.Any(), "1").Return(models.Album{ID: "1"}, tt.err)
+			if tt.err == nil {
+				ms.EXPECT().GetTracks(gomock.Any(), "1").Return([]models.Song{}, nil)
+			}
+			r := httptest.NewRequest(http.MethodGet, "/api/albums/1/tracks", nil)
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.GetAlbumTracks(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_PostAlbumTrack(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", body: `{"song_id": "2", "track": 3}`, code: http.StatusAccepted},
+		{name: "negative test #1", body: `{"song_id": "2", "track": 0}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `{"track": 3}`, code: http.StatusBadRequest},
+		{name: "negative test #3", body: `bad json`, code: http.StatusBadRequest},
+		{name: "negative test #4", body: `{"song_id": "2", "track": 3}`, err: storage.ErrNotAffected,
+			code: http.StatusNoContent},
+		{name: "negative test #5", body: `{"song_id": "2", "track": 3}`, err: storage.ErrUniqueViolation,
+			code: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().AttachTrack(gomock.Any(), "1", "2", 3).Return(tt.err)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/albums/1/tracks", strings.NewReader(tt.body))
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.PostAlbumTrack(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
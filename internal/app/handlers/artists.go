@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/service"
+)
+
+// artistRequest parses artist add or update request, ok is false if bad
+// request is sent.
+func artistRequest(w http.ResponseWriter, r *http.Request) (models.RequestArtist, bool) {
+	var req models.RequestArtist
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log.Info("JSON decode error", zap.Error(err))
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return req, false
+	}
+	if len(req.Name) == 0 || slices.Contains(req.Aliases, "") || req.Formed < 0 {
+		logger.Log.Info("empty name or alias or negative formed year")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+// writeChangeError writes status for artist, album, playlist or song tags
+// change service error.
+func writeChangeError(w http.ResponseWriter, err error) {
+	if e, ok := status.FromError(err); ok {
+		switch e.Code() {
+		case codes.NotFound:
+			w.WriteHeader(http.StatusNoContent)
+		case codes.AlreadyExists, codes.FailedPrecondition:
+			w.WriteHeader(http.StatusConflict)
+		case codes.Internal:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// PostArtist godoc
+// @Summary Add artist
+// @Description Add artist with aliases, names and aliases are unique ignoring case
+// @Tags Artists
+// @Accept json
+// @Produce json
+// @Param artist body models.RequestArtist true "Add artist"
+// @Success 201 {object} models.Artist "Artist added"
+// @Header 201 {string} Location "Artist URI"
+// @Failure 400 "Bad request"
+// @Failure 409 "Artist name or alias already exists"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /artists [post]
+func (h *HTTP) PostArtist(w http.ResponseWriter, r *http.Request) {
+	req, ok := artistRequest(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.s.AddArtist(r.Context(), req)
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	w.Header().Set("Location", "/api/artists/"+d.ID)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&d); err != nil {
+		logger.Log.Info("JSON encode error", zap.Error(err))
+		return
+	}
+}
+
+// GetArtist godoc
+// @Summary Get artist
+// @Description Get artist with aliases
+// @Tags Artists
+// @Produce json
+// @Param id path string true "Artist id"
+// @Success 200 {object} models.Artist "Artist"
+// @Failure 204 "Artist not found"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /artists/{id} [get]
+func (h *HTTP) GetArtist(w http.ResponseWriter, r *http.Request) {
+	d, err := h.s.GetArtist(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.NotFound:
+				w.WriteHeader(http.StatusNoContent)
+			case codes.Internal:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+	writeJSON(w, &d, nil)
+}
+
+// GetArtists godoc
+// @Summary Get artists
+// @Description Get artists ordered by name for certain page and page size
+// @Tags Artists
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param size query int false "Page size" default(10)
+// @Success 200 {object} models.ResponseGetArtists "Artists list"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /artists [get]
+func (h *HTTP) GetArtists(w http.ResponseWriter, r *http.Request) {
+	page, size := service.DefaultPage, service.DefaultSizeArtists
+	var err error
+	if v := r.URL.Query().Get("page"); len(v) > 0 {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			logger.Log.Info("invalid page")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("size"); len(v) > 0 {
+		if size, err = strconv.Atoi(v); err != nil || size < 1 {
+			logger.Log.Info("invalid size")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+	d, err := h.s.GetArtists(r.Context(), page, size)
+	writeJSON(w, &d, err)
+}
+
+// PutArtist godoc
+// @Summary Update artist
+// @Description Replace artist data and aliases, songs of renamed artist get new group
+// @Tags Artists
+// @Accept json
+// @Param id path string true "Artist id"
+// @Param artist body models.RequestArtist true "Update artist"
+// @Success 202 "Artist updated"
+// @Failure 204 "Artist not found"
+// @Failure 400 "Bad request"
+// @Failure 409 "Artist name, alias or renamed song already exists"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /artists/{id} [put]
+func (h *HTTP) PutArtist(w http.ResponseWriter, r *http.Request) {
+	req, ok := artistRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.UpdateArtist(r.Context(), r.PathValue("id"), req); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeleteArtist godoc
+// @Summary Delete artist
+// @Description Delete artist without songs, deleted songs included
+// @Tags Artists
+// @Param id path string true "Artist id"
+// @Success 202 "Artist deleted"
+// @Failure 204 "Artist not found"
+// @Failure 409 "Artist has songs"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /artists/{id} [delete]
+func (h *HTTP) DeleteArtist(w http.ResponseWriter, r *http.Request) {
+	if err := h.s.DeleteArtist(r.Context(), r.PathValue("id")); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/service"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestHTTP_PostArtist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	a := models.Artist{Name: "The Beatles", Aliases: []string{"Beatles"}, Country: "GB", Formed: 1960}
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		code     int
+		location string
+	}{
+		{
+			name:     "positive test #1",
+			body:     `{"name": "The Beatles", "aliases": ["Beatles"], "country": "GB", "formed_year": 1960}`,
+			code:     http.StatusCreated,
+			location: "/api/artists/1",
+		},
+		{name: "negative test #1", body: `{"name": ""}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `{"name": "Queen", "aliases": [""]}`, code: http.StatusBadRequest},
+		{name: "negative test #3", body: `bad json`, code: http.StatusBadRequest},
+		{
+			name: "negative test #4",
+			body: `{"name": "The Beatles", "aliases": ["Beatles"], "country": "GB", "formed_year": 1960}`,
+			err:  storage.ErrUniqueViolation,
+			code: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				d := a
+				d.ID = "1"
+				ms.EXPECT().AddArtist(gomock.Any(), a).Return(d, tt.err)
+			}
+			w := httptest.NewRecorder()
+			h.PostArtist(w, httptest.NewRequest(http.MethodPost, "/api/artists", strings.NewReader(tt.body)))
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+			assert.Equal(t, tt.location, res.Header.Get("Location"))
+		})
+	}
+}
+
+func TestHTTP_GetArtist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", code: http.StatusOK},
+		{name: "negative test #1", err: sql.ErrNoRows, code: http.StatusNoContent},
+		{name: "negative test #2", err: errors.New("test"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().GetArtist(gomock.Any(), "1").Return(models.Artist{ID: "1"}, tt.err)
+			r := httptest.NewRequest(http.MethodGet, "/api/artists/1", nil)
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.GetArtist(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_GetArtists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name  string
+		query string
+		page  int
+		size  int
+		code  int
+	}{
+		{name: "positive test #1", page: 1, size: 10, code: http.StatusOK},
+		{name: "positive test #2", query: "?page=2&size=5", page: 2, size: 5, code: http.StatusOK},
+		{name: "negative test #1", query: "?page=0", code: http.StatusBadRequest},
+		{name: "negative test #2", query: "?size=x", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code == http.StatusOK {
+				ms.EXPECT().GetArtists(gomock.Any(), tt.page, tt.size).Return(models.ResponseGetArtists{}, nil)
+			}
+			w := httptest.NewRecorder()
+			h.GetArtists(w, httptest.NewRequest(http.MethodGet, "/api/artists"+tt.query, nil))
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_PutArtist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", body: `{"name": "Queen"}`, code: http.StatusAccepted},
+		{name: "negative test #1", body: `{"name": "Queen", "formed_year": -1}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `{"name": "Queen"}`, err: storage.ErrNotAffected, code: http.StatusNoContent},
+		{name: "negative test #3", body: `{"name": "Queen"}`, err: storage.ErrUniqueViolation, code: http.StatusConflict},
+		{name: "negative test #4", body: `{"name": "Queen"}`, err: errors.New("test"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().UpdateArtist(gomock.Any(), models.Artist{ID: "1", Name: "Queen"}).Return(tt.err)
+			}
+			r := httptest.NewRequest(http.MethodPut, "/api/artists/1", strings.NewReader(tt.body))
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.PutArtist(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_DeleteArtist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", code: http.StatusAccepted},
+		{name: "negative test #1", err: storage.ErrNotAffected, code: http.StatusNoContent},
+		{name: "negative test #2", err: storage.ErrArtistInUse, code: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().DeleteArtist(gomock.Any(), "1").Return(tt.err)
+			r := httptest.NewRequest(http.MethodDelete, "/api/artists/1", nil)
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.DeleteArtist(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/roles"
+)
+
+// APIKeyHeader is request header carrying API key.
+const APIKeyHeader = "X-API-Key"
+
+type callerKey struct{}
+
+// WithCaller returns context carrying authenticated caller.
+func WithCaller(ctx context.Context, c models.Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// Caller returns authenticated caller of request context.
+func Caller(ctx context.Context) (models.Caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(models.Caller)
+	return c, ok
+}
+
+// WithAuth authenticates caller by bearer token or API key, puts caller on
+// request context and hands caller name to WithLogging.
+func (h *HTTP) WithAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var c models.Caller
+		var err error
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			c, err = h.s.AuthenticateToken(r.Context(), token)
+		} else if key := r.Header.Get(APIKeyHeader); len(key) > 0 {
+			c, err = h.s.Authenticate(r.Context(), key)
+		} else {
+			logger.Log.Info("missing credentials")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			if status.Code(err) == codes.Unauthenticated {
+				logger.Log.Info("invalid credentials")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if lw, ok := w.(*loggingResponseWriter); ok {
+			lw.responseData.caller = c.Name
+		}
+		next.ServeHTTP(w, r.WithContext(WithCaller(r.Context(), c)))
+	})
+}
+
+// WithRole lets through callers having role granting access required by
+// route, others get forbidden response naming both roles.
+func WithRole(required string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, _ := Caller(r.Context())
+			if !roles.Allows(c.Role, required) {
+				logger.Log.Info("forbidden", zap.String("role", c.Role), zap.String("required", required))
+				w.Header().Set("Content-type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				d := models.ResponseForbidden{Error: "forbidden", Role: c.Role, RequiredRole: required}
+				if err := json.NewEncoder(w).Encode(&d); err != nil {
+					logger.Log.Info("JSON encode error", zap.Error(err))
+				}
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/roles"
+	"github.com/xEgorka/project4/internal/app/service"
+)
+
+func TestHTTP_WithAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name   string
+		key    string
+		err    error
+		code   int
+		caller string
+	}{
+		{name: "positive test #1", key: "secret", code: http.StatusOK, caller: "ci"},
+		{name: "negative test #1", code: http.StatusUnauthorized},
+		{name: "negative test #2", key: "bad", err: sql.ErrNoRows, code: http.StatusUnauthorized},
+		{name: "negative test #3", key: "secret", err: errors.New("test"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.key) > 0 {
+				ms.EXPECT().GetAPIKey(gomock.Any(), gomock.Any()).Return(models.APIKey{ID: "1", Name: "ci"}, tt.err)
+			}
+			var got models.Caller
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = Caller(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			r := httptest.NewRequest(http.MethodDelete, "/api/song/1", nil)
+			if len(tt.key) > 0 {
+				r.Header.Set(APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			data := &responseData{}
+			h.WithAuth(next).ServeHTTP(&loggingResponseWriter{ResponseWriter: rec, responseData: data}, r)
+			res := rec.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+			assert.Equal(t, tt.caller, got.Name)
+			assert.Equal(t, tt.caller, data.caller)
+		})
+	}
+}
+
+func TestHTTP_WithAuth_bearer(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kty":"oct","kid":"h1","k":"`+
+		base64.RawURLEncoding.EncodeToString(secret)+`"}]}`), 0o600))
+	cfg := &config.Config{JWKSURL: path, JWTRoleClaim: "role", JWKSRefresh: time.Hour}
+	h := NewHTTP(service.New(cfg, nil, requests.New(cfg)))
+	sign := func(key []byte) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "alice", "role": roles.Editor, "exp": time.Now().Add(time.Minute).Unix()})
+		tok.Header["kid"] = "h1"
+		v, err := tok.SignedString(key)
+		require.NoError(t, err)
+		return v
+	}
+	tests := []struct {
+		name   string
+		token  string
+		code   int
+		caller models.Caller
+	}{
+		{name: "positive test #1", token: sign(secret), code: http.StatusOK,
+			caller: models.Caller{ID: "alice", Name: "alice", Role: roles.Editor}},
+		{name: "negative test #1", token: sign([]byte("other")), code: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got models.Caller
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = Caller(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			r := httptest.NewRequest(http.MethodGet, "/api/songs", nil)
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			h.WithAuth(next).ServeHTTP(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+			assert.Equal(t, tt.caller, got)
+		})
+	}
+}
+
+func TestWithRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   *models.Caller
+		required string
+		code     int
+		body     string
+	}{
+		{name: "positive test #1", caller: &models.Caller{Role: roles.Editor}, required: roles.Reader,
+			code: http.StatusOK},
+		{name: "positive test #2", caller: &models.Caller{Role: roles.Admin}, required: roles.Admin,
+			code: http.StatusOK},
+		{name: "negative test #1", caller: &models.Caller{Role: roles.Reader}, required: roles.Editor,
+			code: http.StatusForbidden, body: `{"error":"forbidden","role":"reader","required_role":"editor"}`},
+		{name: "negative test #2", required: roles.Reader,
+			code: http.StatusForbidden, body: `{"error":"forbidden","role":"","required_role":"reader"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+			r := httptest.NewRequest(http.MethodPost, "/api/song", nil)
+			if tt.caller != nil {
+				r = r.WithContext(WithCaller(r.Context(), *tt.caller))
+			}
+			w := httptest.NewRecorder()
+			WithRole(tt.required)(next).ServeHTTP(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+			if len(tt.body) > 0 {
+				assert.JSONEq(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// dateLayouts lists accepted date query parameter layouts.
+var dateLayouts = []string{"02.01.2006", time.DateOnly, time.RFC3339}
+
+// parseDate parses dd.mm.yyyy or ISO 8601 date, time part is dropped.
+func parseDate(param, v string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s must be dd.mm.yyyy or yyyy-mm-dd date, got %q", param, v)
+}
+
+// releaseRange returns release date range set by released_after,
+// released_before, year and decade query parameters, from is
+// inclusive and to is exclusive, zero bound is open. Several
+// parameters narrow range down.
+func releaseRange(q url.Values) (from, to time.Time, err error) {
+	narrow := func(f, t time.Time) {
+		if from.IsZero() || f.After(from) {
+			from = f
+		}
+		if to.IsZero() || (!t.IsZero() && t.Before(to)) {
+			to = t
+		}
+	}
+	if v := q.Get("released_after"); len(v) > 0 {
+		d, err := parseDate("released_after", v)
+		if err != nil {
+			return from, to, err
+		}
+		narrow(d.AddDate(0, 0, 1), time.Time{})
+	}
+	if v := q.Get("released_before"); len(v) > 0 {
+		d, err := parseDate("released_before", v)
+		if err != nil {
+			return from, to, err
+		}
+		narrow(time.Time{}, d)
+	}
+	if v := q.Get("year"); len(v) > 0 {
+		y, err := strconv.Atoi(v)
+		if err != nil || y < 1 || y > 9999 {
+			return from, to, fmt.Errorf("year must be number from 1 to 9999, got %q", v)
+		}
+		narrow(time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(y+1, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	if v := q.Get("decade"); len(v) > 0 {
+		y, err := strconv.Atoi(v)
+		if err != nil || y < 1 || y > 9990 || y%10 != 0 {
+			return from, to, fmt.Errorf("decade must be first year of decade like 1990, got %q", v)
+		}
+		narrow(time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(y+10, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	return from, to, nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseDate(t *testing.T) {
+	want := time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		v       string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "positive test #1", v: "16.07.2006", want: want},
+		{name: "positive test #2", v: "2006-07-16", want: want},
+		{name: "positive test #3", v: "2006-07-16T23:30:00+03:00", want: want},
+		{name: "negative test #1", v: "16/07/2006", wantErr: true},
+		{name: "negative test #2", v: "2006-13-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDate("released_after", tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				assert.Contains(t, err.Error(), "released_after")
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_releaseRange(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name     string
+		query    string
+		from, to time.Time
+		wantErr  bool
+	}{
+		{name: "positive test #1"},
+		{name: "positive test #2", query: "released_after=16.07.2006", from: day(2006, 7, 17)},
+		{name: "positive test #3", query: "released_before=2006-07-16", to: day(2006, 7, 16)},
+		{name: "positive test #4", query: "year=2006", from: day(2006, 1, 1), to: day(2007, 1, 1)},
+		{name: "positive test #5", query: "decade=1990", from: day(1990, 1, 1), to: day(2000, 1, 1)},
+		{
+			name:  "positive test #6",
+			query: "decade=2000&released_after=2003-12-31&released_before=2008-01-01",
+			from:  day(2004, 1, 1),
+			to:    day(2008, 1, 1),
+		},
+		{name: "positive test #7", query: "year=2006&released_before=2010-01-01", from: day(2006, 1, 1), to: day(2007, 1, 1)},
+		{name: "negative test #1", query: "released_after=bad", wantErr: true},
+		{name: "negative test #2", query: "released_before=32.01.2006", wantErr: true},
+		{name: "negative test #3", query: "year=0", wantErr: true},
+		{name: "negative test #4", query: "decade=1995", wantErr: true},
+		{name: "negative test #5", query: "decade=90s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				panic(err)
+			}
+			from, to, err := releaseRange(q)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("releaseRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil {
+				assert.Equal(t, tt.from, from)
+				assert.Equal(t, tt.to, to)
+			}
+		})
+	}
+}
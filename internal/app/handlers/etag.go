@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+)
+
+// etag formats song version as strong entity tag.
+func etag(version int) string { return `"` + strconv.Itoa(version) + `"` }
+
+// ifMatch parses If-Match header into expected song version, zero
+// version stands for absent header or any version. Single entity tag is
+// supported, ok is false if error response is sent.
+func ifMatch(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if len(v) == 0 || v == "*" {
+		return 0, true
+	}
+	if strings.Contains(v, ",") {
+		logger.Log.Info("multiple entity tags in If-Match")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return 0, false
+	}
+	version, err := strconv.Atoi(strings.Trim(v, `"`))
+	if err != nil || version < 1 || v != etag(version) {
+		// weak or foreign tag never matches strong comparison
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return 0, false
+	}
+	return version, true
+}
+
+// missing responds to change of song not found, If-Match header can not
+// match missing song, so 412 response is sent instead of 204 then.
+func missing(w http.ResponseWriter, r *http.Request) {
+	if len(r.Header.Get("If-Match")) > 0 {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notModified sets ETag header for song version and reports whether
+// If-None-Match header matches it, in that case 304 response is sent.
+func notModified(w http.ResponseWriter, r *http.Request, version int) bool {
+	tag := etag(version)
+	w.Header().Set("ETag", tag)
+	for _, v := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/") // weak comparison
+		if v == "*" || v == tag {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
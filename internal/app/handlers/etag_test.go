@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ifMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantOk  bool
+		wantErr int
+	}{
+		{name: "positive test #1", wantOk: true},
+		{name: "positive test #2", header: "*", wantOk: true},
+		{name: "positive test #3", header: `"3"`, want: 3, wantOk: true},
+		{name: "negative test #1", header: `W/"3"`, wantErr: http.StatusPreconditionFailed},
+		{name: "negative test #2", header: `"abc"`, wantErr: http.StatusPreconditionFailed},
+		{name: "negative test #3", header: `3`, wantErr: http.StatusPreconditionFailed},
+		{name: "negative test #4", header: `"3", "4"`, wantErr: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/api/song/{id}", nil)
+			if len(tt.header) > 0 {
+				r.Header.Set("If-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+			got, ok := ifMatch(w, r)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+			if !ok {
+				assert.Equal(t, tt.wantErr, w.Code)
+			}
+		})
+	}
+}
+
+func Test_missing(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{name: "positive test #1", want: http.StatusNoContent},
+		{name: "positive test #2", header: `"3"`, want: http.StatusPreconditionFailed},
+		{name: "positive test #3", header: "*", want: http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/api/song/{id}", nil)
+			if len(tt.header) > 0 {
+				r.Header.Set("If-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+			missing(w, r)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func Test_notModified(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "positive test #1", header: `"2"`, want: true},
+		{name: "positive test #2", header: `"1", W/"2"`, want: true},
+		{name: "positive test #3", header: "*", want: true},
+		{name: "negative test #1"},
+		{name: "negative test #2", header: `"1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/song/{id}/text", nil)
+			if len(tt.header) > 0 {
+				r.Header.Set("If-None-Match", tt.header)
+			}
+			w := httptest.NewRecorder()
+			assert.Equal(t, tt.want, notModified(w, r, 2))
+			assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+			if tt.want {
+				assert.Equal(t, http.StatusNotModified, w.Code)
+			}
+		})
+	}
+}
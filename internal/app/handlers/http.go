@@ -1,15 +1,23 @@
 package handlers
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/xEgorka/project4/internal/app/cursor"
 	"github.com/xEgorka/project4/internal/app/logger"
 	"github.com/xEgorka/project4/internal/app/models"
 	"github.com/xEgorka/project4/internal/app/service"
@@ -23,16 +31,20 @@ func NewHTTP(service *service.Service) HTTP { return HTTP{s: service} }
 
 // PostSong godoc
 // @Summary Add song
-// @Description Add song to library
+// @Description Add song to library, group alias is replaced with artist name and missing artist is created
 // @Tags Songs
 // @Accept json
 // @Produce json
 // @Param song body models.RequestAddSong true "Add song"
-// @Success 200 {object} models.Song "Song added"
+// @Param force query bool false "Add song despite near duplicates"
+// @Success 201 {object} models.Song "Song added"
+// @Header 201 {string} Location "Song URI"
 // @Failure 400 "Bad request"
-// @Failure 409 "Song already exists"
+// @Failure 409 {object} models.ResponseNearDuplicates "Song already exists, near duplicates are listed unless force is set"
 // @Failure 410 "Song already deleted"
 // @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /song [post]
 func (h *HTTP) PostSong(w http.ResponseWriter, r *http.Request) {
 	var req models.RequestAddSong
@@ -46,7 +58,22 @@ func (h *HTTP) PostSong(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
-	d, err := h.s.Add(r.Context(), req)
+	force, err := strconv.ParseBool(cmp.Or(r.URL.Query().Get("force"), "false"))
+	if err != nil {
+		logger.Log.Info("invalid force")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	d, err := h.s.Add(r.Context(), req, force)
+	var near *service.NearDuplicatesError
+	if errors.As(err, &near) {
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		if err := json.NewEncoder(w).Encode(models.ResponseNearDuplicates{NearDuplicates: near.Songs}); err != nil {
+			logger.Log.Info("JSON encode error", zap.Error(err))
+		}
+		return
+	}
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
@@ -61,6 +88,48 @@ func (h *HTTP) PostSong(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	w.Header().Set("Content-type", "application/json")
+	w.Header().Set("Location", "/api/song/"+d.ID)
+	w.Header().Set("ETag", etag(d.Version))
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&d); err != nil {
+		logger.Log.Info("JSON encode error", zap.Error(err))
+		return
+	}
+}
+
+// GetSong godoc
+// @Summary Get song
+// @Description Get song from library
+// @Tags Songs
+// @Produce json
+// @Param id path string true "Song id"
+// @Param If-None-Match header string false "Cached song ETag"
+// @Success 200 {object} models.Song "Song"
+// @Header 200 {string} ETag "Song version"
+// @Failure 204 "Song not found"
+// @Failure 304 "Song not modified"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /song/{id} [get]
+func (h *HTTP) GetSong(w http.ResponseWriter, r *http.Request) {
+	d, err := h.s.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.NotFound:
+				w.WriteHeader(http.StatusNoContent)
+			case codes.Internal:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	if notModified(w, r, d.Version) {
+		return
+	}
 	w.Header().Set("Content-type", "application/json")
 	if err := json.NewEncoder(w).Encode(&d); err != nil {
 		logger.Log.Info("JSON encode error", zap.Error(err))
@@ -77,10 +146,14 @@ func (h *HTTP) PostSong(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path string true "Song id"
 // @Param song body models.RequestUpdateSong true "Update song"
+// @Param If-Match header string false "Expected song ETag"
 // @Success 202 "Song updated"
 // @Failure 204 "Song not found"
 // @Failure 400 "Bad request"
+// @Failure 412 "Song version mismatch or song not found with If-Match"
 // @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /song/{id} [put]
 func (h *HTTP) PutSong(w http.ResponseWriter, r *http.Request) {
 	var req models.RequestUpdateSong
@@ -96,11 +169,113 @@ func (h *HTTP) PutSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.s.Update(r.Context(), r.PathValue("id"), req); err != nil {
+	version, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.Update(r.Context(), r.PathValue("id"), version, req); err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
 			case codes.NotFound:
-				w.WriteHeader(http.StatusNoContent)
+				missing(w, r)
+			case codes.FailedPrecondition:
+				w.WriteHeader(http.StatusPreconditionFailed)
+			case codes.Internal:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// mergePatchType is JSON merge patch media type.
+const mergePatchType = "application/merge-patch+json"
+
+// mergePatch parses JSON merge patch of song, null members are rejected
+// since song fields can not be removed.
+func mergePatch(body io.Reader) (models.RequestPatchSong, error) {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return models.RequestPatchSong{}, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return models.RequestPatchSong{}, err
+	}
+	for k, v := range members {
+		switch k {
+		case "group", "song", "release_date", "text", "link":
+		default:
+			return models.RequestPatchSong{}, fmt.Errorf("member %q can not be patched", k)
+		}
+		if string(v) == "null" {
+			return models.RequestPatchSong{}, fmt.Errorf("member %q can not be removed", k)
+		}
+	}
+	var d models.RequestPatchSong
+	if err := json.Unmarshal(b, &d); err != nil {
+		return models.RequestPatchSong{}, err
+	}
+	for _, v := range []*string{d.Group, d.Song, d.Text, d.Link} {
+		if v != nil && len(*v) == 0 {
+			return models.RequestPatchSong{}, errors.New("empty member")
+		}
+	}
+	if d.ReleaseDate != nil && d.ReleaseDate.IsZero() {
+		return models.RequestPatchSong{}, errors.New("empty release date")
+	}
+	return d, nil
+}
+
+// PatchSong godoc
+// @Summary Patch song
+// @Description Partially update song in library with JSON merge patch, renaming included
+// @Tags Songs
+// @Accept json
+// @Accept application/merge-patch+json
+// @Param id path string true "Song id"
+// @Param song body models.RequestPatchSong true "Patch song"
+// @Param If-Match header string false "Expected song ETag"
+// @Success 202 "Song updated"
+// @Failure 204 "Song not found"
+// @Failure 400 "Bad request"
+// @Failure 409 "Song already exists"
+// @Failure 412 "Song version mismatch or song not found with If-Match"
+// @Failure 415 "Unsupported media type"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /song/{id} [patch]
+func (h *HTTP) PatchSong(w http.ResponseWriter, r *http.Request) {
+	if ct := r.Header.Get("Content-Type"); len(ct) > 0 {
+		t, _, err := mime.ParseMediaType(ct)
+		if err != nil || (t != mergePatchType && t != "application/json") {
+			logger.Log.Info("unsupported media type", zap.String("type", ct))
+			http.Error(w, "Unsupported media type", http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+	req, err := mergePatch(r.Body)
+	if err != nil {
+		logger.Log.Info("invalid merge patch", zap.Error(err))
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	version, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.Patch(r.Context(), r.PathValue("id"), version, req); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.NotFound:
+				missing(w, r)
+			case codes.FailedPrecondition:
+				w.WriteHeader(http.StatusPreconditionFailed)
+			case codes.AlreadyExists:
+				w.WriteHeader(http.StatusConflict)
 			case codes.Internal:
 				w.WriteHeader(http.StatusInternalServerError)
 			}
@@ -112,15 +287,65 @@ func (h *HTTP) PutSong(w http.ResponseWriter, r *http.Request) {
 
 // DeleteSong godoc
 // @Summary Delete song
-// @Description Delete song from library
+// @Description Delete song from library, purged song is removed permanently
 // @Tags Songs
 // @Param id path string true "Song id"
+// @Param purge query bool false "Remove permanently" default(false)
+// @Param If-Match header string false "Expected song ETag"
 // @Success 202 "Song deleted"
 // @Failure 204 "Song not found"
+// @Failure 400 "Bad request"
+// @Failure 412 "Song version mismatch or song not found with If-Match"
 // @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /song/{id} [delete]
 func (h *HTTP) DeleteSong(w http.ResponseWriter, r *http.Request) {
-	if err := h.s.Delete(r.Context(), r.PathValue("id")); err != nil {
+	del := h.s.Delete
+	if purgeStr := r.URL.Query().Get("purge"); len(purgeStr) > 0 {
+		purge, err := strconv.ParseBool(purgeStr)
+		if err != nil {
+			logger.Log.Info("invalid purge")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		if purge {
+			del = h.s.Purge
+		}
+	}
+	version, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+	if err := del(r.Context(), r.PathValue("id"), version); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.NotFound:
+				missing(w, r)
+			case codes.FailedPrecondition:
+				w.WriteHeader(http.StatusPreconditionFailed)
+			case codes.Internal:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// RestoreSong godoc
+// @Summary Restore song
+// @Description Restore deleted song to library
+// @Tags Songs
+// @Param id path string true "Song id"
+// @Success 202 "Song restored"
+// @Failure 204 "Deleted song not found"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /song/{id}/restore [post]
+func (h *HTTP) RestoreSong(w http.ResponseWriter, r *http.Request) {
+	if err := h.s.Restore(r.Context(), r.PathValue("id")); err != nil {
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
 			case codes.NotFound:
@@ -142,10 +367,15 @@ func (h *HTTP) DeleteSong(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "Song id"
 // @Param page query int false "Page number" default(1)
 // @Param size query int false "Page size" default(3)
+// @Param If-None-Match header string false "Cached song ETag"
 // @Success 200 {object} models.ResponseGetSongText "Song text"
+// @Header 200 {string} ETag "Song version"
 // @Failure 204 "Song not found"
+// @Failure 304 "Song not modified"
 // @Failure 400 "Bad request"
 // @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /song/{id}/text [get]
 func (h *HTTP) GetSongText(w http.ResponseWriter, r *http.Request) {
 	var page, size int
@@ -185,6 +415,9 @@ func (h *HTTP) GetSongText(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if notModified(w, r, d.Version) {
+		return
+	}
 	w.Header().Set("Content-type", "application/json")
 	if err := json.NewEncoder(w).Encode(&d); err != nil {
 		logger.Log.Info("JSON encode error", zap.Error(err))
@@ -199,16 +432,37 @@ func (h *HTTP) GetSongText(w http.ResponseWriter, r *http.Request) {
 // @Tags Songs
 // @Produce json
 // @Param id query string false "Song id"
-// @Param group query string false "Group"
+// @Param group query string false "Group, exact match resolves artist aliases"
+// @Param artist_id query string false "Artist id"
+// @Param album_id query string false "Album id"
 // @Param song query string false "Song"
-// @Param release_date query string false "Release date" default(16.07.2006)
+// @Param release_date query string false "Release date, dd.mm.yyyy or yyyy-mm-dd" default(16.07.2006)
+// @Param released_after query string false "Released after date, dd.mm.yyyy or yyyy-mm-dd"
+// @Param released_before query string false "Released before date, dd.mm.yyyy or yyyy-mm-dd"
+// @Param year query int false "Release year"
+// @Param decade query int false "Release decade first year" example(1990)
 // @Param text query string false "Text"
 // @Param link query string false "Link"
+// @Param group_match query string false "Group match mode, ci is case insensitive contains" Enums(exact, prefix, contains, ci) default(exact)
+// @Param song_match query string false "Song match mode, ci is case insensitive contains" Enums(exact, prefix, contains, ci) default(exact)
+// @Param text_match query string false "Text match mode, ci is case insensitive contains" Enums(exact, prefix, contains, ci) default(contains)
+// @Param tags query string false "Comma separated tags"
+// @Param tags_match query string false "Tags match mode, songs having any or all of tags" Enums(any, all) default(any)
+// @Param deleted query string false "Deleted songs" Enums(exclude, include, only) default(exclude)
 // @Param page query int false "Page number" default(1)
 // @Param size query int false "Page size" default(10)
+// @Param count_only query bool false "Count songs without fetching them"
+// @Param sort query string false "Sort columns group, song, release_date, created_at, updated_at, minus prefix for descending order" default(created_at)
+// @Param cursor query string false "Next page cursor, replaces page"
+// @Param If-None-Match header string false "Cached song ETag, used with id"
 // @Success 200 {object} models.ResponseGetSongs "Songs list"
+// @Header 200 {string} ETag "Song version, set with id"
+// @Header 200 {string} Link "Next and previous pages links"
+// @Failure 304 "Song not modified"
 // @Failure 400 "Bad request"
 // @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /songs [get]
 func (h *HTTP) GetSongs(w http.ResponseWriter, r *http.Request) {
 	var page, size int
@@ -237,29 +491,131 @@ func (h *HTTP) GetSongs(w http.ResponseWriter, r *http.Request) {
 
 	releaseDateStr := r.URL.Query().Get("release_date")
 	var releaseDate time.Time
-	var ee error
 	if len(releaseDateStr) > 0 {
-		releaseDate, ee = time.Parse("02.01.2006", releaseDateStr)
-		if ee != nil {
-			logger.Log.Info("invalid release date")
+		releaseDate, err = parseDate("release_date", releaseDateStr)
+		if err != nil {
+			logger.Log.Info("invalid release date", zap.Error(err))
+			http.Error(w, "Bad request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	releasedFrom, releasedTo, err := releaseRange(r.URL.Query())
+	if err != nil {
+		logger.Log.Info("invalid release date range", zap.Error(err))
+		http.Error(w, "Bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deleted := r.URL.Query().Get("deleted")
+	switch deleted {
+	case "", models.DeletedExclude, models.DeletedInclude, models.DeletedOnly:
+	default:
+		logger.Log.Info("invalid deleted")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	var countOnly bool
+	if v := r.URL.Query().Get("count_only"); len(v) > 0 {
+		countOnly, err = strconv.ParseBool(v)
+		if err != nil {
+			logger.Log.Info("invalid count_only")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var match models.Match
+	for _, m := range []struct {
+		param string
+		mode  *string
+	}{
+		{"group_match", &match.Group},
+		{"song_match", &match.Song},
+		{"text_match", &match.Text},
+	} {
+		switch v := r.URL.Query().Get(m.param); v {
+		case "", models.MatchExact, models.MatchPrefix, models.MatchContains, models.MatchCI:
+			*m.mode = v
+		default:
+			logger.Log.Info("invalid match mode", zap.String(m.param, v))
+			http.Error(w, "Bad request: "+m.param+" must be exact, prefix, contains or ci",
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	var tags []string
+	if v := r.URL.Query().Get("tags"); len(v) > 0 {
+		tags = strings.Split(v, ",")
+		if slices.Contains(tags, "") {
+			logger.Log.Info("empty tag")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+	switch match.Tags = r.URL.Query().Get("tags_match"); match.Tags {
+	case "", models.MatchAny, models.MatchAll:
+	default:
+		logger.Log.Info("invalid tags match mode")
+		http.Error(w, "Bad request: tags_match must be any or all", http.StatusBadRequest)
+		return
+	}
+
+	sort, err := parseSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		logger.Log.Info("invalid sort", zap.Error(err))
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	var c *models.Cursor
+	if v := r.URL.Query().Get("cursor"); len(v) > 0 {
+		if len(pageStr) > 0 {
+			logger.Log.Info("page with cursor")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		d, err := cursor.Decode(v, sort)
+		if err != nil {
+			logger.Log.Info("invalid cursor", zap.Error(err))
 			http.Error(w, "Bad request", http.StatusBadRequest)
 			return
 		}
+		c = &d
 	}
 
-	d, err := h.s.GetSongs(r.Context(), models.Song{
-		ID:          r.URL.Query().Get("id"),
-		Group:       r.URL.Query().Get("group"),
-		Song:        r.URL.Query().Get("song"),
-		ReleaseDate: releaseDate,
-		Text:        r.URL.Query().Get("text"),
-		Link:        r.URL.Query().Get("link"),
-	}, page, size)
+	d, err := h.s.GetSongs(r.Context(), models.RequestGetSongs{
+		Filter: models.Song{
+			ID:          r.URL.Query().Get("id"),
+			Group:       r.URL.Query().Get("group"),
+			Song:        r.URL.Query().Get("song"),
+			ReleaseDate: releaseDate,
+			ArtistID:    r.URL.Query().Get("artist_id"),
+			AlbumID:     r.URL.Query().Get("album_id"),
+			Text:        r.URL.Query().Get("text"),
+			Link:        r.URL.Query().Get("link"),
+			Tags:        tags,
+		},
+		Match:        match,
+		Deleted:      deleted,
+		Page:         page,
+		Size:         size,
+		CountOnly:    countOnly,
+		Cursor:       c,
+		Sort:         sort,
+		ReleasedFrom: releasedFrom,
+		ReleasedTo:   releasedTo,
+	})
 	if err != nil {
 		logger.Log.Info("unable to get songs", zap.Error(err))
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	navigate(w, r, &d)
+	if len(r.URL.Query().Get("id")) > 0 && len(d.Songs) == 1 && notModified(w, r, d.Songs[0].Version) {
+		return
+	}
 
 	w.Header().Set("Content-type", "application/json")
 	if err := json.NewEncoder(w).Encode(&d); err != nil {
@@ -269,6 +625,66 @@ func (h *HTTP) GetSongs(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// sortable lists songs columns allowed in sort parameter.
+var sortable = map[string]bool{
+	models.SortGroup:       true,
+	models.SortSong:        true,
+	models.SortReleaseDate: true,
+	models.SortCreated:     true,
+	models.SortUpdated:     true,
+}
+
+// parseSort parses comma separated sort columns, minus prefix stands
+// for descending order.
+func parseSort(v string) ([]models.SortKey, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	var sort []models.SortKey
+	seen := make(map[string]bool)
+	for _, f := range strings.Split(v, ",") {
+		k := models.SortKey{Column: strings.TrimPrefix(f, "-"), Desc: strings.HasPrefix(f, "-")}
+		if !sortable[k.Column] || seen[k.Column] {
+			return nil, fmt.Errorf("unsupported sort column %q", f)
+		}
+		seen[k.Column] = true
+		sort = append(sort, k)
+	}
+	return sort, nil
+}
+
+// link returns request link with query parameter replaced.
+func link(r *http.Request, param, value string) string {
+	q := r.URL.Query()
+	q.Set(param, value)
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// navigate sets next and previous pages links of songs list response
+// and RFC 8288 Link header, cursor requests are continued by cursor.
+func navigate(w http.ResponseWriter, r *http.Request, d *models.ResponseGetSongs) {
+	var links []string
+	if r.URL.Query().Has("cursor") {
+		if len(d.NextCursor) > 0 {
+			d.Next = link(r, "cursor", d.NextCursor)
+			links = append(links, `<`+d.Next+`>; rel="next"`)
+		}
+	} else {
+		if d.Page < d.Pages {
+			d.Next = link(r, "page", strconv.Itoa(d.Page+1))
+			links = append(links, `<`+d.Next+`>; rel="next"`)
+		}
+		if d.Page > 1 {
+			// past the end previous page is the last one
+			d.Prev = link(r, "page", strconv.Itoa(min(d.Page-1, max(d.Pages, 1))))
+			links = append(links, `<`+d.Prev+`>; rel="prev"`)
+		}
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
 // GetPing checks service availability.
 func (h *HTTP) GetPing(w http.ResponseWriter, r *http.Request) {
 	if err := h.s.Ping(); err != nil {
@@ -13,8 +13,10 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/cursor"
 	"github.com/xEgorka/project4/internal/app/mocks"
 	"github.com/xEgorka/project4/internal/app/models"
 	"github.com/xEgorka/project4/internal/app/requests"
@@ -45,14 +47,15 @@ func TestHTTP_PostSong(t *testing.T) {
 		code        int
 	}
 	tests := []struct {
-		name string
-		body string
-		want want
+		name  string
+		query string
+		body  string
+		want  want
 	}{
 		{
 			name: "positive test #1",
 			body: `{"group": "Muse","song": "Supermassive Black Hole"}`,
-			want: want{code: http.StatusOK, contentType: "application/json"},
+			want: want{code: http.StatusCreated, contentType: "application/json"},
 		},
 		{
 			name: "negative test #1",
@@ -79,11 +82,28 @@ func TestHTTP_PostSong(t *testing.T) {
 			body: `{"group": "Muse","song": "Supermassive Black Hole"}`,
 			want: want{code: http.StatusGone},
 		},
+		{
+			name: "negative test #6",
+			body: `{"group": "Muse","song": "Supermassive Black Hole"}`,
+			want: want{code: http.StatusConflict, contentType: "application/json"},
+		},
+		{
+			name:  "negative test #7",
+			query: "?force=maybe",
+			body:  `{"group": "Muse","song": "Supermassive Black Hole"}`,
+			want:  want{code: http.StatusBadRequest, contentType: "text/plain; charset=utf-8"},
+		},
+		{
+			name:  "positive test #2",
+			query: "?force=true",
+			body:  `{"group": "Muse","song": "Supermassive Black Hole"}`,
+			want:  want{code: http.StatusCreated, contentType: "application/json"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := httptest.NewRequest(http.MethodPost, "/api/song", strings.NewReader(tt.body))
+			r := httptest.NewRequest(http.MethodPost, "/api/song"+tt.query, strings.NewReader(tt.body))
 			r.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 			ctx := context.Background()
@@ -96,8 +116,18 @@ func TestHTTP_PostSong(t *testing.T) {
 				Song:        "Supermassive Black Hole",
 				ReleaseDate: releaseDate,
 				Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
-				Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw"}
-			if tt.want.code == http.StatusOK {
+				Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+				ArtistID:    "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"}
+			ms.EXPECT().ResolveArtist(ctx, "Muse").
+				Return(models.Artist{ID: s.ArtistID, Name: "Muse"}, nil).AnyTimes()
+			if tt.want.code != http.StatusBadRequest && tt.query == "" {
+				var near []models.Suggestion
+				if tt.name == "negative test #6" {
+					near = []models.Suggestion{{ID: "1", Group: "Muse", Song: "Supermasive Black Hole"}}
+				}
+				ms.EXPECT().Suggest(ctx, "Muse Supermassive Black Hole", service.DefaultSizeSuggest).Return(near, nil)
+			}
+			if tt.want.code == http.StatusCreated {
 				ms.EXPECT().Add(ctx, s).Return(s, nil)
 			}
 			if tt.name == "negative test #3" {
@@ -120,6 +150,65 @@ func TestHTTP_PostSong(t *testing.T) {
 	}
 }
 
+func TestHTTP_GetSong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	type want struct {
+		contentType string
+		etag        string
+		code        int
+	}
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		err         error
+		want        want
+	}{
+		{
+			name: "positive test #1",
+			want: want{code: http.StatusOK, contentType: "application/json", etag: `"3"`},
+		},
+		{
+			name:        "positive test #2",
+			ifNoneMatch: `"3"`,
+			want:        want{code: http.StatusNotModified, etag: `"3"`},
+		},
+		{
+			name: "negative test #1",
+			err:  sql.ErrNoRows,
+			want: want{code: http.StatusNoContent},
+		},
+		{
+			name: "negative test #2",
+			err:  errors.New("test"),
+			want: want{code: http.StatusInternalServerError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/song/{id}", nil)
+			r.SetPathValue("id", id)
+			if len(tt.ifNoneMatch) > 0 {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			ms.EXPECT().Get(gomock.Any(), id).Return(models.Song{ID: id, Version: 3}, tt.err)
+			h.GetSong(w, r)
+			res := w.Result()
+			assert.Equal(t, tt.want.code, res.StatusCode)
+			assert.Equal(t, tt.want.contentType, res.Header.Get("Content-Type"))
+			assert.Equal(t, tt.want.etag, res.Header.Get("ETag"))
+			if err := res.Body.Close(); err != nil {
+				panic(err)
+			}
+		})
+	}
+}
+
 func TestHTTP_UpdateSong(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -185,13 +274,13 @@ func TestHTTP_UpdateSong(t *testing.T) {
 				Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight",
 				Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw"}
 			if tt.want.code == http.StatusAccepted {
-				ms.EXPECT().Update(ctx, tt.id, s).Return(nil)
+				ms.EXPECT().Update(ctx, tt.id, 0, s).Return(nil)
 			}
 			if tt.name == "negative test #3" {
-				ms.EXPECT().Update(ctx, tt.id, s).Return(storage.ErrNotAffected)
+				ms.EXPECT().Update(ctx, tt.id, 0, s).Return(storage.ErrNotAffected)
 			}
 			if tt.name == "negative test #4" {
-				ms.EXPECT().Update(ctx, tt.id, s).Return(errors.New("test"))
+				ms.EXPECT().Update(ctx, tt.id, 0, s).Return(errors.New("test"))
 			}
 			h.PutSong(w, r.WithContext(ctx))
 			res := w.Result()
@@ -204,6 +293,83 @@ func TestHTTP_UpdateSong(t *testing.T) {
 	}
 }
 
+func TestHTTP_PatchSong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	link := "https://youtu.be/3dm_5qWWDV8"
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		call        bool
+		err         error
+		code        int
+	}{
+		{name: "positive test #1", contentType: "application/merge-patch+json",
+			body: `{"link": "https://youtu.be/3dm_5qWWDV8"}`, call: true, code: http.StatusAccepted},
+		{name: "negative test #1", contentType: "application/json",
+			body: `{"link": "https://youtu.be/3dm_5qWWDV8"}`, call: true, err: storage.ErrUniqueViolation,
+			code: http.StatusConflict},
+		{name: "negative test #2", body: `{"link": "https://youtu.be/3dm_5qWWDV8"}`, call: true,
+			err: storage.ErrNotAffected, code: http.StatusNoContent},
+		{name: "negative test #3", contentType: "text/plain", body: `{}`, code: http.StatusUnsupportedMediaType},
+		{name: "negative test #4", body: `{"link": null}`, code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/api/song/{id}", strings.NewReader(tt.body))
+			r.SetPathValue("id", id)
+			if len(tt.contentType) > 0 {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			if tt.call {
+				ms.EXPECT().Patch(gomock.Any(), id, 0, models.RequestPatchSong{Link: &link}).Return(tt.err)
+			}
+			h.PatchSong(w, r)
+			res := w.Result()
+			assert.Equal(t, tt.code, res.StatusCode)
+			if err := res.Body.Close(); err != nil {
+				panic(err)
+			}
+		})
+	}
+}
+
+func Test_mergePatch(t *testing.T) {
+	group, date := "Muse", time.Date(2006, 6, 19, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		body    string
+		want    models.RequestPatchSong
+		wantErr bool
+	}{
+		{name: "positive test #1", body: `{}`},
+		{name: "positive test #2", body: `{"group": "Muse", "release_date": "2006-06-19T00:00:00Z"}`,
+			want: models.RequestPatchSong{Group: &group, ReleaseDate: &date}},
+		{name: "negative test #1", body: `[]`, wantErr: true},
+		{name: "negative test #2", body: `{"id": "0824f9fb-7397-4f19-95d5-f9ce8bec75de"}`, wantErr: true},
+		{name: "negative test #3", body: `{"text": null}`, wantErr: true},
+		{name: "negative test #4", body: `{"song": ""}`, wantErr: true},
+		{name: "negative test #5", body: `{"release_date": "19.06.2006"}`, wantErr: true},
+		{name: "negative test #6", body: `{"release_date": "0001-01-01T00:00:00Z"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergePatch(strings.NewReader(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mergePatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestHTTP_DeleteSong(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -236,24 +402,43 @@ func TestHTTP_DeleteSong(t *testing.T) {
 			id:   "0824f9fb-7397-4f19-95d5-f9ce8bec75de",
 			want: want{code: http.StatusInternalServerError},
 		},
+		{
+			name: "positive test #2",
+			id:   "0824f9fb-7397-4f19-95d5-f9ce8bec75de",
+			want: want{code: http.StatusAccepted},
+		},
+		{
+			name: "negative test #3",
+			id:   "0824f9fb-7397-4f19-95d5-f9ce8bec75de",
+			want: want{code: http.StatusBadRequest, contentType: "text/plain; charset=utf-8"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := httptest.NewRequest(http.MethodDelete, "/api/song/{id}", strings.NewReader(""))
+			if tt.name == "positive test #2" {
+				r = httptest.NewRequest(http.MethodDelete, "/api/song/{id}?purge=true", strings.NewReader(""))
+			}
+			if tt.name == "negative test #3" {
+				r = httptest.NewRequest(http.MethodDelete, "/api/song/{id}?purge=bad", strings.NewReader(""))
+			}
 			r.SetPathValue("id", tt.id)
 			r.Header.Set("Content-Type", "text/plain")
 			w := httptest.NewRecorder()
 			ctx := context.Background()
 
-			if tt.want.code == http.StatusAccepted {
-				ms.EXPECT().Delete(ctx, tt.id).Return(nil)
+			if tt.name == "positive test #1" {
+				ms.EXPECT().Delete(ctx, tt.id, 0).Return(nil)
+			}
+			if tt.name == "positive test #2" {
+				ms.EXPECT().Purge(ctx, tt.id, 0).Return(nil)
 			}
 			if tt.name == "negative test #1" {
-				ms.EXPECT().Delete(ctx, tt.id).Return(storage.ErrNotAffected)
+				ms.EXPECT().Delete(ctx, tt.id, 0).Return(storage.ErrNotAffected)
 			}
 			if tt.name == "negative test #2" {
-				ms.EXPECT().Delete(ctx, tt.id).Return(errors.New("test"))
+				ms.EXPECT().Delete(ctx, tt.id, 0).Return(errors.New("test"))
 			}
 			h.DeleteSong(w, r.WithContext(ctx))
 			res := w.Result()
@@ -266,6 +451,60 @@ func TestHTTP_DeleteSong(t *testing.T) {
 	}
 }
 
+func TestHTTP_RestoreSong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := service.New(cfg, ms, requests.New(cfg))
+	h := NewHTTP(s)
+	type want struct {
+		contentType string
+		code        int
+	}
+	tests := []struct {
+		name string
+		id   string
+		err  error
+		want want
+	}{
+		{
+			name: "positive test #1",
+			id:   "0824f9fb-7397-4f19-95d5-f9ce8bec75de",
+			want: want{code: http.StatusAccepted},
+		},
+		{
+			name: "negative test #1",
+			id:   "0824f9fb-7397-4f19-95d5-f9ce8bec75de",
+			err:  storage.ErrNotAffected,
+			want: want{code: http.StatusNoContent},
+		},
+		{
+			name: "negative test #2",
+			id:   "0824f9fb-7397-4f19-95d5-f9ce8bec75de",
+			err:  errors.New("test"),
+			want: want{code: http.StatusInternalServerError},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/song/{id}/restore", strings.NewReader(""))
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			ctx := context.Background()
+			ms.EXPECT().Restore(ctx, tt.id).Return(tt.err)
+			h.RestoreSong(w, r.WithContext(ctx))
+			res := w.Result()
+			assert.Equal(t, tt.want.code, res.StatusCode)
+			assert.Equal(t, res.Header.Get("Content-Type"), tt.want.contentType)
+			if err := res.Body.Close(); err != nil {
+				panic(err)
+			}
+		})
+	}
+}
+
 func TestHTTP_GetSongText(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -375,6 +614,7 @@ func TestHTTP_GetSongs(t *testing.T) {
 		{name: "negative test #2", want: want{code: http.StatusBadRequest, contentType: "text/plain; charset=utf-8"}},
 		{name: "negative test #3", want: want{code: http.StatusBadRequest, contentType: "text/plain; charset=utf-8"}},
 		{name: "negative test #4", want: want{code: http.StatusInternalServerError, contentType: "text/plain; charset=utf-8"}},
+		{name: "negative test #5", want: want{code: http.StatusBadRequest, contentType: "text/plain; charset=utf-8"}},
 	}
 
 	for _, tt := range tests {
@@ -389,21 +629,23 @@ func TestHTTP_GetSongs(t *testing.T) {
 			if tt.name == "negative test #3" {
 				r = httptest.NewRequest(http.MethodGet, "/api/songs?release_date=bad", strings.NewReader(""))
 			}
+			if tt.name == "negative test #5" {
+				r = httptest.NewRequest(http.MethodGet, "/api/songs?deleted=bad", strings.NewReader(""))
+			}
 			r.Header.Set("Content-Type", "text/plain")
 			w := httptest.NewRecorder()
 			ctx := context.Background()
-			var releaseDate time.Time
-			req := models.Song{ReleaseDate: releaseDate}
+			req := models.RequestGetSongs{Page: service.DefaultPage, Size: service.DefaultSizeSongs}
 			s := models.ResponseGetSongs{
 				Page:  service.DefaultPage,
 				Size:  service.DefaultSizeSongs,
 				Songs: []models.Song{},
 			}
 			if tt.want.code == http.StatusOK {
-				ms.EXPECT().GetSongs(ctx, req, service.DefaultPage, service.DefaultSizeSongs).Return(s, nil)
+				ms.EXPECT().GetSongs(ctx, req).Return(s, nil)
 			}
 			if tt.name == "negative test #4" {
-				ms.EXPECT().GetSongs(ctx, req, service.DefaultPage, service.DefaultSizeSongs).Return(s, errors.New("test"))
+				ms.EXPECT().GetSongs(ctx, req).Return(s, errors.New("test"))
 			}
 			h.GetSongs(w, r.WithContext(ctx))
 			res := w.Result()
@@ -416,6 +658,181 @@ func TestHTTP_GetSongs(t *testing.T) {
 	}
 }
 
+func TestHTTP_GetSongs_navigation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	ms.EXPECT().ResolveArtist(gomock.Any(), gomock.Any()).Return(models.Artist{}, sql.ErrNoRows).AnyTimes()
+	c := cursor.After(nil, models.Song{ID: "0824f9fb-7397-4f19-95d5-f9ce8bec75de"})
+	next := cursor.Encode(c)
+	tests := []struct {
+		name      string
+		query     string
+		req       models.RequestGetSongs
+		total     int
+		code      int
+		next      string
+		prev      string
+		link      string
+		countOnly bool
+	}{
+		{
+			name:  "positive test #1",
+			query: "?group=Muse&size=2",
+			req:   models.RequestGetSongs{Filter: models.Song{Group: "Muse"}, Page: 1, Size: 2},
+			total: 5,
+			code:  http.StatusOK,
+			next:  "/api/songs?group=Muse&page=2&size=2",
+			link:  `</api/songs?group=Muse&page=2&size=2>; rel="next"`,
+		},
+		{
+			name:  "positive test #2",
+			query: "?page=2&size=2",
+			req:   models.RequestGetSongs{Page: 2, Size: 2},
+			total: 5,
+			code:  http.StatusOK,
+			next:  "/api/songs?page=3&size=2",
+			prev:  "/api/songs?page=1&size=2",
+			link:  `</api/songs?page=3&size=2>; rel="next", </api/songs?page=1&size=2>; rel="prev"`,
+		},
+		{
+			name:  "positive test #3",
+			query: "?page=9&size=2",
+			req:   models.RequestGetSongs{Page: 9, Size: 2},
+			total: 5,
+			code:  http.StatusOK,
+			prev:  "/api/songs?page=3&size=2",
+			link:  `</api/songs?page=3&size=2>; rel="prev"`,
+		},
+		{
+			name:      "positive test #4",
+			query:     "?count_only=true",
+			req:       models.RequestGetSongs{Page: 1, Size: 10, CountOnly: true},
+			total:     3,
+			code:      http.StatusOK,
+			countOnly: true,
+		},
+		{
+			name:  "positive test #5",
+			query: "?cursor=" + next + "&size=2",
+			req:   models.RequestGetSongs{Page: 1, Size: 2, Cursor: &c},
+			total: 5,
+			code:  http.StatusOK,
+			next:  "/api/songs?cursor=" + next + "&size=2",
+			link:  `</api/songs?cursor=` + next + `&size=2>; rel="next"`,
+		},
+		{name: "negative test #1", query: "?count_only=bad", code: http.StatusBadRequest},
+		{name: "negative test #2", query: "?cursor=bad", code: http.StatusBadRequest},
+		{name: "negative test #3", query: "?cursor=" + next + "&page=2", code: http.StatusBadRequest},
+		{name: "negative test #4", query: "?sort=text", code: http.StatusBadRequest},
+		{name: "negative test #6", query: "?released_after=yesterday", code: http.StatusBadRequest},
+		{name: "negative test #7", query: "?group=mu&group_match=ilike", code: http.StatusBadRequest},
+		{
+			name:  "positive test #8",
+			query: "?group=mu&group_match=prefix&text=ooh&text_match=ci",
+			req: models.RequestGetSongs{Page: 1, Size: 10,
+				Filter: models.Song{Group: "mu", Text: "ooh"},
+				Match:  models.Match{Group: models.MatchPrefix, Text: models.MatchCI}},
+			total: 2,
+			code:  http.StatusOK,
+		},
+		{
+			name:  "positive test #7",
+			query: "?year=2006&release_date=2006-07-16",
+			req: models.RequestGetSongs{Page: 1, Size: 10,
+				Filter:       models.Song{ReleaseDate: time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)},
+				ReleasedFrom: time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC),
+				ReleasedTo:   time.Date(2007, 1, 1, 0, 0, 0, 0, time.UTC)},
+			total: 1,
+			code:  http.StatusOK,
+		},
+		{name: "negative test #5", query: "?sort=group&cursor=" + next, code: http.StatusBadRequest},
+		{
+			name:  "positive test #6",
+			query: "?sort=-release_date,group",
+			req: models.RequestGetSongs{Page: 1, Size: 10, Sort: []models.SortKey{
+				{Column: models.SortReleaseDate, Desc: true}, {Column: models.SortGroup}}},
+			total: 3,
+			code:  http.StatusOK,
+		},
+		{
+			name:  "positive test #9",
+			query: "?tags=rock,english&tags_match=all",
+			req: models.RequestGetSongs{Page: 1, Size: 10,
+				Filter: models.Song{Tags: []string{"rock", "english"}},
+				Match:  models.Match{Tags: models.MatchAll}},
+			total: 1,
+			code:  http.StatusOK,
+		},
+		{name: "negative test #8", query: "?tags=rock,,english", code: http.StatusBadRequest},
+		{name: "negative test #9", query: "?tags=rock&tags_match=none", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/songs"+tt.query, nil)
+			w := httptest.NewRecorder()
+			if tt.code == http.StatusOK {
+				d := models.ResponseGetSongs{Page: tt.req.Page, Size: tt.req.Size, Total: tt.total,
+					Pages: (tt.total + tt.req.Size - 1) / tt.req.Size}
+				if tt.req.Cursor != nil {
+					d.NextCursor = next
+				}
+				ms.EXPECT().GetSongs(gomock.Any(), tt.req).Return(d, nil)
+			}
+			h.GetSongs(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+			assert.Equal(t, tt.link, res.Header.Get("Link"))
+			if tt.code != http.StatusOK {
+				return
+			}
+			var got models.ResponseGetSongs
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+			assert.Equal(t, tt.total, got.Total)
+			assert.Equal(t, tt.next, got.Next)
+			assert.Equal(t, tt.prev, got.Prev)
+			if tt.countOnly {
+				assert.Empty(t, got.Songs)
+			}
+		})
+	}
+}
+
+func Test_parseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       string
+		want    []models.SortKey
+		wantErr bool
+	}{
+		{name: "positive test #1"},
+		{
+			name: "positive test #2",
+			v:    "-release_date,group,song",
+			want: []models.SortKey{{Column: models.SortReleaseDate, Desc: true},
+				{Column: models.SortGroup}, {Column: models.SortSong}},
+		},
+		{name: "positive test #3", v: "-updated_at", want: []models.SortKey{{Column: models.SortUpdated, Desc: true}}},
+		{name: "negative test #1", v: "text", wantErr: true},
+		{name: "negative test #2", v: "group,-group", wantErr: true},
+		{name: "negative test #3", v: "group,", wantErr: true},
+		{name: "negative test #4", v: "id; drop table songs", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSort(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSort() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestHandlers_GetPing(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
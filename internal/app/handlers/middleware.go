@@ -4,13 +4,23 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/metrics"
+	"github.com/xEgorka/project4/internal/app/tracing"
 )
 
 type (
 	responseData struct {
 		status int
 		size   int
+		caller string // set by WithAuth
 	}
 	loggingResponseWriter struct {
 		http.ResponseWriter
@@ -34,7 +44,22 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 
 var sugar = logger.Log.Sugar()
 
-// WithLogging embeds response data to the original http.ResponseWriter.
+// unmatchedRoute labels metrics of requests not matching any route.
+const unmatchedRoute = "unmatched"
+
+// route returns chi route pattern of served request, raw URI would make a
+// metric series per song.
+func route(r *http.Request) string {
+	if rc := chi.RouteContext(r.Context()); rc != nil {
+		if p := rc.RoutePattern(); len(p) > 0 {
+			return p
+		}
+	}
+	return unmatchedRoute
+}
+
+// WithLogging embeds response data to the original http.ResponseWriter,
+// logs served request and records its metrics.
 func WithLogging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		sugar = logger.Log.Sugar()
@@ -51,12 +76,42 @@ func WithLogging(next http.Handler) http.Handler {
 		method := r.Method
 		next.ServeHTTP(&lw, r)
 		duration := time.Since(start)
+		status := responseData.status
+		if status == 0 {
+			status = http.StatusOK // nothing or only body written
+		}
+		metrics.ObserveHTTP(method, route(r), status, duration)
 		sugar.Infoln(
 			"uri", uri,
 			"method", method,
 			"duration", duration,
 			"status", responseData.status,
 			"size", responseData.size,
+			"caller", responseData.caller,
 		)
 	})
 }
+
+// WithTracing runs request in server span continuing trace of traceparent
+// header, span is named by route pattern known after routing. It goes after
+// WithLogging to read response status.
+func WithTracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		status := http.StatusOK
+		if lw, ok := w.(*loggingResponseWriter); ok && lw.responseData.status != 0 {
+			status = lw.responseData.status
+		}
+		rt := route(r)
+		span.SetName(r.Method + " " + rt)
+		span.SetAttributes(semconv.HTTPRequestMethodKey.String(r.Method), semconv.HTTPRoute(rt),
+			semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
@@ -6,6 +6,17 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type CustomResponseWriter struct {
@@ -118,3 +129,66 @@ func TestWithLogging(t *testing.T) {
 		})
 	}
 }
+
+func Test_route(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "positive test #1", path: "/api/song/1", want: "/api/song/{id}"},
+		{name: "negative test #1", path: "/api/unknown", want: unmatchedRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			r := chi.NewRouter()
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					next.ServeHTTP(w, req)
+					got = route(req)
+				})
+			})
+			r.Get("/api/song/{id}", func(w http.ResponseWriter, r *http.Request) {})
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWithTracing(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		want   string
+	}{
+		{name: "positive test #1", path: "/api/song/1", status: http.StatusOK, want: "GET /api/song/{id}"},
+		{name: "positive test #2", path: "/api/song/2", status: http.StatusInternalServerError,
+			want: "GET /api/song/{id}"},
+		{name: "negative test #1", path: "/api/unknown", status: http.StatusNotFound, want: "GET " + unmatchedRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.Use(WithLogging)
+			r.Use(WithTracing)
+			r.Get("/api/song/{id}", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(tt.status) })
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := sr.Ended()
+			require.NotEmpty(t, spans)
+			span := spans[len(spans)-1]
+			assert.Equal(t, tt.want, span.Name())
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+			assert.Contains(t, span.Attributes(), semconv.HTTPResponseStatusCode(tt.status))
+			assert.Equal(t, tt.status >= http.StatusInternalServerError, span.Status().Code == codes.Error)
+		})
+	}
+}
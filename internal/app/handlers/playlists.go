@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/service"
+)
+
+// playlistRequest parses playlist create or rename request, ok is false if
+// bad request is sent.
+func playlistRequest(w http.ResponseWriter, r *http.Request) (models.RequestPlaylist, bool) {
+	var req models.RequestPlaylist
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log.Info("JSON decode error", zap.Error(err))
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return req, false
+	}
+	if len(req.Name) == 0 {
+		logger.Log.Info("empty name")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+// playlistPage parses playlists or playlist songs page and size, ok is
+// false if bad request is sent.
+func playlistPage(w http.ResponseWriter, r *http.Request) (page, size int, ok bool) {
+	page, size = service.DefaultPage, service.DefaultSizePlaylists
+	var err error
+	if v := r.URL.Query().Get("page"); len(v) > 0 {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			logger.Log.Info("invalid page")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+	if v := r.URL.Query().Get("size"); len(v) > 0 {
+		if size, err = strconv.Atoi(v); err != nil || size < 1 {
+			logger.Log.Info("invalid size")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return 0, 0, false
+		}
+	}
+	return page, size, true
+}
+
+// PostPlaylist godoc
+// @Summary Add playlist
+// @Description Add empty playlist
+// @Tags Playlists
+// @Accept json
+// @Produce json
+// @Param playlist body models.RequestPlaylist true "Add playlist"
+// @Success 201 {object} models.Playlist "Playlist added"
+// @Header 201 {string} Location "Playlist URI"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists [post]
+func (h *HTTP) PostPlaylist(w http.ResponseWriter, r *http.Request) {
+	req, ok := playlistRequest(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.s.AddPlaylist(r.Context(), req)
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	w.Header().Set("Location", "/api/playlists/"+d.ID)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&d); err != nil {
+		logger.Log.Info("JSON encode error", zap.Error(err))
+		return
+	}
+}
+
+// GetPlaylists godoc
+// @Summary Get playlists
+// @Description Get playlists ordered by name for certain page and page size
+// @Tags Playlists
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param size query int false "Page size" default(10)
+// @Success 200 {object} models.ResponseGetPlaylists "Playlists list"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists [get]
+func (h *HTTP) GetPlaylists(w http.ResponseWriter, r *http.Request) {
+	page, size, ok := playlistPage(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.s.GetPlaylists(r.Context(), page, size)
+	writeJSON(w, &d, err)
+}
+
+// GetPlaylist godoc
+// @Summary Get playlist
+// @Description Get playlist with songs in playlist order for certain page and page size, deleted songs are not listed
+// @Tags Playlists
+// @Produce json
+// @Param id path string true "Playlist id"
+// @Param page query int false "Page number" default(1)
+// @Param size query int false "Page size" default(10)
+// @Success 200 {object} models.ResponseGetPlaylist "Playlist songs"
+// @Failure 400 "Bad request"
+// @Failure 204 "Playlist not found"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id} [get]
+func (h *HTTP) GetPlaylist(w http.ResponseWriter, r *http.Request) {
+	page, size, ok := playlistPage(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.s.GetPlaylistSongs(r.Context(), r.PathValue("id"), page, size)
+	if err != nil {
+		writeAlbumError(w, err)
+		return
+	}
+	writeJSON(w, &d, nil)
+}
+
+// PutPlaylist godoc
+// @Summary Rename playlist
+// @Description Change playlist name
+// @Tags Playlists
+// @Accept json
+// @Param id path string true "Playlist id"
+// @Param playlist body models.RequestPlaylist true "Rename playlist"
+// @Success 202 "Playlist renamed"
+// @Failure 204 "Playlist not found"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id} [put]
+func (h *HTTP) PutPlaylist(w http.ResponseWriter, r *http.Request) {
+	req, ok := playlistRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.RenamePlaylist(r.Context(), r.PathValue("id"), req); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeletePlaylist godoc
+// @Summary Delete playlist
+// @Description Delete playlist, its songs are kept
+// @Tags Playlists
+// @Param id path string true "Playlist id"
+// @Success 202 "Playlist deleted"
+// @Failure 204 "Playlist not found"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id} [delete]
+func (h *HTTP) DeletePlaylist(w http.ResponseWriter, r *http.Request) {
+	if err := h.s.DeletePlaylist(r.Context(), r.PathValue("id")); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// PostPlaylistSong godoc
+// @Summary Add playlist song
+// @Description Put song on one based playlist position shifting following songs, missing position appends song
+// @Tags Playlists
+// @Accept json
+// @Param id path string true "Playlist id"
+// @Param song body models.RequestPlaylistSong true "Add playlist song"
+// @Success 202 "Song added"
+// @Failure 204 "Playlist or song not found"
+// @Failure 400 "Bad request"
+// @Failure 409 "Song already in playlist"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id}/songs [post]
+func (h *HTTP) PostPlaylistSong(w http.ResponseWriter, r *http.Request) {
+	var req models.RequestPlaylistSong
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log.Info("JSON decode error", zap.Error(err))
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if len(req.SongID) == 0 || req.Position < 0 {
+		logger.Log.Info("empty song id or invalid position")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if err := h.s.AddPlaylistSong(r.Context(), r.PathValue("id"), req); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// PutPlaylistSong godoc
+// @Summary Move playlist song
+// @Description Move song to one based playlist position, position past last song moves song to the end
+// @Tags Playlists
+// @Accept json
+// @Param id path string true "Playlist id"
+// @Param song_id path string true "Song id"
+// @Param position body models.RequestMovePlaylistSong true "Move playlist song"
+// @Success 202 "Song moved"
+// @Failure 204 "Playlist or song not found"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id}/songs/{song_id} [put]
+func (h *HTTP) PutPlaylistSong(w http.ResponseWriter, r *http.Request) {
+	var req models.RequestMovePlaylistSong
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log.Info("JSON decode error", zap.Error(err))
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if req.Position < 1 {
+		logger.Log.Info("invalid position")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if err := h.s.MovePlaylistSong(r.Context(), r.PathValue("id"), r.PathValue("song_id"), req); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeletePlaylistSong godoc
+// @Summary Remove playlist song
+// @Description Remove song from playlist shifting following songs, song is kept
+// @Tags Playlists
+// @Param id path string true "Playlist id"
+// @Param song_id path string true "Song id"
+// @Success 202 "Song removed"
+// @Failure 204 "Playlist or song not found"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /playlists/{id}/songs/{song_id} [delete]
+func (h *HTTP) DeletePlaylistSong(w http.ResponseWriter, r *http.Request) {
+	if err := h.s.RemovePlaylistSong(r.Context(), r.PathValue("id"), r.PathValue("song_id")); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
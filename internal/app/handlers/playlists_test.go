@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/service"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestHTTP_PostPlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		code     int
+		location string
+	}{
+		{name: "positive test #1", body: `{"name": "Road trip"}`, code: http.StatusCreated, location: "/api/playlists/1"},
+		{name: "negative test #1", body: `{"name": ""}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `bad json`, code: http.StatusBadRequest},
+		{name: "negative test #3", body: `{"name": "Road trip"}`, err: errors.New("test"),
+			code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().AddPlaylist(gomock.Any(), models.Playlist{Name: "Road trip"}).
+					Return(models.Playlist{ID: "1", Name: "Road trip"}, tt.err)
+			}
+			w := httptest.NewRecorder()
+			h.PostPlaylist(w, httptest.NewRequest(http.MethodPost, "/api/playlists", strings.NewReader(tt.body)))
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+			assert.Equal(t, tt.location, res.Header.Get("Location"))
+		})
+	}
+}
+
+func TestHTTP_GetPlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name  string
+		query string
+		err   error
+		code  int
+	}{
+		{name: "positive test #1", query: "?page=2&size=1", code: http.StatusOK},
+		{name: "negative test #1", query: "?page=2&size=1", err: sql.ErrNoRows, code: http.StatusNoContent},
+		{name: "negative test #2", query: "?page=0", code: http.StatusBadRequest},
+		{name: "negative test #3", query: "?size=bad", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().GetPlaylist(gomock.Any(), "1").Return(models.Playlist{ID: "1"}, tt.err)
+			}
+			if tt.code == http.StatusOK {
+				ms.EXPECT().GetPlaylistSongs(gomock.Any(), "1", 2, 1).Return(models.ResponseGetPlaylist{}, nil)
+			}
+			r := httptest.NewRequest(http.MethodGet, "/api/playlists/1"+tt.query, nil)
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.GetPlaylist(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_PutPlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", body: `{"name": "Workout"}`, code: http.StatusAccepted},
+		{name: "negative test #1", body: `{}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `{"name": "Workout"}`, err: storage.ErrNotAffected,
+			code: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().RenamePlaylist(gomock.Any(), "1", "Workout").Return(tt.err)
+			}
+			r := httptest.NewRequest(http.MethodPut, "/api/playlists/1", strings.NewReader(tt.body))
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.PutPlaylist(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_PostPlaylistSong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", body: `{"song_id": "2", "position": 3}`, code: http.StatusAccepted},
+		{name: "negative test #1", body: `{"song_id": "2", "position": -1}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `{"position": 3}`, code: http.StatusBadRequest},
+		{name: "negative test #3", body: `bad json`, code: http.StatusBadRequest},
+		{name: "negative test #4", body: `{"song_id": "2", "position": 3}`, err: storage.ErrNotAffected,
+			code: http.StatusNoContent},
+		{name: "negative test #5", body: `{"song_id": "2", "position": 3}`, err: storage.ErrUniqueViolation,
+			code: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().AddPlaylistSong(gomock.Any(), "1", "2", 3).Return(tt.err)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/playlists/1/songs", strings.NewReader(tt.body))
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.PostPlaylistSong(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_PutPlaylistSong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", body: `{"position": 1}`, code: http.StatusAccepted},
+		{name: "negative test #1", body: `{"position": 0}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `{"position": 1}`, err: storage.ErrNotAffected, code: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().MovePlaylistSong(gomock.Any(), "1", "2", 1).Return(tt.err)
+			}
+			r := httptest.NewRequest(http.MethodPut, "/api/playlists/1/songs/2", strings.NewReader(tt.body))
+			r.SetPathValue("id", "1")
+			r.SetPathValue("song_id", "2")
+			w := httptest.NewRecorder()
+			h.PutPlaylistSong(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_DeletePlaylistSong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", code: http.StatusAccepted},
+		{name: "negative test #1", err: storage.ErrNotAffected, code: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().RemovePlaylistSong(gomock.Any(), "1", "2").Return(tt.err)
+			r := httptest.NewRequest(http.MethodDelete, "/api/playlists/1/songs/2", nil)
+			r.SetPathValue("id", "1")
+			r.SetPathValue("song_id", "2")
+			w := httptest.NewRecorder()
+			h.DeletePlaylistSong(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/ratelimit"
+)
+
+// Rate limit route classes.
+const (
+	// ClassRead groups reading requests.
+	ClassRead = "read"
+	// ClassWrite groups changing requests.
+	ClassWrite = "write"
+	// ClassLookup groups song add requests calling music info API.
+	ClassLookup = "lookup"
+)
+
+// routeClass returns rate limit class of request.
+func routeClass(r *http.Request) string {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/song":
+		return ClassLookup
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return ClassRead
+	}
+	return ClassWrite
+}
+
+// classLimit is rate limit of route class.
+type classLimit struct {
+	*ratelimit.Limiter
+	policy string
+}
+
+// RateLimit limits requests per route class of client addresses before
+// authentication and of authenticated callers after it.
+type RateLimit struct {
+	clients map[string]classLimit // classes without limit are missing
+	callers map[string]classLimit
+	proxies []netip.Prefix
+}
+
+// NewRateLimit creates RateLimit of configured route class limits.
+func NewRateLimit(cfg *config.Config) *RateLimit {
+	return &RateLimit{clients: classLimits(cfg), callers: classLimits(cfg), proxies: cfg.TrustedProxies}
+}
+
+// classLimits returns limiters of configured route class limits.
+func classLimits(cfg *config.Config) map[string]classLimit {
+	limits := make(map[string]classLimit)
+	for class, v := range map[string]config.RateLimit{
+		ClassRead:   cfg.RateLimitRead,
+		ClassWrite:  cfg.RateLimitWrite,
+		ClassLookup: cfg.RateLimitLookup,
+	} {
+		if v.Count > 0 {
+			limits[class] = classLimit{
+				Limiter: ratelimit.New(v.Count, v.Period),
+				policy:  strconv.Itoa(v.Count) + ";w=" + strconv.Itoa(seconds(v.Period)),
+			}
+		}
+	}
+	return limits
+}
+
+// seconds returns duration rounded up to whole seconds.
+func seconds(d time.Duration) int { return int(math.Ceil(d.Seconds())) }
+
+// WithRateLimit limits requests of client address before authentication,
+// unchecked credentials are ignored so they can not split address bucket.
+func (l *RateLimit) WithRateLimit(next http.Handler) http.Handler {
+	return throttle(l.clients, func(r *http.Request) (string, bool) {
+		return ratelimit.ClientIP(r, l.proxies), true
+	}, next)
+}
+
+// WithCallerRateLimit limits requests of caller authenticated by WithAuth
+// by API key or bearer token.
+func (l *RateLimit) WithCallerRateLimit(next http.Handler) http.Handler {
+	return throttle(l.callers, func(r *http.Request) (string, bool) {
+		c, ok := Caller(r.Context())
+		return c.ID, ok
+	}, next)
+}
+
+// throttle takes token of client bucket of request route class and reports
+// limit in RateLimit headers, client out of tokens gets 429.
+func throttle(limits map[string]classLimit, client func(r *http.Request) (string, bool),
+	next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, ok := limits[routeClass(r)]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, ok := client(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		res := limit.Allow(id)
+		report(w, limit, res)
+		if !res.Allowed {
+			logger.Log.Info("rate limit exceeded")
+			w.Header().Set("Retry-After", strconv.Itoa(seconds(res.RetryAfter)))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// report sets RateLimit headers of result unless fewer remaining requests
+// are reported already.
+func report(w http.ResponseWriter, limit classLimit, res ratelimit.Result) {
+	if v, err := strconv.Atoi(w.Header().Get("RateLimit-Remaining")); err == nil && v < res.Remaining {
+		return
+	}
+	w.Header().Set("RateLimit-Policy", limit.policy)
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+}
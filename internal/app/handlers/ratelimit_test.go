@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/ratelimit"
+)
+
+func Test_routeClass(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{name: "positive test #1", method: http.MethodPost, path: "/api/song", want: ClassLookup},
+		{name: "positive test #2", method: http.MethodGet, path: "/api/songs", want: ClassRead},
+		{name: "positive test #3", method: http.MethodHead, path: "/api/song/1", want: ClassRead},
+		{name: "positive test #4", method: http.MethodPost, path: "/api/song/1/restore", want: ClassWrite},
+		{name: "positive test #5", method: http.MethodDelete, path: "/api/song/1", want: ClassWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, routeClass(httptest.NewRequest(tt.method, tt.path, nil)))
+		})
+	}
+}
+
+func TestRateLimit_WithRateLimit(t *testing.T) {
+	l := NewRateLimit(&config.Config{
+		RateLimitRead:   config.RateLimit{Count: 2, Period: time.Minute},
+		RateLimitLookup: config.RateLimit{Count: 1, Period: time.Minute},
+		TrustedProxies:  []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := l.WithRateLimit(next)
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		header    http.Header
+		code      int
+		remaining string
+		policy    string
+	}{
+		{name: "positive test #1", method: http.MethodGet, path: "/api/songs", code: http.StatusOK,
+			remaining: "1", policy: "2;w=60"},
+		{name: "positive test #2", method: http.MethodGet, path: "/api/songs", code: http.StatusOK,
+			remaining: "0", policy: "2;w=60"},
+		{name: "negative test #1", method: http.MethodGet, path: "/api/songs", code: http.StatusTooManyRequests,
+			remaining: "0", policy: "2;w=60"},
+		{name: "negative test #2", method: http.MethodGet, path: "/api/songs", code: http.StatusTooManyRequests,
+			remaining: "0", policy: "2;w=60", header: http.Header{APIKeyHeader: {"fake"}}},
+		{name: "positive test #3", method: http.MethodGet, path: "/api/songs", code: http.StatusOK,
+			remaining: "1", policy: "2;w=60", header: http.Header{"X-Forwarded-For": {"198.51.100.8"}}},
+		{name: "positive test #4", method: http.MethodGet, path: "/api/songs", code: http.StatusOK,
+			remaining: "1", policy: "2;w=60", header: http.Header{"X-Forwarded-For": {"198.51.100.7, 10.0.0.2"}}},
+		{name: "positive test #5", method: http.MethodPost, path: "/api/song", code: http.StatusOK,
+			remaining: "0", policy: "1;w=60"},
+		{name: "negative test #3", method: http.MethodPost, path: "/api/song", code: http.StatusTooManyRequests,
+			remaining: "0", policy: "1;w=60"},
+		{name: "positive test #6", method: http.MethodDelete, path: "/api/song/1", code: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.RemoteAddr = "10.0.0.1:1234"
+			for k, v := range tt.header {
+				r.Header.Set(k, v[0])
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			res := rec.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+			assert.Equal(t, tt.remaining, res.Header.Get("RateLimit-Remaining"))
+			if tt.code == http.StatusTooManyRequests {
+				assert.NotEmpty(t, res.Header.Get("Retry-After"))
+			}
+			assert.Equal(t, tt.policy, res.Header.Get("RateLimit-Policy"))
+		})
+	}
+}
+
+func TestRateLimit_WithRateLimit_fakeKeys(t *testing.T) {
+	l := NewRateLimit(&config.Config{RateLimitRead: config.RateLimit{Count: 3, Period: time.Minute}})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := l.WithRateLimit(next)
+	codes := make([]int, 0, 5)
+	for i := range 5 {
+		r := httptest.NewRequest(http.MethodGet, "/api/songs", nil)
+		r.RemoteAddr = "198.51.100.7:1234"
+		r.Header.Set(APIKeyHeader, "fake-"+strconv.Itoa(i))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		codes = append(codes, rec.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusOK,
+		http.StatusTooManyRequests, http.StatusTooManyRequests}, codes)
+}
+
+func TestRateLimit_WithCallerRateLimit(t *testing.T) {
+	l := NewRateLimit(&config.Config{RateLimitRead: config.RateLimit{Count: 1, Period: time.Minute}})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	h := l.WithCallerRateLimit(next)
+	tests := []struct {
+		name   string
+		caller *models.Caller
+		addr   string
+		code   int
+	}{
+		{name: "positive test #1", caller: &models.Caller{ID: "alice"}, addr: "198.51.100.7:1234", code: http.StatusOK},
+		{name: "positive test #2", caller: &models.Caller{ID: "bob"}, addr: "198.51.100.7:1234", code: http.StatusOK},
+		{name: "positive test #3", addr: "198.51.100.7:1234", code: http.StatusOK},
+		{name: "negative test #1", caller: &models.Caller{ID: "alice"}, addr: "203.0.113.9:1234",
+			code: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/songs", nil)
+			r.RemoteAddr = tt.addr
+			if tt.caller != nil {
+				r = r.WithContext(WithCaller(r.Context(), *tt.caller))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+}
+
+func Test_report(t *testing.T) {
+	l := classLimits(&config.Config{RateLimitRead: config.RateLimit{Count: 5, Period: time.Minute}})[ClassRead]
+	rec := httptest.NewRecorder()
+	report(rec, l, ratelimit.Result{Limit: 5, Remaining: 1})
+	report(rec, l, ratelimit.Result{Limit: 5, Remaining: 4})
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	report(rec, l, ratelimit.Result{Limit: 5, Remaining: 0})
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+}
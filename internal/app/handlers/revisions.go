@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+)
+
+// revision parses revision number, ok is false if bad request is sent.
+func revision(w http.ResponseWriter, s string) (int, bool) {
+	rev, err := strconv.Atoi(s)
+	if err != nil || rev < 1 {
+		logger.Log.Info("invalid revision")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return 0, false
+	}
+	return rev, true
+}
+
+// writeJSON writes response as JSON or error status for service error.
+func writeJSON(w http.ResponseWriter, d any, err error) {
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.NotFound:
+				w.WriteHeader(http.StatusNoContent)
+			case codes.Unimplemented:
+				http.Error(w, "Not implemented", http.StatusNotImplemented)
+			case codes.Internal:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(d); err != nil {
+		logger.Log.Info("JSON encode error", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetRevisions godoc
+// @Summary Get song revisions
+// @Description Get song states replaced by updates, deletes and restores
+// @Tags Revisions
+// @Produce json
+// @Param id path string true "Song id"
+// @Success 200 {object} models.ResponseGetRevisions "Song revisions"
+// @Failure 204 "Song not found"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /song/{id}/revisions [get]
+func (h *HTTP) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	d, err := h.s.GetRevisions(r.Context(), r.PathValue("id"))
+	writeJSON(w, &d, err)
+}
+
+// GetRevision godoc
+// @Summary Get song revision
+// @Description Get song state replaced by update, delete or restore
+// @Tags Revisions
+// @Produce json
+// @Param id path string true "Song id"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} models.Revision "Song revision"
+// @Failure 204 "Revision not found"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /song/{id}/revisions/{rev} [get]
+func (h *HTTP) GetRevision(w http.ResponseWriter, r *http.Request) {
+	rev, ok := revision(w, r.PathValue("rev"))
+	if !ok {
+		return
+	}
+	d, err := h.s.GetRevision(r.Context(), r.PathValue("id"), rev)
+	writeJSON(w, &d, err)
+}
+
+// GetRevisionDiff godoc
+// @Summary Get lyrics diff
+// @Description Get line based lyrics diff from revision to other revision or current song
+// @Tags Revisions
+// @Produce json
+// @Param id path string true "Song id"
+// @Param rev path int true "Revision number"
+// @Param to query int false "Other revision number, current song if omitted"
+// @Success 200 {object} models.ResponseGetDiff "Lyrics diff"
+// @Failure 204 "Revision not found"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /song/{id}/revisions/{rev}/diff [get]
+func (h *HTTP) GetRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	rev, ok := revision(w, r.PathValue("rev"))
+	if !ok {
+		return
+	}
+	var to int
+	if toStr := r.URL.Query().Get("to"); len(toStr) > 0 {
+		if to, ok = revision(w, toStr); !ok {
+			return
+		}
+	}
+	d, err := h.s.Diff(r.Context(), r.PathValue("id"), rev, to)
+	writeJSON(w, &d, err)
+}
+
+// RevertRevision godoc
+// @Summary Revert song
+// @Description Roll song group, name, release date, text and link back to revision, current state is saved as new revision
+// @Tags Revisions
+// @Param id path string true "Song id"
+// @Param rev path int true "Revision number"
+// @Success 202 "Song reverted"
+// @Failure 204 "Song or revision not found"
+// @Failure 400 "Bad request"
+// @Failure 409 "Song with reverted name already exists"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /song/{id}/revisions/{rev}/revert [post]
+func (h *HTTP) RevertRevision(w http.ResponseWriter, r *http.Request) {
+	rev, ok := revision(w, r.PathValue("rev"))
+	if !ok {
+		return
+	}
+	if err := h.s.Revert(r.Context(), r.PathValue("id"), rev); err != nil {
+		if e, ok := status.FromError(err); ok {
+			switch e.Code() {
+			case codes.NotFound:
+				w.WriteHeader(http.StatusNoContent)
+			case codes.AlreadyExists:
+				w.WriteHeader(http.StatusConflict)
+			case codes.Unimplemented:
+				http.Error(w, "Not implemented", http.StatusNotImplemented)
+			case codes.Internal:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
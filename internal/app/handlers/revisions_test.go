@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/service"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestHTTP_GetRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	type want struct {
+		contentType string
+		code        int
+	}
+	tests := []struct {
+		name string
+		err  error
+		want want
+	}{
+		{name: "positive test #1", want: want{code: http.StatusOK, contentType: "application/json"}},
+		{name: "negative test #1", err: sql.ErrNoRows, want: want{code: http.StatusNoContent}},
+		{name: "negative test #2", err: errors.New("test"), want: want{code: http.StatusInternalServerError}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/song/{id}/revisions", nil)
+			r.SetPathValue("id", id)
+			w := httptest.NewRecorder()
+			ms.EXPECT().GetRevisions(gomock.Any(), id).Return([]models.Revision{}, tt.err)
+			h.GetRevisions(w, r)
+			res := w.Result()
+			assert.Equal(t, tt.want.code, res.StatusCode)
+			assert.Equal(t, tt.want.contentType, res.Header.Get("Content-Type"))
+			if err := res.Body.Close(); err != nil {
+				panic(err)
+			}
+		})
+	}
+}
+
+func TestHTTP_GetRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	tests := []struct {
+		name string
+		rev  string
+		call bool
+		err  error
+		code int
+	}{
+		{name: "positive test #1", rev: "1", call: true, code: http.StatusOK},
+		{name: "negative test #1", rev: "1", call: true, err: sql.ErrNoRows, code: http.StatusNoContent},
+		{name: "negative test #2", rev: "0", code: http.StatusBadRequest},
+		{name: "negative test #3", rev: "x", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/song/{id}/revisions/{rev}", nil)
+			r.SetPathValue("id", id)
+			r.SetPathValue("rev", tt.rev)
+			w := httptest.NewRecorder()
+			if tt.call {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{Rev: 1}, tt.err)
+			}
+			h.GetRevision(w, r)
+			res := w.Result()
+			assert.Equal(t, tt.code, res.StatusCode)
+			if err := res.Body.Close(); err != nil {
+				panic(err)
+			}
+		})
+	}
+}
+
+func TestHTTP_GetRevisionDiff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	tests := []struct {
+		name    string
+		query   string
+		prepare func()
+		code    int
+	}{
+		{
+			name:  "positive test #1",
+			query: "?to=2",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{Text: "a"}, nil)
+				ms.EXPECT().GetRevision(gomock.Any(), id, 2).Return(models.Revision{Text: "b"}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "negative test #1",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{}, sql.ErrNoRows)
+			},
+			code: http.StatusNoContent,
+		},
+		{name: "negative test #2", query: "?to=bad", prepare: func() {}, code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/song/{id}/revisions/{rev}/diff"+tt.query, nil)
+			r.SetPathValue("id", id)
+			r.SetPathValue("rev", "1")
+			w := httptest.NewRecorder()
+			tt.prepare()
+			h.GetRevisionDiff(w, r)
+			res := w.Result()
+			assert.Equal(t, tt.code, res.StatusCode)
+			if err := res.Body.Close(); err != nil {
+				panic(err)
+			}
+		})
+	}
+}
+
+func TestHTTP_RevertRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	rev := models.Revision{Rev: 1, Group: "Muse", Song: "Hysteria", Text: "It's bugging me"}
+	tests := []struct {
+		name    string
+		rev     string
+		prepare func()
+		code    int
+	}{
+		{
+			name: "positive test #1",
+			rev:  "1",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(rev, nil)
+				ms.EXPECT().Patch(gomock.Any(), id, 0, gomock.Any()).Return(nil)
+			},
+			code: http.StatusAccepted,
+		},
+		{
+			name: "negative test #1",
+			rev:  "1",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{}, sql.ErrNoRows)
+			},
+			code: http.StatusNoContent,
+		},
+		{name: "negative test #2", rev: "-1", prepare: func() {}, code: http.StatusBadRequest},
+		{
+			name: "negative test #3",
+			rev:  "1",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(rev, nil)
+				ms.EXPECT().Patch(gomock.Any(), id, 0, gomock.Any()).Return(storage.ErrUniqueViolation)
+			},
+			code: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/song/{id}/revisions/{rev}/revert", nil)
+			r.SetPathValue("id", id)
+			r.SetPathValue("rev", tt.rev)
+			w := httptest.NewRecorder()
+			tt.prepare()
+			h.RevertRevision(w, r)
+			res := w.Result()
+			assert.Equal(t, tt.code, res.StatusCode)
+			if err := res.Body.Close(); err != nil {
+				panic(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/service"
+)
+
+// Search godoc
+// @Summary Search songs
+// @Description Full text search by lyrics, group and song ranked by relevance, supports "quoted phrases", or and -excluded words
+// @Tags Search
+// @Produce json
+// @Param q query string true "Search query" example("soul alight" or glaciers)
+// @Param lang query string false "Search language" Enums(english, russian) default(english)
+// @Param page query int false "Page number" default(1)
+// @Param size query int false "Page size" default(10)
+// @Success 200 {object} models.ResponseSearch "Found songs with highlighted verses"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Failure 501 "Not implemented by storage"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /search [get]
+func (h *HTTP) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	d := models.RequestSearch{
+		Query: q.Get("q"),
+		Lang:  q.Get("lang"),
+		Page:  service.DefaultPage,
+		Size:  service.DefaultSizeSongs,
+	}
+	if len(d.Query) == 0 {
+		logger.Log.Info("empty search query")
+		http.Error(w, "Bad request: q is required", http.StatusBadRequest)
+		return
+	}
+	switch d.Lang {
+	case "":
+		d.Lang = models.LangEnglish
+	case models.LangEnglish, models.LangRussian:
+	default:
+		logger.Log.Info("invalid search language")
+		http.Error(w, "Bad request: lang must be english or russian", http.StatusBadRequest)
+		return
+	}
+	var err error
+	if v := q.Get("page"); len(v) > 0 {
+		if d.Page, err = strconv.Atoi(v); err != nil || d.Page < 1 {
+			logger.Log.Info("invalid page")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("size"); len(v) > 0 {
+		if d.Size, err = strconv.Atoi(v); err != nil || d.Size < 1 {
+			logger.Log.Info("invalid size")
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+	}
+	res, err := h.s.Search(r.Context(), d)
+	writeJSON(w, &res, err)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/service"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestHTTP_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name  string
+		query string
+		req   *models.RequestSearch
+		err   error
+		code  int
+	}{
+		{
+			name:  "positive test #1",
+			query: "?q=%22soul+alight%22",
+			req:   &models.RequestSearch{Query: `"soul alight"`, Lang: models.LangEnglish, Page: 1, Size: 10},
+			code:  http.StatusOK,
+		},
+		{
+			name:  "positive test #2",
+			query: "?q=кровь&lang=russian&page=2&size=5",
+			req:   &models.RequestSearch{Query: "кровь", Lang: models.LangRussian, Page: 2, Size: 5},
+			code:  http.StatusOK,
+		},
+		{name: "negative test #1", code: http.StatusBadRequest},
+		{name: "negative test #2", query: "?q=soul&lang=german", code: http.StatusBadRequest},
+		{name: "negative test #3", query: "?q=soul&size=0", code: http.StatusBadRequest},
+		{
+			name:  "negative test #4",
+			query: "?q=soul",
+			req:   &models.RequestSearch{Query: "soul", Lang: models.LangEnglish, Page: 1, Size: 10},
+			err:   storage.ErrNotSupported,
+			code:  http.StatusNotImplemented,
+		},
+		{
+			name:  "negative test #5",
+			query: "?q=soul",
+			req:   &models.RequestSearch{Query: "soul", Lang: models.LangEnglish, Page: 1, Size: 10},
+			err:   errors.New("test"),
+			code:  http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/search"+tt.query, nil)
+			w := httptest.NewRecorder()
+			if tt.req != nil {
+				ms.EXPECT().Search(gomock.Any(), *tt.req).Return(models.ResponseSearch{}, tt.err)
+			}
+			h.Search(w, r)
+			res := w.Result()
+			assert.Equal(t, tt.code, res.StatusCode)
+			if err := res.Body.Close(); err != nil {
+				panic(err)
+			}
+		})
+	}
+}
+
+func TestHTTP_Suggest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name  string
+		query string
+		q     string
+		limit int
+		err   error
+		code  int
+	}{
+		{name: "positive test #1", query: "?q=Supermasive", q: "Supermasive", limit: 10, code: http.StatusOK},
+		{name: "positive test #2", query: "?q=Musе&limit=3", q: "Musе", limit: 3, code: http.StatusOK},
+		{name: "negative test #1", code: http.StatusBadRequest},
+		{name: "negative test #2", query: "?q=Muse&limit=0", code: http.StatusBadRequest},
+		{name: "negative test #3", query: "?q=Muse&limit=x", code: http.StatusBadRequest},
+		{
+			name:  "negative test #4",
+			query: "?q=Muse",
+			q:     "Muse",
+			limit: 10,
+			err:   errors.New("test"),
+			code:  http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.limit > 0 {
+				ms.EXPECT().Suggest(gomock.Any(), tt.q, tt.limit).Return(nil, tt.err)
+			}
+			w := httptest.NewRecorder()
+			h.Suggest(w, httptest.NewRequest(http.MethodGet, "/api/songs/suggest"+tt.query, nil))
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
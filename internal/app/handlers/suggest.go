@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/service"
+)
+
+// Suggest godoc
+// @Summary Suggest songs
+// @Description Typo tolerant lookup of songs by group and song names using trigram similarity, cyrillic letters looking like latin ones are treated as latin
+// @Tags Songs
+// @Produce json
+// @Param q query string true "Group or song name" example(Supermasive)
+// @Param limit query int false "Maximum number of suggestions" default(10)
+// @Success 200 {object} models.ResponseSuggest "Similar songs, most similar first"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /songs/suggest [get]
+func (h *HTTP) Suggest(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if len(q) == 0 {
+		logger.Log.Info("empty suggest query")
+		http.Error(w, "Bad request: q is required", http.StatusBadRequest)
+		return
+	}
+	limit := service.DefaultSizeSuggest
+	if v := r.URL.Query().Get("limit"); len(v) > 0 {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > 100 {
+			logger.Log.Info("invalid limit")
+			http.Error(w, "Bad request: limit must be number from 1 to 100", http.StatusBadRequest)
+			return
+		}
+	}
+	d, err := h.s.Suggest(r.Context(), q, limit)
+	writeJSON(w, &d, err)
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// validTag reports whether tag name is non empty and has no commas
+// separating tags in songs filter.
+func validTag(name string) bool { return len(name) > 0 && !strings.Contains(name, ",") }
+
+// validKind reports whether tag kind is known, empty kind is custom.
+func validKind(kind string) bool {
+	switch kind {
+	case "", models.TagGenre, models.TagMood, models.TagLanguage, models.TagCustom:
+		return true
+	}
+	return false
+}
+
+// tagsRequest parses song tags request, ok is false if bad request is
+// sent.
+func tagsRequest(w http.ResponseWriter, r *http.Request) (models.RequestSongTags, bool) {
+	var req models.RequestSongTags
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Log.Info("JSON decode error", zap.Error(err))
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return req, false
+	}
+	if len(req.Tags) == 0 || !validKind(req.Kind) {
+		logger.Log.Info("empty tags or invalid kind")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return req, false
+	}
+	for _, v := range req.Tags {
+		if !validTag(v) {
+			logger.Log.Info("invalid tag", zap.String("tag", v))
+			http.Error(w, "Bad request: tag must be non empty and have no commas", http.StatusBadRequest)
+			return req, false
+		}
+	}
+	return req, true
+}
+
+// PostSongTags godoc
+// @Summary Tag song
+// @Description Add tags to song, missing tags are created of request kind, custom by default
+// @Tags Tags
+// @Accept json
+// @Param id path string true "Song id"
+// @Param tags body models.RequestSongTags true "Song tags"
+// @Success 202 "Song tagged"
+// @Failure 204 "Song not found"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /song/{id}/tags [post]
+func (h *HTTP) PostSongTags(w http.ResponseWriter, r *http.Request) {
+	req, ok := tagsRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.AddTags(r.Context(), r.PathValue("id"), req); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeleteSongTags godoc
+// @Summary Untag song
+// @Description Remove tags from song, tags are kept
+// @Tags Tags
+// @Accept json
+// @Param id path string true "Song id"
+// @Param tags body models.RequestSongTags true "Song tags"
+// @Success 202 "Song untagged"
+// @Failure 204 "Song not found"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /song/{id}/tags [delete]
+func (h *HTTP) DeleteSongTags(w http.ResponseWriter, r *http.Request) {
+	req, ok := tagsRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.RemoveTags(r.Context(), r.PathValue("id"), req); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// GetTags godoc
+// @Summary Get tags
+// @Description Get tags ordered by name with numbers of songs tagged
+// @Tags Tags
+// @Produce json
+// @Param kind query string false "Tag kind" Enums(genre, mood, language, custom)
+// @Success 200 {object} models.ResponseGetTags "Tags list"
+// @Failure 400 "Bad request"
+// @Failure 500 "Internal server error"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /tags [get]
+func (h *HTTP) GetTags(w http.ResponseWriter, r *http.Request) {
+	kind := r.URL.Query().Get("kind")
+	if !validKind(kind) {
+		logger.Log.Info("invalid kind")
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	d, err := h.s.GetTags(r.Context(), kind)
+	writeJSON(w, &d, err)
+}
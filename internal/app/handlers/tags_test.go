@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/service"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestHTTP_PostSongTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", body: `{"tags": ["rock", "english"], "kind": "genre"}`, code: http.StatusAccepted},
+		{name: "negative test #1", body: `{"tags": []}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `{"tags": ["rock,pop"]}`, code: http.StatusBadRequest},
+		{name: "negative test #3", body: `{"tags": ["rock"], "kind": "era"}`, code: http.StatusBadRequest},
+		{name: "negative test #4", body: `bad json`, code: http.StatusBadRequest},
+		{name: "negative test #5", body: `{"tags": ["rock", "english"], "kind": "genre"}`,
+			err: storage.ErrNotAffected, code: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().AddTags(gomock.Any(), "1", models.TagGenre, []string{"rock", "english"}).Return(tt.err)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/song/1/tags", strings.NewReader(tt.body))
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.PostSongTags(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_DeleteSongTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "positive test #1", body: `{"tags": ["rock"]}`, code: http.StatusAccepted},
+		{name: "negative test #1", body: `{"tags": [""]}`, code: http.StatusBadRequest},
+		{name: "negative test #2", body: `{"tags": ["rock"]}`, err: errors.New("test"),
+			code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().RemoveTags(gomock.Any(), "1", []string{"rock"}).Return(tt.err)
+			}
+			r := httptest.NewRequest(http.MethodDelete, "/api/song/1/tags", strings.NewReader(tt.body))
+			r.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+			h.DeleteSongTags(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
+
+func TestHTTP_GetTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	h := NewHTTP(service.New(cfg, ms, requests.New(cfg)))
+	tests := []struct {
+		name  string
+		query string
+		err   error
+		code  int
+	}{
+		{name: "positive test #1", query: "?kind=mood", code: http.StatusOK},
+		{name: "negative test #1", query: "?kind=era", code: http.StatusBadRequest},
+		{name: "negative test #2", query: "?kind=mood", err: errors.New("test"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code != http.StatusBadRequest {
+				ms.EXPECT().GetTags(gomock.Any(), models.TagMood).Return([]models.Tag{}, tt.err)
+			}
+			w := httptest.NewRecorder()
+			h.GetTags(w, httptest.NewRequest(http.MethodGet, "/api/tags"+tt.query, nil))
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.code, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Package jwks loads JSON web key sets from file or URL and caches keys
+// verifying tokens.
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+)
+
+var (
+	// ErrUnavailable is returned when key set is neither cached nor loaded.
+	ErrUnavailable = errors.New("key set unavailable")
+	// ErrUnknownKey is returned when key set has no key of id and
+	// algorithm.
+	ErrUnknownKey = errors.New("unknown key")
+)
+
+// MinReload is minimal interval between key set reloads caused by
+// unknown key ids.
+const MinReload = 10 * time.Second
+
+// key is verification key of key set.
+type key struct {
+	id  string
+	kty string
+	alg string // empty fits any algorithm of key type
+	pub any    // *rsa.PublicKey, *ecdsa.PublicKey or []byte
+}
+
+// fits reports whether key verifies tokens signed by algorithm.
+func (k key) fits(alg string) bool {
+	if len(k.alg) > 0 && k.alg != alg {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(alg, "RS"):
+		return k.kty == "RSA"
+	case strings.HasPrefix(alg, "ES"):
+		return k.kty == "EC"
+	case strings.HasPrefix(alg, "HS"):
+		return k.kty == "oct"
+	}
+	return false
+}
+
+// Set caches keys of key set loaded from file or http(s) URL, keys are
+// reloaded after refresh interval or on unknown key id.
+type Set struct {
+	src     string
+	refresh time.Duration
+	client  *http.Client
+	group   singleflight.Group // runs single reload for concurrent callers
+
+	mu     sync.Mutex
+	keys   []key
+	loaded time.Time
+}
+
+// New creates Set of key set source, keys are loaded on first use.
+func New(src string, refresh time.Duration) *Set {
+	return &Set{src: src, refresh: refresh, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Key returns key of id verifying tokens signed by algorithm, cached keys
+// are used while key set is reloaded or can not be reloaded.
+func (s *Set) Key(ctx context.Context, kid, alg string) (any, error) {
+	s.mu.Lock()
+	k, ok := s.find(kid, alg)
+	due := s.keys == nil || time.Since(s.loaded) >= s.refresh || (!ok && time.Since(s.loaded) >= MinReload)
+	s.mu.Unlock()
+	if !due {
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return k.pub, nil
+	}
+	res := s.group.DoChan("", func() (any, error) {
+		return nil, s.load(context.WithoutCancel(ctx)) // outlives caller serving cached key
+	})
+	if ok {
+		return k.pub, nil
+	}
+	var err error
+	select {
+	case r := <-res:
+		err = r.Err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, ctx.Err())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	if k, ok = s.find(kid, alg); !ok {
+		return nil, ErrUnknownKey
+	}
+	return k.pub, nil
+}
+
+// find returns cached key of id verifying tokens signed by algorithm.
+func (s *Set) find(kid, alg string) (key, bool) {
+	for _, k := range s.keys {
+		if k.id == kid && k.fits(alg) {
+			return k, true
+		}
+	}
+	return key{}, false
+}
+
+// load reads key set replacing cached keys, cached keys are kept if key
+// set can not be read.
+func (s *Set) load(ctx context.Context) error {
+	data, err := s.read(ctx)
+	var keys []key
+	if err == nil {
+		keys, err = parse(data)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		if s.keys != nil {
+			logger.Log.Info("failed reload key set", zap.Error(err))
+		}
+		return err
+	}
+	s.keys, s.loaded = keys, time.Now()
+	return nil
+}
+
+// read returns key set from http(s) URL or file.
+func (s *Set) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.src, "http://") && !strings.HasPrefix(s.src, "https://") {
+		return os.ReadFile(strings.TrimPrefix(s.src, "file://"))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.src, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Log.Error("failed close body", zap.Error(err))
+		}
+	}()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("key set status %d", res.StatusCode)
+	}
+	return io.ReadAll(res.Body)
+}
+
+// jwk describes JSON web key fields used for signature verification.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parse reads signature verification keys of key set, keys of other use
+// or unsupported type are skipped.
+func parse(data []byte) ([]key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := make([]key, 0, len(set.Keys))
+	for _, v := range set.Keys {
+		if len(v.Use) > 0 && v.Use != "sig" {
+			continue
+		}
+		pub, err := v.public()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", v.Kid, err)
+		}
+		if pub != nil {
+			keys = append(keys, key{id: v.Kid, kty: v.Kty, alg: v.Alg, pub: pub})
+		}
+	}
+	return keys, nil
+}
+
+// public returns verification key, nil for unsupported key type.
+func (v jwk) public() (any, error) {
+	switch v.Kty {
+	case "RSA":
+		n, err := number(v.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := number(v.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch v.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", v.Crv)
+		}
+		x, err := number(v.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := number(v.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		k, err := base64.RawURLEncoding.DecodeString(v.K)
+		if err != nil {
+			return nil, err
+		}
+		if len(k) == 0 {
+			return nil, errors.New("empty key")
+		}
+		return k, nil
+	}
+	return nil, nil
+}
+
+// number decodes base64url big endian unsigned integer.
+func number(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty number")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,180 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func rsaJWK(t *testing.T, kid string) (*rsa.PrivateKey, string) {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return k, fmt.Sprintf(`{"kty":"RSA","kid":%q,"alg":"RS256","use":"sig","n":%q,"e":%q}`,
+		kid, b64(k.N.Bytes()), b64(big.NewInt(int64(k.E)).Bytes()))
+}
+
+func ecJWK(t *testing.T, kid string) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return k, fmt.Sprintf(`{"kty":"EC","kid":%q,"crv":"P-256","x":%q,"y":%q}`,
+		kid, b64(k.X.FillBytes(make([]byte, 32))), b64(k.Y.FillBytes(make([]byte, 32))))
+}
+
+func Test_parse(t *testing.T) {
+	rk, rj := rsaJWK(t, "r1")
+	ek, ej := ecJWK(t, "e1")
+	tests := []struct {
+		name    string
+		data    string
+		want    []key
+		wantErr bool
+	}{
+		{
+			name: "positive test #1",
+			data: `{"keys":[` + rj + `,` + ej + `,{"kty":"oct","kid":"h1","k":"c2VjcmV0"}]}`,
+			want: []key{
+				{id: "r1", kty: "RSA", alg: "RS256", pub: &rk.PublicKey},
+				{id: "e1", kty: "EC", pub: &ek.PublicKey},
+				{id: "h1", kty: "oct", pub: []byte("secret")},
+			},
+		},
+		{
+			name: "positive test #2",
+			data: `{"keys":[{"kty":"oct","kid":"h1","use":"enc","k":"c2VjcmV0"},{"kty":"OKP","kid":"o1","crv":"Ed25519","x":"AA"}]}`,
+			want: []key{},
+		},
+		{name: "negative test #1", data: `{"keys":[{"kty":"EC","kid":"e1","crv":"P-256","x":"AQ","y":"AQ"}]}`, wantErr: true},
+		{name: "negative test #2", data: `{"keys":[{"kty":"EC","kid":"e1","crv":"P-192","x":"AQ","y":"AQ"}]}`, wantErr: true},
+		{name: "negative test #3", data: `{"keys":[{"kty":"RSA","kid":"r1","n":"","e":"AQAB"}]}`, wantErr: true},
+		{name: "negative test #4", data: `not json`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSet_Key(t *testing.T) {
+	rk, rj := rsaJWK(t, "r1")
+	ek, ej := ecJWK(t, "e1")
+	var hits atomic.Int32
+	body := `{"keys":[` + rj + `]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	s := New(srv.URL, time.Hour)
+
+	got, err := s.Key(ctx, "r1", "RS256")
+	require.NoError(t, err)
+	assert.Equal(t, &rk.PublicKey, got)
+	_, err = s.Key(ctx, "r1", "RS256")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hits.Load())
+	_, err = s.Key(ctx, "r1", "HS256")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// rotated key is loaded on unknown key id not sooner than MinReload
+	body = `{"keys":[` + rj + `,` + ej + `]}`
+	_, err = s.Key(ctx, "e1", "ES256")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	s.loaded = s.loaded.Add(-MinReload)
+	got, err = s.Key(ctx, "e1", "ES256")
+	require.NoError(t, err)
+	assert.Equal(t, &ek.PublicKey, got)
+	assert.Equal(t, int32(2), hits.Load())
+
+	// cached keys are used while key set is unavailable
+	srv.Close()
+	s.loaded = s.loaded.Add(-time.Hour)
+	_, err = s.Key(ctx, "r1", "RS256")
+	require.NoError(t, err)
+	_, err = New(srv.URL, time.Hour).Key(ctx, "r1", "RS256")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestSet_Key_reload(t *testing.T) {
+	rk, rj := rsaJWK(t, "r1")
+	_, ej := ecJWK(t, "e1")
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) > 1 {
+			<-release
+		}
+		fmt.Fprint(w, `{"keys":[`+rj+`,`+ej+`]}`)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	s := New(srv.URL, time.Hour)
+	_, err := s.Key(ctx, "r1", "RS256")
+	require.NoError(t, err)
+
+	// cached key is served while due reload is blocked
+	s.mu.Lock()
+	s.loaded = s.loaded.Add(-time.Hour)
+	s.keys = s.keys[:1]
+	s.mu.Unlock()
+	got, err := s.Key(ctx, "r1", "RS256")
+	require.NoError(t, err)
+	assert.Equal(t, &rk.PublicKey, got)
+
+	// callers of unknown key wait for the same reload
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, err := s.Key(ctx, "e1", "ES256")
+			errs <- err
+		}()
+	}
+	close(release)
+	for range 3 {
+		assert.NoError(t, <-errs)
+	}
+	assert.Equal(t, int32(2), hits.Load())
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = New(srv.URL, time.Hour).Key(canceled, "r1", "RS256")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestSet_Key_file(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kty":"oct","kid":"h1","alg":"HS256","k":"c2VjcmV0"}]}`), 0o600))
+	for _, src := range []string{path, "file://" + path} {
+		got, err := New(src, time.Hour).Key(context.Background(), "h1", "HS256")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret"), got)
+	}
+	_, err := New(path+".missing", time.Hour).Key(context.Background(), "h1", "HS256")
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
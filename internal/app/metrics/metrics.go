@@ -0,0 +1,96 @@
+// Package metrics defines Prometheus metrics of server.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "songs"
+
+// Status labels of storage queries.
+const (
+	StatusOK       = "ok"
+	StatusNotFound = "not_found" // missing or not changed rows
+	StatusError    = "error"
+)
+
+// Registry holds server metrics and Go runtime and process stats.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts served requests by method, route pattern and
+	// status code.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Served HTTP requests.",
+	}, []string{"method", "route", "code"})
+	// HTTPDuration observes served requests latency by method, route
+	// pattern and status code.
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Served HTTP requests latency.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+	// StorageDuration observes storage queries latency by storage method
+	// and status.
+	StorageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_query_duration_seconds",
+		Help:      "Storage queries latency.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"query", "status"})
+	// MusicInfoDuration observes music info API requests latency by
+	// response status code, zero code is failed request.
+	MusicInfoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "music_info_request_duration_seconds",
+		Help:      "Music info API requests latency.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"code"})
+	// MusicInfoErrors counts failed music info API requests and requests
+	// answered not with 200.
+	MusicInfoErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "music_info_request_errors_total",
+		Help:      "Failed music info API requests.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration, StorageDuration, MusicInfoDuration, MusicInfoErrors)
+}
+
+// Handler serves metrics in Prometheus text format.
+func Handler() http.Handler { return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}) }
+
+// ObserveHTTP records served request.
+func ObserveHTTP(method, route string, code int, d time.Duration) {
+	c := strconv.Itoa(code)
+	HTTPRequests.WithLabelValues(method, route, c).Inc()
+	HTTPDuration.WithLabelValues(method, route, c).Observe(d.Seconds())
+}
+
+// ObserveStorage records storage query finished with status.
+func ObserveStorage(query string, d time.Duration, status string) {
+	StorageDuration.WithLabelValues(query, status).Observe(d.Seconds())
+}
+
+// ObserveMusicInfo records music info API request answered with code, zero
+// code is failed request.
+func ObserveMusicInfo(code int, d time.Duration) {
+	MusicInfoDuration.WithLabelValues(strconv.Itoa(code)).Observe(d.Seconds())
+	if code != http.StatusOK {
+		MusicInfoErrors.Inc()
+	}
+}
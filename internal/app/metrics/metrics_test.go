@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveHTTP(t *testing.T) {
+	before := testutil.ToFloat64(HTTPRequests.WithLabelValues(http.MethodGet, "/api/song/{id}", "200"))
+	ObserveHTTP(http.MethodGet, "/api/song/{id}", http.StatusOK, time.Millisecond)
+	assert.Equal(t, before+1, testutil.ToFloat64(HTTPRequests.WithLabelValues(http.MethodGet, "/api/song/{id}", "200")))
+}
+
+func TestObserveStorage(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		series int
+	}{
+		{name: "positive test #1", status: StatusOK, series: 1},
+		{name: "positive test #2", status: StatusNotFound, series: 2},
+		{name: "positive test #3", status: StatusError, series: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ObserveStorage("Get", time.Millisecond, tt.status)
+			assert.Equal(t, tt.series, testutil.CollectAndCount(StorageDuration))
+		})
+	}
+}
+
+func TestObserveMusicInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		code int
+		errs float64
+	}{
+		{name: "positive test #1", code: http.StatusOK},
+		{name: "negative test #1", code: http.StatusInternalServerError, errs: 1},
+		{name: "negative test #2", errs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.ToFloat64(MusicInfoErrors)
+			ObserveMusicInfo(tt.code, time.Millisecond)
+			assert.Equal(t, before+tt.errs, testutil.ToFloat64(MusicInfoErrors))
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ObserveHTTP(http.MethodGet, "/api/songs", http.StatusOK, time.Millisecond)
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	res := rec.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `songs_http_requests_total{code="200",method="GET",route="/api/songs"}`)
+	assert.Contains(t, string(b), "go_goroutines")
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/xEgorka/project4/internal/app/models"
@@ -50,6 +51,108 @@ func (mr *MockStorageMockRecorder) Add(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockStorage)(nil).Add), arg0, arg1)
 }
 
+// AddAPIKey mocks base method.
+func (m *MockStorage) AddAPIKey(arg0 context.Context, arg1 models.APIKey) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAPIKey indicates an expected call of AddAPIKey.
+func (mr *MockStorageMockRecorder) AddAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAPIKey", reflect.TypeOf((*MockStorage)(nil).AddAPIKey), arg0, arg1)
+}
+
+// AddAlbum mocks base method.
+func (m *MockStorage) AddAlbum(arg0 context.Context, arg1 models.Album) (models.Album, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAlbum", arg0, arg1)
+	ret0, _ := ret[0].(models.Album)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAlbum indicates an expected call of AddAlbum.
+func (mr *MockStorageMockRecorder) AddAlbum(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAlbum", reflect.TypeOf((*MockStorage)(nil).AddAlbum), arg0, arg1)
+}
+
+// AddArtist mocks base method.
+func (m *MockStorage) AddArtist(arg0 context.Context, arg1 models.Artist) (models.Artist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddArtist", arg0, arg1)
+	ret0, _ := ret[0].(models.Artist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddArtist indicates an expected call of AddArtist.
+func (mr *MockStorageMockRecorder) AddArtist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddArtist", reflect.TypeOf((*MockStorage)(nil).AddArtist), arg0, arg1)
+}
+
+// AddPlaylist mocks base method.
+func (m *MockStorage) AddPlaylist(arg0 context.Context, arg1 models.Playlist) (models.Playlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPlaylist", arg0, arg1)
+	ret0, _ := ret[0].(models.Playlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPlaylist indicates an expected call of AddPlaylist.
+func (mr *MockStorageMockRecorder) AddPlaylist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPlaylist", reflect.TypeOf((*MockStorage)(nil).AddPlaylist), arg0, arg1)
+}
+
+// AddPlaylistSong mocks base method.
+func (m *MockStorage) AddPlaylistSong(arg0 context.Context, arg1, arg2 string, arg3 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPlaylistSong", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPlaylistSong indicates an expected call of AddPlaylistSong.
+func (mr *MockStorageMockRecorder) AddPlaylistSong(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPlaylistSong", reflect.TypeOf((*MockStorage)(nil).AddPlaylistSong), arg0, arg1, arg2, arg3)
+}
+
+// AddTags mocks base method.
+func (m *MockStorage) AddTags(arg0 context.Context, arg1, arg2 string, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTags", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTags indicates an expected call of AddTags.
+func (mr *MockStorageMockRecorder) AddTags(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTags", reflect.TypeOf((*MockStorage)(nil).AddTags), arg0, arg1, arg2, arg3)
+}
+
+// AttachTrack mocks base method.
+func (m *MockStorage) AttachTrack(arg0 context.Context, arg1, arg2 string, arg3 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachTrack", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AttachTrack indicates an expected call of AttachTrack.
+func (mr *MockStorageMockRecorder) AttachTrack(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachTrack", reflect.TypeOf((*MockStorage)(nil).AttachTrack), arg0, arg1, arg2, arg3)
+}
+
 // Close mocks base method.
 func (m *MockStorage) Close() error {
 	m.ctrl.T.Helper()
@@ -65,32 +168,269 @@ func (mr *MockStorageMockRecorder) Close() *gomock.Call {
 }
 
 // Delete mocks base method.
-func (m *MockStorage) Delete(arg0 context.Context, arg1 string) error {
+func (m *MockStorage) Delete(arg0 context.Context, arg1 string, arg2 int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockStorageMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockStorageMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStorage)(nil).Delete), arg0, arg1, arg2)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockStorage) DeleteAPIKey(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockStorageMockRecorder) DeleteAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockStorage)(nil).DeleteAPIKey), arg0, arg1)
+}
+
+// DeleteArtist mocks base method.
+func (m *MockStorage) DeleteArtist(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArtist", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteArtist indicates an expected call of DeleteArtist.
+func (mr *MockStorageMockRecorder) DeleteArtist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArtist", reflect.TypeOf((*MockStorage)(nil).DeleteArtist), arg0, arg1)
+}
+
+// DeletePlaylist mocks base method.
+func (m *MockStorage) DeletePlaylist(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePlaylist", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePlaylist indicates an expected call of DeletePlaylist.
+func (mr *MockStorageMockRecorder) DeletePlaylist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlaylist", reflect.TypeOf((*MockStorage)(nil).DeletePlaylist), arg0, arg1)
+}
+
+// Get mocks base method.
+func (m *MockStorage) Get(arg0 context.Context, arg1 string) (models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStorageMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStorage)(nil).Get), arg0, arg1)
+}
+
+// GetAPIKey mocks base method.
+func (m *MockStorage) GetAPIKey(arg0 context.Context, arg1 string) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKey indicates an expected call of GetAPIKey.
+func (mr *MockStorageMockRecorder) GetAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKey", reflect.TypeOf((*MockStorage)(nil).GetAPIKey), arg0, arg1)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockStorage) GetAPIKeys(arg0 context.Context) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", arg0)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockStorageMockRecorder) GetAPIKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockStorage)(nil).GetAPIKeys), arg0)
+}
+
+// GetAlbum mocks base method.
+func (m *MockStorage) GetAlbum(arg0 context.Context, arg1 string) (models.Album, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlbum", arg0, arg1)
+	ret0, _ := ret[0].(models.Album)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlbum indicates an expected call of GetAlbum.
+func (mr *MockStorageMockRecorder) GetAlbum(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlbum", reflect.TypeOf((*MockStorage)(nil).GetAlbum), arg0, arg1)
+}
+
+// GetAlbums mocks base method.
+func (m *MockStorage) GetAlbums(arg0 context.Context, arg1 string, arg2, arg3 int) (models.ResponseGetAlbums, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlbums", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(models.ResponseGetAlbums)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlbums indicates an expected call of GetAlbums.
+func (mr *MockStorageMockRecorder) GetAlbums(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlbums", reflect.TypeOf((*MockStorage)(nil).GetAlbums), arg0, arg1, arg2, arg3)
+}
+
+// GetArtist mocks base method.
+func (m *MockStorage) GetArtist(arg0 context.Context, arg1 string) (models.Artist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArtist", arg0, arg1)
+	ret0, _ := ret[0].(models.Artist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArtist indicates an expected call of GetArtist.
+func (mr *MockStorageMockRecorder) GetArtist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArtist", reflect.TypeOf((*MockStorage)(nil).GetArtist), arg0, arg1)
+}
+
+// GetArtists mocks base method.
+func (m *MockStorage) GetArtists(arg0 context.Context, arg1, arg2 int) (models.ResponseGetArtists, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArtists", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.ResponseGetArtists)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArtists indicates an expected call of GetArtists.
+func (mr *MockStorageMockRecorder) GetArtists(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArtists", reflect.TypeOf((*MockStorage)(nil).GetArtists), arg0, arg1, arg2)
+}
+
+// GetPlaylist mocks base method.
+func (m *MockStorage) GetPlaylist(arg0 context.Context, arg1 string) (models.Playlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaylist", arg0, arg1)
+	ret0, _ := ret[0].(models.Playlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaylist indicates an expected call of GetPlaylist.
+func (mr *MockStorageMockRecorder) GetPlaylist(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStorage)(nil).Delete), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaylist", reflect.TypeOf((*MockStorage)(nil).GetPlaylist), arg0, arg1)
+}
+
+// GetPlaylistSongs mocks base method.
+func (m *MockStorage) GetPlaylistSongs(arg0 context.Context, arg1 string, arg2, arg3 int) (models.ResponseGetPlaylist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaylistSongs", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(models.ResponseGetPlaylist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaylistSongs indicates an expected call of GetPlaylistSongs.
+func (mr *MockStorageMockRecorder) GetPlaylistSongs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaylistSongs", reflect.TypeOf((*MockStorage)(nil).GetPlaylistSongs), arg0, arg1, arg2, arg3)
+}
+
+// GetPlaylists mocks base method.
+func (m *MockStorage) GetPlaylists(arg0 context.Context, arg1, arg2 int) (models.ResponseGetPlaylists, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaylists", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.ResponseGetPlaylists)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaylists indicates an expected call of GetPlaylists.
+func (mr *MockStorageMockRecorder) GetPlaylists(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaylists", reflect.TypeOf((*MockStorage)(nil).GetPlaylists), arg0, arg1, arg2)
+}
+
+// GetRevision mocks base method.
+func (m *MockStorage) GetRevision(arg0 context.Context, arg1 string, arg2 int) (models.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", arg0, arg1, arg2)
+	ret0, _ := ret[0].(models.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockStorageMockRecorder) GetRevision(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockStorage)(nil).GetRevision), arg0, arg1, arg2)
+}
+
+// GetRevisions mocks base method.
+func (m *MockStorage) GetRevisions(arg0 context.Context, arg1 string) ([]models.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", arg0, arg1)
+	ret0, _ := ret[0].([]models.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions.
+func (mr *MockStorageMockRecorder) GetRevisions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockStorage)(nil).GetRevisions), arg0, arg1)
 }
 
 // GetSongs mocks base method.
-func (m *MockStorage) GetSongs(arg0 context.Context, arg1 models.Song, arg2, arg3 int) (models.ResponseGetSongs, error) {
+func (m *MockStorage) GetSongs(arg0 context.Context, arg1 models.RequestGetSongs) (models.ResponseGetSongs, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSongs", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "GetSongs", arg0, arg1)
 	ret0, _ := ret[0].(models.ResponseGetSongs)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSongs indicates an expected call of GetSongs.
-func (mr *MockStorageMockRecorder) GetSongs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockStorageMockRecorder) GetSongs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongs", reflect.TypeOf((*MockStorage)(nil).GetSongs), arg0, arg1)
+}
+
+// GetTags mocks base method.
+func (m *MockStorage) GetTags(arg0 context.Context, arg1 string) ([]models.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", arg0, arg1)
+	ret0, _ := ret[0].([]models.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockStorageMockRecorder) GetTags(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongs", reflect.TypeOf((*MockStorage)(nil).GetSongs), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockStorage)(nil).GetTags), arg0, arg1)
 }
 
 // GetText mocks base method.
@@ -108,6 +448,49 @@ func (mr *MockStorageMockRecorder) GetText(arg0, arg1, arg2, arg3 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetText", reflect.TypeOf((*MockStorage)(nil).GetText), arg0, arg1, arg2, arg3)
 }
 
+// GetTracks mocks base method.
+func (m *MockStorage) GetTracks(arg0 context.Context, arg1 string) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTracks", arg0, arg1)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTracks indicates an expected call of GetTracks.
+func (mr *MockStorageMockRecorder) GetTracks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTracks", reflect.TypeOf((*MockStorage)(nil).GetTracks), arg0, arg1)
+}
+
+// MovePlaylistSong mocks base method.
+func (m *MockStorage) MovePlaylistSong(arg0 context.Context, arg1, arg2 string, arg3 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MovePlaylistSong", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MovePlaylistSong indicates an expected call of MovePlaylistSong.
+func (mr *MockStorageMockRecorder) MovePlaylistSong(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MovePlaylistSong", reflect.TypeOf((*MockStorage)(nil).MovePlaylistSong), arg0, arg1, arg2, arg3)
+}
+
+// Patch mocks base method.
+func (m *MockStorage) Patch(arg0 context.Context, arg1 string, arg2 int, arg3 models.RequestPatchSong) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Patch indicates an expected call of Patch.
+func (mr *MockStorageMockRecorder) Patch(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockStorage)(nil).Patch), arg0, arg1, arg2, arg3)
+}
+
 // Ping mocks base method.
 func (m *MockStorage) Ping() error {
 	m.ctrl.T.Helper()
@@ -122,16 +505,160 @@ func (mr *MockStorageMockRecorder) Ping() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStorage)(nil).Ping))
 }
 
+// Purge mocks base method.
+func (m *MockStorage) Purge(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockStorageMockRecorder) Purge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockStorage)(nil).Purge), arg0, arg1, arg2)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockStorage) PurgeDeleted(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockStorageMockRecorder) PurgeDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockStorage)(nil).PurgeDeleted), arg0, arg1)
+}
+
+// RemovePlaylistSong mocks base method.
+func (m *MockStorage) RemovePlaylistSong(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePlaylistSong", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePlaylistSong indicates an expected call of RemovePlaylistSong.
+func (mr *MockStorageMockRecorder) RemovePlaylistSong(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePlaylistSong", reflect.TypeOf((*MockStorage)(nil).RemovePlaylistSong), arg0, arg1, arg2)
+}
+
+// RemoveTags mocks base method.
+func (m *MockStorage) RemoveTags(arg0 context.Context, arg1 string, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTags", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTags indicates an expected call of RemoveTags.
+func (mr *MockStorageMockRecorder) RemoveTags(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTags", reflect.TypeOf((*MockStorage)(nil).RemoveTags), arg0, arg1, arg2)
+}
+
+// RenamePlaylist mocks base method.
+func (m *MockStorage) RenamePlaylist(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenamePlaylist", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenamePlaylist indicates an expected call of RenamePlaylist.
+func (mr *MockStorageMockRecorder) RenamePlaylist(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenamePlaylist", reflect.TypeOf((*MockStorage)(nil).RenamePlaylist), arg0, arg1, arg2)
+}
+
+// ResolveArtist mocks base method.
+func (m *MockStorage) ResolveArtist(arg0 context.Context, arg1 string) (models.Artist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveArtist", arg0, arg1)
+	ret0, _ := ret[0].(models.Artist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveArtist indicates an expected call of ResolveArtist.
+func (mr *MockStorageMockRecorder) ResolveArtist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveArtist", reflect.TypeOf((*MockStorage)(nil).ResolveArtist), arg0, arg1)
+}
+
+// Restore mocks base method.
+func (m *MockStorage) Restore(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockStorageMockRecorder) Restore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockStorage)(nil).Restore), arg0, arg1)
+}
+
+// Search mocks base method.
+func (m *MockStorage) Search(arg0 context.Context, arg1 models.RequestSearch) (models.ResponseSearch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].(models.ResponseSearch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockStorageMockRecorder) Search(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockStorage)(nil).Search), arg0, arg1)
+}
+
+// Suggest mocks base method.
+func (m *MockStorage) Suggest(arg0 context.Context, arg1 string, arg2 int) ([]models.Suggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suggest", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Suggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suggest indicates an expected call of Suggest.
+func (mr *MockStorageMockRecorder) Suggest(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suggest", reflect.TypeOf((*MockStorage)(nil).Suggest), arg0, arg1, arg2)
+}
+
 // Update mocks base method.
-func (m *MockStorage) Update(arg0 context.Context, arg1 string, arg2 models.RequestUpdateSong) error {
+func (m *MockStorage) Update(arg0 context.Context, arg1 string, arg2 int, arg3 models.RequestUpdateSong) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockStorageMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockStorageMockRecorder) Update(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockStorage)(nil).Update), arg0, arg1, arg2, arg3)
+}
+
+// UpdateArtist mocks base method.
+func (m *MockStorage) UpdateArtist(arg0 context.Context, arg1 models.Artist) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateArtist", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateArtist indicates an expected call of UpdateArtist.
+func (mr *MockStorageMockRecorder) UpdateArtist(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockStorage)(nil).Update), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateArtist", reflect.TypeOf((*MockStorage)(nil).UpdateArtist), arg0, arg1)
 }
@@ -5,12 +5,21 @@ import "time"
 
 // Song describes song data.
 type Song struct {
-	ID          string    `json:"id" example:"ca1da5fa-50ee-4d00-82e9-d6a578419ad7"`
-	Group       string    `json:"group" example:"Muse"`
-	Song        string    `json:"song" example:"Supermassive Black Hole"`
-	ReleaseDate time.Time `json:"release_date" format:"RFC3339" example:"2006-07-16T00:00:00Z"`
-	Text        string    `json:"text" example:"Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight"`
-	Link        string    `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	ID          string     `json:"id" example:"ca1da5fa-50ee-4d00-82e9-d6a578419ad7"`
+	Group       string     `json:"group" example:"Muse"`
+	Song        string     `json:"song" example:"Supermassive Black Hole"`
+	ReleaseDate time.Time  `json:"release_date" format:"RFC3339" example:"2006-07-16T00:00:00Z"`
+	Text        string     `json:"text" example:"Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight"`
+	Link        string     `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	Deleted     bool       `json:"deleted,omitempty" example:"false"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" format:"RFC3339" example:"2024-12-16T12:00:00Z"`
+	Version     int        `json:"version" example:"1"`
+	CreatedAt   time.Time  `json:"created_at" format:"RFC3339" example:"2024-12-24T12:00:00Z"`
+	UpdatedAt   time.Time  `json:"updated_at" format:"RFC3339" example:"2024-12-24T12:00:00Z"`
+	ArtistID    string     `json:"artist_id,omitempty" example:"5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"`
+	AlbumID     string     `json:"album_id,omitempty" example:"9c3f6f1e-8d2b-4b8a-a6f4-1b6c2d7e8f90"`
+	Track       int        `json:"track,omitempty" example:"3"`
+	Tags        []string   `json:"tags,omitempty" example:"rock,english"`
 }
 
 // ResponseDetailSong describes music info api response.
@@ -33,15 +42,106 @@ type RequestUpdateSong struct {
 	Link        string    `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
 }
 
+// RequestPatchSong describes song JSON merge patch request, nil fields
+// are kept unchanged.
+type RequestPatchSong struct {
+	Group       *string    `json:"group,omitempty" example:"Muse"`
+	Song        *string    `json:"song,omitempty" example:"Supermassive Black Hole"`
+	ReleaseDate *time.Time `json:"release_date,omitempty" format:"RFC3339" example:"2006-07-16T00:00:00Z"`
+	Text        *string    `json:"text,omitempty" example:"Ooh baby, don't you know I suffer?"`
+	Link        *string    `json:"link,omitempty" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	ArtistID    *string    `json:"-"` // resolved from group by storage if nil
+}
+
 // ResponseGetSongText describes song get text request.
 type ResponseGetSongText struct {
-	ID     string   `json:"id" example:"ca1da5fa-50ee-4d00-82e9-d6a578419ad7"`
-	Group  string   `json:"group" example:"Muse"`
-	Song   string   `json:"song" example:"Supermassive Black Hole"`
-	Verses []string `json:"verses,omitempty" example:"Ooh baby don't you know I suffer?\nOoh baby can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?,Ooh\nYou set my soul alight\nOoh\nYou set my soul alight"`
-	Total  int      `json:"total" example:"2"`
-	Page   int      `json:"page" example:"1"`
-	Size   int      `json:"size" example:"3"`
+	ID      string   `json:"id" example:"ca1da5fa-50ee-4d00-82e9-d6a578419ad7"`
+	Group   string   `json:"group" example:"Muse"`
+	Song    string   `json:"song" example:"Supermassive Black Hole"`
+	Verses  []string `json:"verses,omitempty" example:"Ooh baby don't you know I suffer?\nOoh baby can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?,Ooh\nYou set my soul alight\nOoh\nYou set my soul alight"`
+	Total   int      `json:"total" example:"2"`
+	Page    int      `json:"page" example:"1"`
+	Size    int      `json:"size" example:"3"`
+	Version int      `json:"version" example:"1"`
+}
+
+// Deleted songs listing modes.
+const (
+	// DeletedExclude hides deleted songs.
+	DeletedExclude = "exclude"
+	// DeletedInclude lists deleted songs with others.
+	DeletedInclude = "include"
+	// DeletedOnly lists deleted songs only.
+	DeletedOnly = "only"
+)
+
+// Songs list filter match modes.
+const (
+	// MatchExact matches equal value.
+	MatchExact = "exact"
+	// MatchPrefix matches value start.
+	MatchPrefix = "prefix"
+	// MatchContains matches value part.
+	MatchContains = "contains"
+	// MatchCI matches value part ignoring case.
+	MatchCI = "ci"
+	// MatchAny matches songs having any of tags.
+	MatchAny = "any"
+	// MatchAll matches songs having all tags.
+	MatchAll = "all"
+)
+
+// Match holds songs list filter match modes by field, empty mode is
+// exact for group and song, contains for text and any for tags.
+type Match struct {
+	Group string
+	Song  string
+	Text  string
+	Tags  string
+}
+
+// Songs list sort columns.
+const (
+	// SortGroup orders songs by group.
+	SortGroup = "group"
+	// SortSong orders songs by song name.
+	SortSong = "song"
+	// SortReleaseDate orders songs by release date.
+	SortReleaseDate = "release_date"
+	// SortCreated orders songs by creation time.
+	SortCreated = "created_at"
+	// SortUpdated orders songs by last change time.
+	SortUpdated = "updated_at"
+)
+
+// SortKey is songs list sort column and direction.
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// Cursor points to last song of songs list page, next page starts
+// right after it in sort order.
+type Cursor struct {
+	Sort string   `json:"s"`
+	Keys []string `json:"k"` // sort columns values of last song
+	ID   string   `json:"id"`
+}
+
+// RequestGetSongs describes songs get request.
+type RequestGetSongs struct {
+	Filter    Song
+	Match     Match
+	Deleted   string
+	Page      int
+	Size      int
+	CountOnly bool      // skips fetching songs
+	Cursor    *Cursor   // replaces page when set
+	Sort      []SortKey // ties are broken by id
+	// ReleasedFrom and ReleasedTo bound release date, from is inclusive
+	// and to is exclusive, zero bound is open.
+	ReleasedFrom time.Time
+	ReleasedTo   time.Time
 }
 
 // ResponseGetSongs describes songs get response.
@@ -49,4 +149,294 @@ type ResponseGetSongs struct {
 	Songs []Song `json:"songs"`
 	Page  int    `json:"page" example:"1"`
 	Size  int    `json:"size" example:"10"`
+	Total int    `json:"total" example:"42"`
+	Pages int    `json:"pages" example:"5"`
+	Next  string `json:"next,omitempty" example:"/api/songs?page=2&size=10"`
+	Prev  string `json:"prev,omitempty"`
+	// NextCursor is opaque cursor of next page, empty on last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Suggestion describes song similar to looked up name.
+type Suggestion struct {
+	ID    string  `json:"id" example:"0824f9fb-7397-4f19-95d5-f9ce8bec75de"`
+	Group string  `json:"group" example:"Muse"`
+	Song  string  `json:"song" example:"Supermassive Black Hole"`
+	Score float64 `json:"score" example:"0.88"`
+}
+
+// ResponseSuggest describes songs suggest response.
+type ResponseSuggest struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// ResponseNearDuplicates describes add song response rejected for
+// similar songs.
+type ResponseNearDuplicates struct {
+	NearDuplicates []Suggestion `json:"near_duplicates"`
+}
+
+// Lyrics search languages.
+const (
+	// LangEnglish is english search configuration.
+	LangEnglish = "english"
+	// LangRussian is russian search configuration.
+	LangRussian = "russian"
+)
+
+// RequestSearch describes lyrics search request.
+type RequestSearch struct {
+	Query string
+	Lang  string
+	Page  int
+	Size  int
+}
+
+// SearchHit describes song found by lyrics search.
+type SearchHit struct {
+	ID       string  `json:"id" example:"0824f9fb-7397-4f19-95d5-f9ce8bec75de"`
+	Group    string  `json:"group" example:"Muse"`
+	Song     string  `json:"song" example:"Supermassive Black Hole"`
+	Rank     float64 `json:"rank" example:"0.6079271"`
+	Headline string  `json:"headline" example:"Ooh <mark>baby</mark>, don't you know I suffer?"`
+}
+
+// ResponseSearch describes lyrics search response.
+type ResponseSearch struct {
+	Hits  []SearchHit `json:"hits"`
+	Query string      `json:"query" example:"\"soul alight\""`
+	Lang  string      `json:"lang" example:"english"`
+	Page  int         `json:"page" example:"1"`
+	Size  int         `json:"size" example:"10"`
+	Total int         `json:"total" example:"1"`
+}
+
+// Song change actions saved with revisions.
+const (
+	// ActionUpdate is song update.
+	ActionUpdate = "update"
+	// ActionDelete is song delete.
+	ActionDelete = "delete"
+	// ActionRestore is deleted song restore.
+	ActionRestore = "restore"
+)
+
+// Revision describes song state replaced by update, delete or restore.
+type Revision struct {
+	Rev         int       `json:"rev" example:"1"`
+	Group       string    `json:"group" example:"Muse"`
+	Song        string    `json:"song" example:"Supermassive Black Hole"`
+	ReleaseDate time.Time `json:"release_date" format:"RFC3339" example:"2006-07-16T00:00:00Z"`
+	Text        string    `json:"text" example:"Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?"`
+	Link        string    `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	Action      string    `json:"action" example:"update"`
+	CreatedAt   time.Time `json:"created_at" format:"RFC3339" example:"2024-12-20T12:00:00Z"`
+}
+
+// ResponseGetRevisions describes song revisions get response.
+type ResponseGetRevisions struct {
+	ID        string     `json:"id" example:"ca1da5fa-50ee-4d00-82e9-d6a578419ad7"`
+	Revisions []Revision `json:"revisions"`
+}
+
+// Diff line operations.
+const (
+	// DiffEqual is line present in both texts.
+	DiffEqual = "equal"
+	// DiffInsert is line present in new text only.
+	DiffInsert = "insert"
+	// DiffDelete is line present in old text only.
+	DiffDelete = "delete"
+)
+
+// DiffLine describes line of lyrics diff.
+type DiffLine struct {
+	Op   string `json:"op" enums:"equal,insert,delete" example:"insert"`
+	Text string `json:"text" example:"Ooh baby, can you hear me moan?"`
+}
+
+// ResponseGetDiff describes lyrics diff between two revisions, zero To
+// stands for current song.
+type ResponseGetDiff struct {
+	ID    string     `json:"id" example:"ca1da5fa-50ee-4d00-82e9-d6a578419ad7"`
+	From  int        `json:"from" example:"1"`
+	To    int        `json:"to" example:"2"`
+	Lines []DiffLine `json:"lines"`
+}
+
+// Artist describes artist referenced by songs, group of song is artist
+// name, aliases resolve to artist too.
+type Artist struct {
+	ID        string    `json:"id" example:"5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"`
+	Name      string    `json:"name" example:"The Beatles"`
+	Aliases   []string  `json:"aliases" example:"Beatles,the beatles"`
+	Country   string    `json:"country,omitempty" example:"GB"`
+	Formed    int       `json:"formed_year,omitempty" example:"1960"`
+	CreatedAt time.Time `json:"created_at" format:"RFC3339" example:"2025-01-04T12:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" format:"RFC3339" example:"2025-01-04T12:00:00Z"`
+}
+
+// RequestArtist describes artist add and update request.
+type RequestArtist struct {
+	Name    string   `json:"name" example:"The Beatles"`
+	Aliases []string `json:"aliases" example:"Beatles,the beatles"`
+	Country string   `json:"country" example:"GB"`
+	Formed  int      `json:"formed_year" example:"1960"`
+}
+
+// ResponseGetArtists describes artists get response.
+type ResponseGetArtists struct {
+	Artists []Artist `json:"artists"`
+	Page    int      `json:"page" example:"1"`
+	Size    int      `json:"size" example:"10"`
+	Total   int      `json:"total" example:"42"`
+	Pages   int      `json:"pages" example:"5"`
+}
+
+// Album describes artist album, album songs are ordered by track number.
+type Album struct {
+	ID          string     `json:"id" example:"9c3f6f1e-8d2b-4b8a-a6f4-1b6c2d7e8f90"`
+	Title       string     `json:"title" example:"Black Holes and Revelations"`
+	ArtistID    string     `json:"artist_id" example:"5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"`
+	Artist      string     `json:"artist" example:"Muse"`
+	ReleaseDate *time.Time `json:"release_date,omitempty" format:"RFC3339" example:"2006-07-03T00:00:00Z"`
+	CoverLink   string     `json:"cover_link,omitempty" example:"https://example.com/covers/bhar.jpg"`
+	CreatedAt   time.Time  `json:"created_at" format:"RFC3339" example:"2025-01-06T12:00:00Z"`
+	UpdatedAt   time.Time  `json:"updated_at" format:"RFC3339" example:"2025-01-06T12:00:00Z"`
+}
+
+// RequestAddAlbum describes album add request, artist is name or alias.
+type RequestAddAlbum struct {
+	Title       string     `json:"title" example:"Black Holes and Revelations"`
+	Artist      string     `json:"artist" example:"Muse"`
+	ReleaseDate *time.Time `json:"release_date,omitempty" format:"RFC3339" example:"2006-07-03T00:00:00Z"`
+	CoverLink   string     `json:"cover_link" example:"https://example.com/covers/bhar.jpg"`
+}
+
+// ResponseGetAlbums describes albums get response.
+type ResponseGetAlbums struct {
+	Albums []Album `json:"albums"`
+	Page   int     `json:"page" example:"1"`
+	Size   int     `json:"size" example:"10"`
+	Total  int     `json:"total" example:"42"`
+	Pages  int     `json:"pages" example:"5"`
+}
+
+// RequestAttachTrack describes request placing song on album position.
+type RequestAttachTrack struct {
+	SongID string `json:"song_id" example:"0824f9fb-7397-4f19-95d5-f9ce8bec75de"`
+	Track  int    `json:"track" example:"3"`
+}
+
+// ResponseGetTracks describes album tracks get response.
+type ResponseGetTracks struct {
+	Album  Album  `json:"album"`
+	Tracks []Song `json:"tracks"`
+}
+
+// Tag kinds.
+const (
+	// TagGenre is song genre.
+	TagGenre = "genre"
+	// TagMood is song mood.
+	TagMood = "mood"
+	// TagLanguage is song lyrics language.
+	TagLanguage = "language"
+	// TagCustom is free-form tag.
+	TagCustom = "custom"
+)
+
+// Tag describes song tag with number of not deleted songs tagged.
+type Tag struct {
+	Name  string `json:"name" example:"rock"`
+	Kind  string `json:"kind" example:"genre"`
+	Songs int    `json:"songs" example:"42"`
+}
+
+// RequestSongTags describes song tags add or remove request, kind is
+// set on tags created by add request.
+type RequestSongTags struct {
+	Tags []string `json:"tags" example:"rock,english"`
+	Kind string   `json:"kind,omitempty" example:"genre" enums:"genre,mood,language,custom"`
+}
+
+// ResponseGetTags describes tags get response.
+type ResponseGetTags struct {
+	Tags []Tag `json:"tags"`
+}
+
+// Playlist describes user curated songs list.
+type Playlist struct {
+	ID        string    `json:"id" example:"3f2b9c1d-6a4e-4c7b-8e2f-5d1a9b0c7e64"`
+	Name      string    `json:"name" example:"Road trip"`
+	CreatedAt time.Time `json:"created_at" format:"RFC3339" example:"2025-01-10T12:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" format:"RFC3339" example:"2025-01-10T12:00:00Z"`
+}
+
+// RequestPlaylist describes playlist create or rename request.
+type RequestPlaylist struct {
+	Name string `json:"name" example:"Road trip"`
+}
+
+// ResponseGetPlaylists describes playlists get response.
+type ResponseGetPlaylists struct {
+	Playlists []Playlist `json:"playlists"`
+	Page      int        `json:"page" example:"1"`
+	Size      int        `json:"size" example:"10"`
+	Total     int        `json:"total" example:"42"`
+	Pages     int        `json:"pages" example:"5"`
+}
+
+// RequestPlaylistSong describes playlist song add request, position is
+// one based among listed songs, zero position appends song.
+type RequestPlaylistSong struct {
+	SongID   string `json:"song_id" example:"0824f9fb-7397-4f19-95d5-f9ce8bec75de"`
+	Position int    `json:"position,omitempty" example:"2"`
+}
+
+// RequestMovePlaylistSong describes playlist song move request, position
+// is one based among listed songs.
+type RequestMovePlaylistSong struct {
+	Position int `json:"position" example:"1"`
+}
+
+// ResponseGetPlaylist describes playlist get response with songs page in
+// playlist order, deleted songs are not listed.
+type ResponseGetPlaylist struct {
+	Playlist Playlist `json:"playlist"`
+	Songs    []Song   `json:"songs"`
+	Page     int      `json:"page" example:"1"`
+	Size     int      `json:"size" example:"10"`
+	Total    int      `json:"total" example:"42"`
+	Pages    int      `json:"pages" example:"5"`
+}
+
+// APIKey describes API key of caller, key itself is not kept.
+type APIKey struct {
+	ID        string    `json:"id" example:"9c4e1f2a-7b3d-4e8f-a1c6-2d5b8e0f3a71"`
+	Name      string    `json:"name" example:"ci"`
+	Role      string    `json:"role" example:"editor"`
+	Hash      string    `json:"-"`
+	CreatedAt time.Time `json:"created_at" format:"RFC3339" example:"2025-01-12T12:00:00Z"`
+}
+
+// ResponseAddAPIKey describes API key add response, key is shown once.
+type ResponseAddAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"3q2-7wB0mQyJ9Xk1sVtN4cLpZ8rF6hGdE5aU0iOeYbM"`
+}
+
+// Caller describes authenticated request caller.
+type Caller struct {
+	ID   string
+	Name string
+	Role string
+}
+
+// ResponseForbidden describes response to caller lacking role required
+// by route.
+type ResponseForbidden struct {
+	Error        string `json:"error" example:"forbidden"`
+	Role         string `json:"role" example:"reader"`
+	RequiredRole string `json:"required_role" example:"editor"`
 }
@@ -0,0 +1,135 @@
+// Package ratelimit limits request rates of clients with token buckets.
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is interval of dropping idle full buckets.
+const sweepInterval = time.Minute
+
+// bucket holds client tokens as of last update.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Result describes client bucket state after request.
+type Result struct {
+	// Allowed reports whether request took token.
+	Allowed bool
+	// Limit is bucket capacity.
+	Limit int
+	// Remaining is number of whole tokens left.
+	Remaining int
+	// Reset is time until bucket is full.
+	Reset time.Duration
+	// RetryAfter is time until next token of denied request.
+	RetryAfter time.Duration
+}
+
+// Limiter keeps token bucket per client, bucket holding count tokens is
+// refilled evenly over period.
+type Limiter struct {
+	count  int
+	period time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// New creates Limiter allowing count requests per period with bursts of
+// count requests.
+func New(count int, period time.Duration) *Limiter {
+	return &Limiter{count: count, period: period, now: time.Now, buckets: make(map[string]*bucket)}
+}
+
+// rate returns tokens refilled per second.
+func (l *Limiter) rate() float64 { return float64(l.count) / l.period.Seconds() }
+
+// Allow takes token of client bucket if there is one.
+func (l *Limiter) Allow(client string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.Sub(l.swept) >= sweepInterval {
+		l.sweep(now)
+	}
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: float64(l.count), updated: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(float64(l.count), b.tokens+now.Sub(b.updated).Seconds()*l.rate())
+	b.updated = now
+	res := Result{Limit: l.count}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = l.wait(1 - b.tokens)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = l.wait(float64(l.count) - b.tokens)
+	return res
+}
+
+// wait returns time of refilling tokens.
+func (l *Limiter) wait(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.rate() * float64(time.Second)))
+}
+
+// sweep drops buckets refilled completely since last update.
+func (l *Limiter) sweep(now time.Time) {
+	for k, b := range l.buckets {
+		if now.Sub(b.updated) >= l.wait(float64(l.count)-b.tokens) {
+			delete(l.buckets, k)
+		}
+	}
+	l.swept = now
+}
+
+// ClientIP returns request client address, X-Forwarded-For addresses are
+// trusted only when sent by trusted proxies and read from right to left
+// up to first untrusted address.
+func ClientIP(r *http.Request, trusted []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isTrusted(addr, trusted) {
+		return host
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !isTrusted(addr, trusted) {
+			break
+		}
+	}
+	return addr.String()
+}
+
+// isTrusted reports whether address belongs to trusted prefixes.
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2025, 1, 16, 12, 0, 0, 0, time.UTC)
+	l := New(3, 3*time.Second)
+	l.now = func() time.Time { return now }
+	for i := 2; i >= 0; i-- {
+		res := l.Allow("a")
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 3, res.Limit)
+		assert.Equal(t, i, res.Remaining)
+		assert.Equal(t, time.Duration(3-i)*time.Second, res.Reset)
+	}
+	res := l.Allow("a")
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.True(t, l.Allow("b").Allowed, "clients have own buckets")
+
+	now = now.Add(1500 * time.Millisecond)
+	res = l.Allow("a")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	res = l.Allow("a")
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, 2, l.Allow("a").Remaining, "bucket is not refilled over capacity")
+	assert.Len(t, l.buckets, 1, "idle full buckets are dropped")
+}
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+	tests := []struct {
+		name   string
+		remote string
+		xff    []string
+		want   string
+	}{
+		{name: "positive test #1", remote: "203.0.113.7:1234", want: "203.0.113.7"},
+		{name: "positive test #2", remote: "203.0.113.7:1234", xff: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "positive test #3", remote: "10.0.0.2:1234", xff: []string{"198.51.100.1, 10.0.0.3"}, want: "198.51.100.1"},
+		{
+			name:   "positive test #4",
+			remote: "10.0.0.2:1234",
+			xff:    []string{"192.0.2.9, 198.51.100.1", "10.0.0.3"},
+			want:   "198.51.100.1",
+		},
+		{name: "positive test #5", remote: "[::1]:1234", xff: []string{"2001:db8::1"}, want: "2001:db8::1"},
+		{name: "positive test #6", remote: "10.0.0.2:1234", want: "10.0.0.2"},
+		{name: "negative test #1", remote: "10.0.0.2:1234", xff: []string{"bad, 10.0.0.3"}, want: "10.0.0.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/songs", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			assert.Equal(t, tt.want, ClientIP(r, trusted))
+		})
+	}
+}
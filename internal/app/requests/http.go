@@ -5,14 +5,22 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/xEgorka/project4/internal/app/config"
 	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/metrics"
 	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/tracing"
 )
 
 // HTTP provides methods for http requests.
@@ -26,10 +34,13 @@ func newClient() *http.Client { return &http.Client{} }
 // New creates HTTP.
 func New(config *config.Config) *HTTP { return &HTTP{cfg: config, c: newClient()} }
 
-// GetSongDetail requests song details.
+// GetSongDetail requests song details in client span, trace context is
+// passed to music info API in traceparent header.
 func (h *HTTP) GetSongDetail(ctx context.Context, d models.RequestAddSong) (
 	models.ResponseDetailSong, error) {
 	var s models.ResponseDetailSong
+	ctx, span := tracing.Start(ctx, "GET /info", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
 
 	url := h.cfg.MusicInfoURL + "/info"
 	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -41,11 +52,23 @@ func (h *HTTP) GetSongDetail(ctx context.Context, d models.RequestAddSong) (
 	q.Add("group", d.Group)
 	q.Add("song", d.Song)
 	r.URL.RawQuery = q.Encode()
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+	span.SetAttributes(semconv.HTTPRequestMethodKey.String(http.MethodGet), semconv.URLFull(r.URL.String()))
 
+	start, code := time.Now(), 0 // zero code records failed request
+	defer func() {
+		metrics.ObserveMusicInfo(code, time.Since(start))
+		if code != http.StatusOK {
+			span.SetStatus(otelcodes.Error, "music info request failed")
+		}
+	}()
 	res, ee := h.c.Do(r)
 	if ee != nil {
+		span.RecordError(ee)
 		return s, ee
 	}
+	code = res.StatusCode
+	span.SetAttributes(semconv.HTTPResponseStatusCode(code))
 	defer func() {
 		if err := res.Body.Close(); err != nil {
 			logger.Log.Info("failed body close", zap.Error(err))
@@ -56,6 +79,8 @@ func (h *HTTP) GetSongDetail(ctx context.Context, d models.RequestAddSong) (
 	switch {
 	case res.StatusCode == http.StatusOK:
 		if e = json.NewDecoder(res.Body).Decode(&s); e != nil {
+			span.RecordError(e)
+			span.SetStatus(otelcodes.Error, e.Error())
 			return s, e
 		}
 		return s, nil
@@ -5,10 +5,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/metrics"
 	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/tracing"
 )
 
 func TestHTTP_GetSongDetail(t *testing.T) {
@@ -91,3 +97,41 @@ func TestHTTP_GetSongDetail(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTP_GetSongDetail_metrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close() // refused request
+	before := testutil.ToFloat64(metrics.MusicInfoErrors)
+	_, err := New(&config.Config{MusicInfoURL: srv.URL}).GetSongDetail(context.Background(),
+		models.RequestAddSong{Group: "Muse", Song: "Uprising"})
+	if err == nil {
+		t.Fatalf("error was expected")
+	}
+	if got := testutil.ToFloat64(metrics.MusicInfoErrors); got != before+1 {
+		t.Errorf("music info errors = %v, want %v", got, before+1)
+	}
+}
+
+func TestHTTP_GetSongDetail_traceparent(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), ""); err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("traceparent")
+		if err := json.NewEncoder(w).Encode(&models.ResponseDetailSong{ReleaseDate: "16.07.2006"}); err != nil {
+			panic(err)
+		}
+	}))
+	defer srv.Close()
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: trace.SpanID{1}, TraceFlags: trace.FlagsSampled, Remote: true}))
+	if _, err := New(&config.Config{MusicInfoURL: srv.URL}).GetSongDetail(ctx,
+		models.RequestAddSong{Group: "Muse", Song: "Uprising"}); err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	if !strings.Contains(got, traceID.String()) {
+		t.Errorf("traceparent = %q, want trace id %s", got, traceID)
+	}
+}
@@ -0,0 +1,39 @@
+// Package roles ranks caller roles granting access to API routes.
+package roles
+
+import "slices"
+
+// Caller roles from least to most privileged.
+const (
+	// Reader may list songs and read song lyrics.
+	Reader = "reader"
+	// Editor may also read and change library.
+	Editor = "editor"
+	// Admin may also delete and purge.
+	Admin = "admin"
+)
+
+// ranked lists roles by privilege.
+var ranked = []string{Reader, Editor, Admin}
+
+// Valid reports whether role is known.
+func Valid(role string) bool { return slices.Contains(ranked, role) }
+
+// Allows reports whether role grants access required by other role,
+// unknown role grants nothing.
+func Allows(role, required string) bool {
+	i, j := slices.Index(ranked, role), slices.Index(ranked, required)
+	return i >= 0 && j >= 0 && i >= j
+}
+
+// Highest returns most privileged known role of roles, empty if none is
+// known.
+func Highest(rr ...string) string {
+	res := ""
+	for _, r := range rr {
+		if Valid(r) && (res == "" || Allows(r, res)) {
+			res = r
+		}
+	}
+	return res
+}
@@ -0,0 +1,68 @@
+package roles
+
+import "testing"
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		role string
+		want bool
+	}{
+		{name: "positive test #1", role: Reader, want: true},
+		{name: "positive test #2", role: Admin, want: true},
+		{name: "negative test #1", role: "", want: false},
+		{name: "negative test #2", role: "Admin", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.role); got != tt.want {
+				t.Errorf("Valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		required string
+		want     bool
+	}{
+		{name: "positive test #1", role: Reader, required: Reader, want: true},
+		{name: "positive test #2", role: Editor, required: Reader, want: true},
+		{name: "positive test #3", role: Admin, required: Editor, want: true},
+		{name: "negative test #1", role: Reader, required: Editor, want: false},
+		{name: "negative test #2", role: Editor, required: Admin, want: false},
+		{name: "negative test #3", role: "", required: Reader, want: false},
+		{name: "negative test #4", role: Admin, required: "root", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allows(tt.role, tt.required); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighest(t *testing.T) {
+	tests := []struct {
+		name string
+		rr   []string
+		want string
+	}{
+		{name: "positive test #1", rr: []string{Reader}, want: Reader},
+		{name: "positive test #2", rr: []string{"root", Admin, Reader}, want: Admin},
+		{name: "positive test #3", rr: []string{Editor, "guest"}, want: Editor},
+		{name: "negative test #1", rr: []string{"root"}, want: ""},
+		{name: "negative test #2", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highest(tt.rr...); got != tt.want {
+				t.Errorf("Highest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
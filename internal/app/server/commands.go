@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/xEgorka/project4/internal/app/roles"
+	"github.com/xEgorka/project4/internal/app/service"
+)
+
+// errUsage is returned for unknown command or bad command arguments.
+var errUsage = errors.New(`usage: apikey add NAME [reader|editor|admin] | apikey list | apikey revoke ID`)
+
+// command runs API key management command instead of server, added key
+// is printed once, key role is reader unless set.
+func command(ctx context.Context, sv *service.Service, args []string, out io.Writer) error {
+	if len(args) < 2 || args[0] != "apikey" {
+		return errUsage
+	}
+	switch {
+	case args[1] == "add" && (len(args) == 3 || len(args) == 4):
+		role := roles.Reader
+		if len(args) == 4 {
+			role = args[3]
+		}
+		d, err := sv.AddAPIKey(ctx, args[2], role)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\t%s\n", d.ID, d.Key)
+		return err
+	case args[1] == "list" && len(args) == 2:
+		dd, err := sv.GetAPIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		for _, d := range dd {
+			if _, err := fmt.Fprintf(out, "%s\t%s\t%s\t%s\n",
+				d.ID, d.Name, d.Role, d.CreatedAt.Format(time.RFC3339)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case args[1] == "revoke" && len(args) == 3:
+		return sv.DeleteAPIKey(ctx, args[2])
+	}
+	return errUsage
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/roles"
+	"github.com/xEgorka/project4/internal/app/service"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func Test_command(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{DBDriver: config.DriverMemory}
+	st, err := storage.Open(ctx, cfg)
+	require.NoError(t, err)
+	sv := service.New(cfg, st, requests.New(cfg))
+
+	var out bytes.Buffer
+	require.NoError(t, command(ctx, sv, []string{"apikey", "add", "ci", "editor"}, &out))
+	added := strings.Fields(out.String())
+	require.Len(t, added, 2)
+	c, err := sv.Authenticate(ctx, added[1])
+	require.NoError(t, err)
+	assert.Equal(t, "ci", c.Name)
+	assert.Equal(t, roles.Editor, c.Role)
+	require.Error(t, command(ctx, sv, []string{"apikey", "add", "ci", "root"}, &out))
+
+	out.Reset()
+	require.NoError(t, command(ctx, sv, []string{"apikey", "list"}, &out))
+	assert.True(t, strings.HasPrefix(out.String(), added[0]+"\tci\teditor\t"))
+	assert.NotContains(t, out.String(), added[1])
+
+	require.NoError(t, command(ctx, sv, []string{"apikey", "revoke", added[0]}, &out))
+	assert.Error(t, command(ctx, sv, []string{"apikey", "revoke", added[0]}, &out))
+	_, err = sv.Authenticate(ctx, added[1])
+	assert.Error(t, err)
+
+	for _, args := range [][]string{{"apikey"}, {"apikey", "add"}, {"song", "list"}, {"apikey", "list", "all"}} {
+		assert.ErrorIs(t, command(ctx, sv, args, &out), errUsage)
+	}
+}
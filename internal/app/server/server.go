@@ -24,9 +24,12 @@ import (
 	"github.com/xEgorka/project4/internal/app/config"
 	"github.com/xEgorka/project4/internal/app/handlers"
 	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/metrics"
 	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/roles"
 	"github.com/xEgorka/project4/internal/app/service"
 	"github.com/xEgorka/project4/internal/app/storage"
+	"github.com/xEgorka/project4/internal/app/tracing"
 )
 
 var (
@@ -55,19 +58,46 @@ func Start() error {
 	if err != nil {
 		return err
 	}
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	s, err := storage.Open(ctx, cfg)
 	if err != nil {
 		return err
 	}
-	srv := http.Server{
-		Addr:    cfg.URI,
-		Handler: routes(handlers.NewHTTP(service.New(cfg, s, requests.New(cfg)))),
+	sv := service.New(cfg, storage.Instrument(s), requests.New(cfg))
+	if len(cfg.Args) > 0 {
+		return command(ctx, sv, cfg.Args, os.Stdout)
+	}
+	shutdown, err := tracing.Setup(ctx, cfg.TraceExporter)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			logger.Log.Error("failed flush trace spans", zap.Error(err))
+		}
+	}()
+	r := routes(handlers.NewHTTP(sv), handlers.NewRateLimit(cfg), len(cfg.MetricsURI) == 0)
+	srv := http.Server{Addr: cfg.URI, Handler: r}
+	servers := []*http.Server{&srv}
+	if len(cfg.MetricsURI) > 0 {
+		admin := &http.Server{Addr: cfg.MetricsURI, Handler: adminRoutes()}
+		servers = append(servers, admin)
+		go func() {
+			logger.Log.Info("running admin http server...", zap.String("uri", cfg.MetricsURI))
+			if err := admin.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Log.Error("failed run admin http server", zap.Error(err))
+			}
+		}()
 	}
+	go sv.Retain(ctx, retentionInterval)
 
 	go func() {
 		logger.Log.Info("running http server...", zap.String("uri", cfg.URI))
 		logger.Log.Info("music info api", zap.String("url", cfg.MusicInfoURL))
+		logger.Log.Info("trace exporter", zap.String("exporter", cfg.TraceExporter))
 		logger.Log.Info("swagger address", zap.String("url", cfg.URI+"/swagger/index.html#/"))
 		if err := srv.ListenAndServe(); err != nil {
 			if errors.Is(err, http.ErrServerClosed) {
@@ -77,14 +107,17 @@ func Start() error {
 			}
 		}
 	}()
-	return stop(&srv)
+	return stop(servers...)
 }
 
 var sigint = make(chan os.Signal, 1)
 
-const timeout = 5 * time.Second
+const (
+	timeout           = 5 * time.Second
+	retentionInterval = time.Hour
+)
 
-func stop(srv *http.Server) error {
+func stop(servers ...*http.Server) error {
 	signal.Notify(sigint, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 	sig := <-sigint
 	logger.Log.Info("signal received", zap.String("sig", sig.String()))
@@ -92,13 +125,22 @@ func stop(srv *http.Server) error {
 	logger.Log.Info("server stopping...")
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Log.Error("failed server stop", zap.Error(err))
-		return err
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Log.Error("failed server stop", zap.Error(err))
+			return err
+		}
 	}
 	return nil
 }
 
+// adminRoutes serves metrics on admin listener kept apart from API.
+func adminRoutes() *chi.Mux {
+	r := chi.NewRouter()
+	r.Handle("/metrics", metrics.Handler())
+	return r
+}
+
 // @Title Online Song Library API
 // @Description Online Song Library.
 // @Version 0.1
@@ -110,16 +152,83 @@ func stop(srv *http.Server) error {
 
 // @Tag.name Songs
 // @Tag.description "Songs requests group."
-func routes(h handlers.HTTP) *chi.Mux {
+// @Tag.name Revisions
+// @Tag.description "Song revisions requests group."
+// @Tag.name Search
+// @Tag.description "Lyrics search requests group."
+// @Tag.name Artists
+// @Tag.description "Artists requests group."
+// @Tag.name Albums
+// @Tag.description "Albums requests group."
+// @Tag.name Tags
+// @Tag.description "Song tags requests group."
+// @Tag.name Playlists
+// @Tag.description "Playlists requests group."
+
+// @SecurityDefinitions.apikey ApiKeyAuth
+// @In header
+// @Name X-API-Key
+// @Description API key of reader, editor or admin role, calls not allowed for role get 403.
+
+// @SecurityDefinitions.apikey BearerAuth
+// @In header
+// @Name Authorization
+// @Description "Bearer" followed by JWT verified by configured JWKS, role claim grants reader, editor or admin role.
+func routes(h handlers.HTTP, l *handlers.RateLimit, apiMetrics bool) *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(handlers.WithLogging)
+	r.Use(handlers.WithTracing)
 
 	r.Get("/api/ping", h.GetPing)
-	r.Post("/api/song", h.PostSong)
-	r.Put("/api/song/{id}", h.PutSong)
-	r.Delete("/api/song/{id}", h.DeleteSong)
-	r.Get("/api/song/{id}/text", h.GetSongText)
-	r.Get("/api/songs", h.GetSongs)
+	r.Group(func(r chi.Router) {
+		// limit addresses before auth so unauthenticated floods are limited
+		// too, then callers whatever address they come from
+		r.Use(l.WithRateLimit)
+		r.Use(h.WithAuth)
+		r.Use(l.WithCallerRateLimit)
+		// readers list and read songs and lyrics, editors read and change the
+		// rest, only admins delete and purge
+		reader, editor, admin :=
+			handlers.WithRole(roles.Reader), handlers.WithRole(roles.Editor), handlers.WithRole(roles.Admin)
+		r.With(editor).Post("/api/song", h.PostSong)
+		r.With(reader).Get("/api/song/{id}", h.GetSong)
+		r.With(editor).Put("/api/song/{id}", h.PutSong)
+		r.With(editor).Patch("/api/song/{id}", h.PatchSong)
+		r.With(admin).Delete("/api/song/{id}", h.DeleteSong)
+		r.With(editor).Post("/api/song/{id}/restore", h.RestoreSong)
+		r.With(reader).Get("/api/song/{id}/text", h.GetSongText)
+		r.With(editor).Post("/api/song/{id}/tags", h.PostSongTags)
+		r.With(admin).Delete("/api/song/{id}/tags", h.DeleteSongTags)
+		r.With(editor).Get("/api/song/{id}/revisions", h.GetRevisions)
+		r.With(editor).Get("/api/song/{id}/revisions/{rev}", h.GetRevision)
+		r.With(editor).Get("/api/song/{id}/revisions/{rev}/diff", h.GetRevisionDiff)
+		r.With(editor).Post("/api/song/{id}/revisions/{rev}/revert", h.RevertRevision)
+		r.With(reader).Get("/api/songs", h.GetSongs)
+		r.With(editor).Get("/api/songs/suggest", h.Suggest)
+		r.With(editor).Get("/api/search", h.Search)
+		r.With(editor).Post("/api/artists", h.PostArtist)
+		r.With(editor).Get("/api/artists", h.GetArtists)
+		r.With(editor).Get("/api/artists/{id}", h.GetArtist)
+		r.With(editor).Put("/api/artists/{id}", h.PutArtist)
+		r.With(admin).Delete("/api/artists/{id}", h.DeleteArtist)
+		r.With(editor).Post("/api/albums", h.PostAlbum)
+		r.With(editor).Get("/api/albums", h.GetAlbums)
+		r.With(editor).Get("/api/albums/{id}", h.GetAlbum)
+		r.With(editor).Get("/api/albums/{id}/tracks", h.GetAlbumTracks)
+		r.With(editor).Post("/api/albums/{id}/tracks", h.PostAlbumTrack)
+		r.With(editor).Get("/api/tags", h.GetTags)
+		r.With(editor).Post("/api/playlists", h.PostPlaylist)
+		r.With(editor).Get("/api/playlists", h.GetPlaylists)
+		r.With(editor).Get("/api/playlists/{id}", h.GetPlaylist)
+		r.With(editor).Put("/api/playlists/{id}", h.PutPlaylist)
+		r.With(admin).Delete("/api/playlists/{id}", h.DeletePlaylist)
+		r.With(editor).Post("/api/playlists/{id}/songs", h.PostPlaylistSong)
+		r.With(editor).Put("/api/playlists/{id}/songs/{song_id}", h.PutPlaylistSong)
+		r.With(admin).Delete("/api/playlists/{id}/songs/{song_id}", h.DeletePlaylistSong)
+		if apiMetrics { // served to admins unless admin listener is set
+			r.With(admin).Handle("/metrics", metrics.Handler())
+		}
+	})
 
 	r.Get("/swagger/*",
 		httpSwagger.Handler(httpSwagger.URL("/swagger/doc.json")))
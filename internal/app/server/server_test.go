@@ -2,19 +2,33 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/xEgorka/project4/internal/app/config"
 	"github.com/xEgorka/project4/internal/app/handlers"
 	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
 	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/roles"
 	"github.com/xEgorka/project4/internal/app/service"
+	"github.com/xEgorka/project4/internal/app/storage"
+	"github.com/xEgorka/project4/internal/app/tracing"
 )
 
 func TestStart(t *testing.T) {
@@ -83,10 +97,397 @@ func Test_routes(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := routes(h)
+			got := routes(h, handlers.NewRateLimit(cfg), false)
 			if reflect.TypeOf(got) == reflect.TypeOf((*chi.Mux)(nil)).Elem() {
 				t.Errorf("not chi mux")
 			}
 		})
 	}
 }
+
+func Test_routes_rateLimit(t *testing.T) {
+	cfg := &config.Config{DBDriver: config.DriverMemory,
+		RateLimitRead: config.RateLimit{Count: 2, Period: time.Minute}}
+	st, err := storage.Open(context.Background(), cfg)
+	require.NoError(t, err)
+	srv := httptest.NewServer(routes(handlers.NewHTTP(service.New(cfg, st, requests.New(cfg))),
+		handlers.NewRateLimit(cfg), false))
+	defer srv.Close()
+
+	// fake keys sent from one address share its bucket
+	var codes []int
+	for _, key := range []string{"fake-1", "fake-2", "fake-3"} {
+		r, err := http.NewRequest(http.MethodGet, srv.URL+"/api/songs", nil)
+		require.NoError(t, err)
+		r.Header.Set(handlers.APIKeyHeader, key)
+		res, err := srv.Client().Do(r)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		codes = append(codes, res.StatusCode)
+	}
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+}
+
+func Test_routes_metrics(t *testing.T) {
+	cfg := &config.Config{DBDriver: config.DriverMemory,
+		RateLimitRead: config.RateLimit{Count: 3, Period: time.Minute}}
+	st, err := storage.Open(context.Background(), cfg)
+	require.NoError(t, err)
+	sv := service.New(cfg, st, requests.New(cfg))
+	admin, err := sv.AddAPIKey(context.Background(), "admin", roles.Admin)
+	require.NoError(t, err)
+	editor, err := sv.AddAPIKey(context.Background(), "editor", roles.Editor)
+	require.NoError(t, err)
+	r := routes(handlers.NewHTTP(sv), handlers.NewRateLimit(cfg), true)
+	tests := []struct {
+		name string
+		key  string
+		code int
+	}{
+		{name: "positive test #1", key: admin.Key, code: http.StatusOK},
+		{name: "negative test #1", code: http.StatusUnauthorized},
+		{name: "negative test #2", key: editor.Key, code: http.StatusForbidden},
+		{name: "negative test #3", key: admin.Key, code: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if len(tt.key) > 0 {
+				req.Header.Set(handlers.APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	routes(handlers.NewHTTP(sv), handlers.NewRateLimit(cfg), false).ServeHTTP(rec,
+		httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code) // served by admin listener
+}
+
+func Test_routes_memory(t *testing.T) {
+	info := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			d := models.ResponseDetailSong{
+				ReleaseDate: "16.07.2006",
+				Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nOoh\nYou set my soul alight",
+				Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw"}
+			if err := json.NewEncoder(w).Encode(&d); err != nil {
+				panic(err)
+			}
+		}))
+	defer info.Close()
+	cfg := &config.Config{DBDriver: config.DriverMemory, MusicInfoURL: info.URL}
+	st, err := storage.Open(context.Background(), cfg)
+	require.NoError(t, err)
+	sv := service.New(cfg, storage.Instrument(st), requests.New(cfg))
+	key, err := sv.AddAPIKey(context.Background(), "test", roles.Admin)
+	require.NoError(t, err)
+	reader, err := sv.AddAPIKey(context.Background(), "reader", roles.Reader)
+	require.NoError(t, err)
+	editor, err := sv.AddAPIKey(context.Background(), "editor", roles.Editor)
+	require.NoError(t, err)
+	srv := httptest.NewServer(routes(handlers.NewHTTP(sv), handlers.NewRateLimit(cfg), false))
+	defer srv.Close()
+
+	doWith := func(method, path, body string, header http.Header) *http.Response {
+		r, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		r.Header.Set(handlers.APIKeyHeader, key.Key)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		res, err := srv.Client().Do(r)
+		require.NoError(t, err)
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+	do := func(method, path, body string) *http.Response { return doWith(method, path, body, nil) }
+
+	assert.Equal(t, http.StatusUnauthorized, doWith(http.MethodGet, "/api/songs", "",
+		http.Header{handlers.APIKeyHeader: {"bad"}}).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, doWith(http.MethodDelete, "/api/song/1", "",
+		http.Header{handlers.APIKeyHeader: {""}}).StatusCode)
+	asReader := http.Header{handlers.APIKeyHeader: {reader.Key}}
+	assert.Equal(t, http.StatusOK, doWith(http.MethodGet, "/api/songs", "", asReader).StatusCode)
+	assert.Equal(t, http.StatusNoContent, doWith(http.MethodGet, "/api/song/1", "", asReader).StatusCode)
+	res := doWith(http.MethodPost, "/api/song", `{"group": "Muse","song": "Uprising"}`, asReader)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	var forbidden models.ResponseForbidden
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&forbidden))
+	assert.Equal(t, models.ResponseForbidden{Error: "forbidden", Role: roles.Reader, RequiredRole: roles.Editor},
+		forbidden)
+	assert.Equal(t, http.StatusForbidden, doWith(http.MethodGet, "/api/artists", "", asReader).StatusCode)
+	assert.Equal(t, http.StatusForbidden, doWith(http.MethodDelete, "/api/song/1?purge=true", "",
+		http.Header{handlers.APIKeyHeader: {editor.Key}}).StatusCode)
+	res, err = srv.Client().Get(srv.URL + "/api/ping")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = do(http.MethodPost, "/api/song", `{"group": "Muse","song": "Supermassive Black Hole"}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var song models.Song
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&song))
+	assert.Equal(t, "/api/song/"+song.ID, res.Header.Get("Location"))
+	res = do(http.MethodGet, res.Header.Get("Location"), "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var got models.Song
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+	assert.Equal(t, song, got)
+	assert.Equal(t, http.StatusConflict,
+		do(http.MethodPost, "/api/song", `{"group": "Muse","song": "Supermassive Black Hole"}`).StatusCode)
+
+	res = do(http.MethodGet, "/api/songs?group=Muse", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var songs models.ResponseGetSongs
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&songs))
+	require.Len(t, songs.Songs, 1)
+	assert.Equal(t, song.ID, songs.Songs[0].ID)
+
+	res = do(http.MethodGet, "/api/song/"+song.ID+"/text?size=1", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var text models.ResponseGetSongText
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&text))
+	assert.Equal(t, 2, text.Total)
+	assert.Len(t, text.Verses, 1)
+
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPut, "/api/song/"+song.ID,
+		`{"release_date": "2006-06-19T00:00:00Z","text": "Ooh","link": "https://example.com"}`).StatusCode)
+	res = do(http.MethodGet, "/api/songs?id="+song.ID, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	tag := res.Header.Get("ETag")
+	assert.Equal(t, `"2"`, tag)
+	assert.Equal(t, http.StatusNotModified, doWith(http.MethodGet, "/api/song/"+song.ID+"/text", "",
+		http.Header{"If-None-Match": {tag}}).StatusCode)
+	assert.Equal(t, http.StatusPreconditionFailed, doWith(http.MethodPatch, "/api/song/"+song.ID,
+		`{"link": "https://youtu.be/3dm_5qWWDV8"}`, http.Header{"If-Match": {`"1"`}}).StatusCode)
+	assert.Equal(t, http.StatusAccepted, doWith(http.MethodPatch, "/api/song/"+song.ID,
+		`{"link": "https://youtu.be/3dm_5qWWDV8"}`, http.Header{"If-Match": {tag}}).StatusCode)
+	assert.Equal(t, http.StatusOK, doWith(http.MethodGet, "/api/song/"+song.ID+"/text", "",
+		http.Header{"If-None-Match": {tag}}).StatusCode)
+	assert.Equal(t, http.StatusCreated,
+		do(http.MethodPost, "/api/song", `{"group": "Muse","song": "Hysteria"}`).StatusCode)
+	res = do(http.MethodGet, "/api/songs/suggest?q=Supermasive", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var suggest models.ResponseSuggest
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&suggest))
+	require.Len(t, suggest.Suggestions, 1)
+	assert.Equal(t, song.ID, suggest.Suggestions[0].ID)
+	res = do(http.MethodPost, "/api/song", `{"group": "Muse","song": "Supermasive Black Hole"}`)
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+	var near models.ResponseNearDuplicates
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&near))
+	require.Len(t, near.NearDuplicates, 1)
+	assert.Equal(t, song.ID, near.NearDuplicates[0].ID)
+
+	res = do(http.MethodGet, "/api/artists", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var artists models.ResponseGetArtists
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&artists))
+	require.Len(t, artists.Artists, 1)
+	muse := artists.Artists[0]
+	assert.Equal(t, "Muse", muse.Name)
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPut, "/api/artists/"+muse.ID,
+		`{"name": "Muse", "aliases": ["Rocket Baby Dolls"], "country": "GB", "formed_year": 1994}`).StatusCode)
+	res = do(http.MethodPost, "/api/song", `{"group": "rocket baby dolls","song": "Uprising"}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var uprising models.Song
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&uprising))
+	assert.Equal(t, "Muse", uprising.Group)
+	assert.Equal(t, muse.ID, uprising.ArtistID)
+	res = do(http.MethodGet, "/api/songs?group=Rocket+Baby+Dolls", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&songs))
+	assert.Equal(t, 3, songs.Total)
+	assert.Equal(t, http.StatusConflict, do(http.MethodDelete, "/api/artists/"+muse.ID, "").StatusCode)
+	assert.Equal(t, http.StatusConflict,
+		do(http.MethodPost, "/api/artists", `{"name": "ROCKET BABY DOLLS"}`).StatusCode)
+
+	res = do(http.MethodPost, "/api/albums", `{"title": "The Resistance", "artist": "rocket baby dolls"}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var album models.Album
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&album))
+	assert.Equal(t, muse.ID, album.ArtistID)
+	assert.Equal(t, "/api/albums/"+album.ID, res.Header.Get("Location"))
+	assert.Equal(t, http.StatusConflict,
+		do(http.MethodPost, "/api/albums", `{"title": "The Resistance", "artist": "Muse"}`).StatusCode)
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPost, "/api/albums/"+album.ID+"/tracks",
+		`{"song_id": "`+uprising.ID+`", "track": 1}`).StatusCode)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/api/albums/"+album.ID+"/tracks",
+		`{"song_id": "`+song.ID+`", "track": 1}`).StatusCode)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodPost, "/api/albums/missing/tracks",
+		`{"song_id": "`+song.ID+`", "track": 2}`).StatusCode)
+	res = do(http.MethodGet, "/api/albums/"+album.ID+"/tracks", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var tracks models.ResponseGetTracks
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&tracks))
+	assert.Equal(t, "Muse", tracks.Album.Artist)
+	require.Len(t, tracks.Tracks, 1)
+	assert.Equal(t, 1, tracks.Tracks[0].Track)
+	res = do(http.MethodGet, "/api/songs?album_id="+album.ID, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&songs))
+	require.Len(t, songs.Songs, 1)
+	assert.Equal(t, uprising.ID, songs.Songs[0].ID)
+	assert.Equal(t, album.ID, songs.Songs[0].AlbumID)
+	res = do(http.MethodGet, "/api/albums?artist_id="+muse.ID, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var albums models.ResponseGetAlbums
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&albums))
+	assert.Equal(t, 1, albums.Total)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodGet, "/api/albums/missing", "").StatusCode)
+
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPost, "/api/song/"+uprising.ID+"/tags",
+		`{"tags": ["rock", "english"], "kind": "genre"}`).StatusCode)
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPost, "/api/song/"+song.ID+"/tags",
+		`{"tags": ["Rock"]}`).StatusCode)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodPost, "/api/song/missing/tags",
+		`{"tags": ["rock"]}`).StatusCode)
+	res = do(http.MethodGet, "/api/songs?tags=rock,english&tags_match=all", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&songs))
+	require.Len(t, songs.Songs, 1)
+	assert.Equal(t, []string{"english", "rock"}, songs.Songs[0].Tags)
+	assert.Equal(t, http.StatusAccepted, do(http.MethodDelete, "/api/song/"+song.ID+"/tags",
+		`{"tags": ["rock"]}`).StatusCode)
+	res = do(http.MethodGet, "/api/tags?kind=genre", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var tags models.ResponseGetTags
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&tags))
+	assert.Equal(t, []models.Tag{
+		{Name: "english", Kind: models.TagGenre, Songs: 1},
+		{Name: "rock", Kind: models.TagGenre, Songs: 1},
+	}, tags.Tags)
+
+	res = do(http.MethodPost, "/api/playlists", `{"name": "Road trip"}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var playlist models.Playlist
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&playlist))
+	assert.Equal(t, "/api/playlists/"+playlist.ID, res.Header.Get("Location"))
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPost, "/api/playlists/"+playlist.ID+"/songs",
+		`{"song_id": "`+song.ID+`"}`).StatusCode)
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPost, "/api/playlists/"+playlist.ID+"/songs",
+		`{"song_id": "`+uprising.ID+`", "position": 1}`).StatusCode)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/api/playlists/"+playlist.ID+"/songs",
+		`{"song_id": "`+song.ID+`"}`).StatusCode)
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPut, "/api/playlists/"+playlist.ID+"/songs/"+song.ID,
+		`{"position": 1}`).StatusCode)
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPut, "/api/playlists/"+playlist.ID,
+		`{"name": "Workout"}`).StatusCode)
+	res = do(http.MethodGet, "/api/playlists/"+playlist.ID, "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var listed models.ResponseGetPlaylist
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&listed))
+	assert.Equal(t, "Workout", listed.Playlist.Name)
+	require.Len(t, listed.Songs, 2)
+	assert.Equal(t, song.ID, listed.Songs[0].ID)
+	assert.Equal(t, uprising.ID, listed.Songs[1].ID)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodGet, "/api/playlists/missing", "").StatusCode)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPatch, "/api/song/"+song.ID,
+		`{"song": "Hysteria"}`).StatusCode)
+	assert.Equal(t, http.StatusAccepted, do(http.MethodDelete, "/api/song/"+song.ID, "").StatusCode)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodGet, "/api/song/"+song.ID+"/text", "").StatusCode)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodGet, "/api/song/"+song.ID, "").StatusCode)
+	assert.Equal(t, http.StatusGone,
+		do(http.MethodPost, "/api/song", `{"group": "Muse","song": "Supermassive Black Hole"}`).StatusCode)
+
+	res = do(http.MethodGet, "/api/songs?deleted=only", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&songs))
+	require.Len(t, songs.Songs, 1)
+	assert.True(t, songs.Songs[0].Deleted)
+	assert.Equal(t, http.StatusAccepted, do(http.MethodPost, "/api/song/"+song.ID+"/restore", "").StatusCode)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodPost, "/api/song/"+song.ID+"/restore", "").StatusCode)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/song/"+song.ID+"/text", "").StatusCode)
+
+	res = do(http.MethodGet, "/api/song/"+song.ID+"/revisions", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var revs models.ResponseGetRevisions
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&revs))
+	require.Len(t, revs.Revisions, 6) // tags changes included
+	assert.Equal(t, models.ActionUpdate, revs.Revisions[0].Action)
+	assert.Equal(t, models.ActionUpdate, revs.Revisions[3].Action)
+	assert.Equal(t, models.ActionDelete, revs.Revisions[4].Action)
+	assert.Equal(t, models.ActionRestore, revs.Revisions[5].Action)
+	res = do(http.MethodGet, "/api/song/"+song.ID+"/revisions/1/diff", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var diff models.ResponseGetDiff
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&diff))
+	assert.NotEmpty(t, diff.Lines)
+	assert.Equal(t, http.StatusAccepted,
+		do(http.MethodPost, "/api/song/"+song.ID+"/revisions/1/revert", "").StatusCode)
+	res = do(http.MethodGet, "/api/song/"+song.ID+"/text", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&text))
+	assert.Equal(t, 2, text.Total)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodGet, "/api/song/"+song.ID+"/revisions/9", "").StatusCode)
+	assert.Equal(t, http.StatusNotImplemented, do(http.MethodGet, "/api/search?q=soul", "").StatusCode)
+
+	assert.Equal(t, http.StatusAccepted, do(http.MethodDelete, "/api/song/"+song.ID+"?purge=true", "").StatusCode)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/song/"+song.ID+"?purge=true", "").StatusCode)
+	assert.Equal(t, http.StatusCreated,
+		do(http.MethodPost, "/api/song", `{"group": "Muse","song": "Supermassive Black Hole"}`).StatusCode)
+
+	rec := httptest.NewRecorder()
+	adminRoutes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `songs_http_requests_total{code="202",method="DELETE",route="/api/song/{id}"}`)
+	assert.Contains(t, rec.Body.String(), `songs_storage_query_duration_seconds_count{query="Add",status="ok"}`)
+	assert.Contains(t, rec.Body.String(), `songs_music_info_request_duration_seconds_count{code="200"}`)
+}
+
+func Test_routes_tracing(t *testing.T) {
+	var traceparent string
+	info := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			traceparent = r.Header.Get("traceparent")
+			if err := json.NewEncoder(w).Encode(&models.ResponseDetailSong{ReleaseDate: "16.07.2006"}); err != nil {
+				panic(err)
+			}
+		}))
+	defer info.Close()
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := tracing.Setup(context.Background(), "file://"+path)
+	require.NoError(t, err)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	cfg := &config.Config{DBDriver: config.DriverMemory, MusicInfoURL: info.URL}
+	st, err := storage.Open(context.Background(), cfg)
+	require.NoError(t, err)
+	sv := service.New(cfg, storage.Instrument(st), requests.New(cfg))
+	key, err := sv.AddAPIKey(context.Background(), "test", roles.Admin)
+	require.NoError(t, err)
+	srv := httptest.NewServer(routes(handlers.NewHTTP(sv), handlers.NewRateLimit(cfg), false))
+	defer srv.Close()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	r, err := http.NewRequest(http.MethodPost, srv.URL+"/api/song", strings.NewReader(`{"group": "Muse","song": "Uprising"}`))
+	require.NoError(t, err)
+	r.Header.Set(handlers.APIKeyHeader, key.Key)
+	r.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	res, err := srv.Client().Do(r)
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Contains(t, traceparent, traceID)
+
+	require.NoError(t, shutdown(context.Background()))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	names := make(map[string]string)
+	for dec := json.NewDecoder(f); dec.More(); {
+		var span struct {
+			Name        string
+			SpanContext struct{ TraceID string }
+		}
+		require.NoError(t, dec.Decode(&span))
+		names[span.Name] = span.SpanContext.TraceID
+	}
+	for _, name := range []string{"POST /api/song", "Service.Add", "GET /info", "storage.Add", "storage.GetAPIKey"} {
+		assert.Equal(t, traceID, names[name], name)
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/storage"
+	"github.com/xEgorka/project4/internal/app/tracing"
+)
+
+// AddAlbum creates album of artist resolved by name or alias, missing
+// artist is created.
+func (s *Service) AddAlbum(ctx context.Context, r models.RequestAddAlbum) (_ models.Album, err error) {
+	ctx, span := tracing.Start(ctx, "Service.AddAlbum")
+	defer func() { tracing.End(span, err) }()
+	a, err := s.artist(ctx, r.Artist)
+	if err != nil {
+		logger.Log.Info("failed resolve artist", zap.Error(err))
+		return models.Album{}, status.Error(codes.Internal, "internal")
+	}
+	d, err := s.s.AddAlbum(ctx, models.Album{
+		Title:       r.Title,
+		ArtistID:    a.ID,
+		ReleaseDate: r.ReleaseDate,
+		CoverLink:   r.CoverLink,
+	})
+	if err != nil {
+		if err == storage.ErrUniqueViolation {
+			return models.Album{}, status.Error(codes.AlreadyExists, "already exists")
+		}
+		logger.Log.Info("failed add album", zap.Error(err))
+		return models.Album{}, status.Error(codes.Internal, "internal")
+	}
+	return d, nil
+}
+
+// GetAlbum returns album.
+func (s *Service) GetAlbum(ctx context.Context, id string) (_ models.Album, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetAlbum")
+	defer func() { tracing.End(span, err) }()
+	d, err := s.s.GetAlbum(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Album{}, status.Error(codes.NotFound, "not found")
+		}
+		return models.Album{}, status.Error(codes.Internal, "internal")
+	}
+	return d, nil
+}
+
+// GetAlbums returns albums page ordered by title, empty artist id lists
+// albums of all artists.
+func (s *Service) GetAlbums(ctx context.Context, artistID string,
+	page, size int) (_ models.ResponseGetAlbums, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetAlbums")
+	defer func() { tracing.End(span, err) }()
+	d, err := s.s.GetAlbums(ctx, artistID, page, size)
+	if err != nil {
+		return d, status.Error(codes.Internal, "internal")
+	}
+	return d, nil
+}
+
+// GetTracks returns album with songs ordered by track number.
+func (s *Service) GetTracks(ctx context.Context, id string) (_ models.ResponseGetTracks, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetTracks")
+	defer func() { tracing.End(span, err) }()
+	a, err := s.GetAlbum(ctx, id)
+	if err != nil {
+		return models.ResponseGetTracks{}, err
+	}
+	dd, err := s.s.GetTracks(ctx, id)
+	if err != nil {
+		return models.ResponseGetTracks{}, status.Error(codes.Internal, "internal")
+	}
+	return models.ResponseGetTracks{Album: a, Tracks: dd}, nil
+}
+
+// AttachTrack places song on album position.
+func (s *Service) AttachTrack(ctx context.Context, id string, r models.RequestAttachTrack) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.AttachTrack")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.AttachTrack(ctx, id, r.SongID, r.Track); err != nil {
+		switch err {
+		case storage.ErrNotAffected:
+			return status.Error(codes.NotFound, "not found")
+		case storage.ErrUniqueViolation:
+			return status.Error(codes.AlreadyExists, "already exists")
+		}
+		logger.Log.Info("failed attach track", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
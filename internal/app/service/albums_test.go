@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestAddAlbum(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	muse := models.Artist{ID: "1", Name: "Muse"}
+	r := models.RequestAddAlbum{Title: "Absolution", Artist: "muse"}
+	a := models.Album{Title: r.Title, ArtistID: muse.ID}
+	tests := []struct {
+		name      string
+		resolved  error
+		err       error
+		wantCode  codes.Code
+		wantAdded bool
+	}{
+		{name: "positive test #1", wantCode: codes.OK, wantAdded: true},
+		{name: "positive test #2", resolved: sql.ErrNoRows, wantCode: codes.OK, wantAdded: true},
+		{name: "negative test #1", err: storage.ErrUniqueViolation, wantCode: codes.AlreadyExists, wantAdded: true},
+		{name: "negative test #2", resolved: errors.New("test"), wantCode: codes.Internal},
+		{name: "negative test #3", err: errors.New("test"), wantCode: codes.Internal, wantAdded: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().ResolveArtist(gomock.Any(), r.Artist).Return(muse, tt.resolved)
+			if tt.resolved == sql.ErrNoRows {
+				ms.EXPECT().AddArtist(gomock.Any(), models.Artist{Name: r.Artist}).Return(muse, nil)
+			}
+			if tt.wantAdded {
+				ms.EXPECT().AddAlbum(gomock.Any(), a).Return(a, tt.err)
+			}
+			if _, err := s.AddAlbum(context.Background(), r); status.Code(err) != tt.wantCode {
+				t.Errorf("Service.AddAlbum() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestGetTracks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		albumErr error
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", albumErr: sql.ErrNoRows, wantCode: codes.NotFound},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().GetAlbum(gomock.Any(), "1").Return(models.Album{ID: "1"}, tt.albumErr)
+			if tt.albumErr == nil {
+				ms.EXPECT().GetTracks(gomock.Any(), "1").Return([]models.Song{{Track: 1}}, tt.err)
+			}
+			got, err := s.GetTracks(context.Background(), "1")
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.GetTracks() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			if err == nil && (got.Album.ID != "1" || len(got.Tracks) != 1) {
+				t.Errorf("Service.GetTracks() = %v", got)
+			}
+		})
+	}
+}
+
+func TestAttachTrack(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrNotAffected, wantCode: codes.NotFound},
+		{name: "negative test #2", err: storage.ErrUniqueViolation, wantCode: codes.AlreadyExists},
+		{name: "negative test #3", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().AttachTrack(gomock.Any(), "1", "2", 3).Return(tt.err)
+			err := s.AttachTrack(context.Background(), "1", models.RequestAttachTrack{SongID: "2", Track: 3})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.AttachTrack() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/storage"
+	"github.com/xEgorka/project4/internal/app/tracing"
+)
+
+// resolve returns artist having name or alias equal to name, zero
+// artist if there is none.
+func (s *Service) resolve(ctx context.Context, name string) (models.Artist, error) {
+	a, err := s.s.ResolveArtist(ctx, name)
+	if err == sql.ErrNoRows {
+		return models.Artist{}, nil
+	}
+	return a, err
+}
+
+// artist resolves name or alias to artist creating missing one.
+func (s *Service) artist(ctx context.Context, name string) (models.Artist, error) {
+	a, err := s.resolve(ctx, name)
+	if err != nil || len(a.ID) > 0 {
+		return a, err
+	}
+	a, err = s.s.AddArtist(ctx, models.Artist{Name: name})
+	if err == storage.ErrUniqueViolation { // created concurrently
+		return s.s.ResolveArtist(ctx, name)
+	}
+	return a, err
+}
+
+// AddArtist creates artist.
+func (s *Service) AddArtist(ctx context.Context, r models.RequestArtist) (_ models.Artist, err error) {
+	ctx, span := tracing.Start(ctx, "Service.AddArtist")
+	defer func() { tracing.End(span, err) }()
+	a, err := s.s.AddArtist(ctx, models.Artist{
+		Name:    r.Name,
+		Aliases: r.Aliases,
+		Country: r.Country,
+		Formed:  r.Formed,
+	})
+	if err != nil {
+		if err == storage.ErrUniqueViolation {
+			return models.Artist{}, status.Error(codes.AlreadyExists, "already exists")
+		}
+		logger.Log.Info("failed add artist", zap.Error(err))
+		return models.Artist{}, status.Error(codes.Internal, "internal")
+	}
+	return a, nil
+}
+
+// GetArtist returns artist.
+func (s *Service) GetArtist(ctx context.Context, id string) (_ models.Artist, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetArtist")
+	defer func() { tracing.End(span, err) }()
+	a, err := s.s.GetArtist(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Artist{}, status.Error(codes.NotFound, "not found")
+		}
+		return models.Artist{}, status.Error(codes.Internal, "internal")
+	}
+	return a, nil
+}
+
+// GetArtists returns artists page ordered by name.
+func (s *Service) GetArtists(ctx context.Context, page, size int) (_ models.ResponseGetArtists, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetArtists")
+	defer func() { tracing.End(span, err) }()
+	d, err := s.s.GetArtists(ctx, page, size)
+	if err != nil {
+		return d, status.Error(codes.Internal, "internal")
+	}
+	return d, nil
+}
+
+// UpdateArtist replaces artist data and aliases, songs of renamed artist
+// get new group.
+func (s *Service) UpdateArtist(ctx context.Context, id string, r models.RequestArtist) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.UpdateArtist")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.UpdateArtist(ctx, models.Artist{
+		ID:      id,
+		Name:    r.Name,
+		Aliases: r.Aliases,
+		Country: r.Country,
+		Formed:  r.Formed,
+	}); err != nil {
+		switch err {
+		case storage.ErrNotAffected:
+			return status.Error(codes.NotFound, "not found")
+		case storage.ErrUniqueViolation:
+			return status.Error(codes.AlreadyExists, "already exists")
+		}
+		logger.Log.Info("failed update artist", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// DeleteArtist removes artist without songs.
+func (s *Service) DeleteArtist(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.DeleteArtist")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.DeleteArtist(ctx, id); err != nil {
+		switch err {
+		case storage.ErrNotAffected:
+			return status.Error(codes.NotFound, "not found")
+		case storage.ErrArtistInUse:
+			return status.Error(codes.FailedPrecondition, "artist has songs")
+		}
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
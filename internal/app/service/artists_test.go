@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestAddArtist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	r := models.RequestArtist{Name: "The Beatles", Aliases: []string{"Beatles"}, Country: "GB", Formed: 1960}
+	a := models.Artist{Name: r.Name, Aliases: r.Aliases, Country: r.Country, Formed: r.Formed}
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrUniqueViolation, wantCode: codes.AlreadyExists},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().AddArtist(gomock.Any(), a).Return(a, tt.err)
+			if _, err := s.AddArtist(context.Background(), r); status.Code(err) != tt.wantCode {
+				t.Errorf("Service.AddArtist() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestGetArtist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: sql.ErrNoRows, wantCode: codes.NotFound},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().GetArtist(gomock.Any(), "1").Return(models.Artist{}, tt.err)
+			if _, err := s.GetArtist(context.Background(), "1"); status.Code(err) != tt.wantCode {
+				t.Errorf("Service.GetArtist() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestUpdateArtist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrNotAffected, wantCode: codes.NotFound},
+		{name: "negative test #2", err: storage.ErrUniqueViolation, wantCode: codes.AlreadyExists},
+		{name: "negative test #3", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().UpdateArtist(gomock.Any(), models.Artist{ID: "1", Name: "Queen"}).Return(tt.err)
+			err := s.UpdateArtist(context.Background(), "1", models.RequestArtist{Name: "Queen"})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.UpdateArtist() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestDeleteArtist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrNotAffected, wantCode: codes.NotFound},
+		{name: "negative test #2", err: storage.ErrArtistInUse, wantCode: codes.FailedPrecondition},
+		{name: "negative test #3", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().DeleteArtist(gomock.Any(), "1").Return(tt.err)
+			if err := s.DeleteArtist(context.Background(), "1"); status.Code(err) != tt.wantCode {
+				t.Errorf("Service.DeleteArtist() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestGetSongs_artistAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	beatles := models.Artist{ID: "1", Name: "The Beatles"}
+	tests := []struct {
+		name     string
+		r        models.RequestGetSongs
+		resolved error
+		want     models.Song
+	}{
+		{
+			name: "positive test #1",
+			r:    models.RequestGetSongs{Filter: models.Song{Group: "beatles"}},
+			want: models.Song{ArtistID: "1"},
+		},
+		{
+			name:     "positive test #2",
+			r:        models.RequestGetSongs{Filter: models.Song{Group: "Muse"}},
+			resolved: sql.ErrNoRows,
+			want:     models.Song{Group: "Muse"},
+		},
+		{
+			name: "positive test #3",
+			r:    models.RequestGetSongs{Filter: models.Song{Group: "beat"}, Match: models.Match{Group: models.MatchPrefix}},
+			want: models.Song{Group: "beat"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.r.Match.Group == "" {
+				ms.EXPECT().ResolveArtist(gomock.Any(), tt.r.Filter.Group).Return(beatles, tt.resolved)
+			}
+			want := tt.r
+			want.Filter = tt.want
+			ms.EXPECT().GetSongs(gomock.Any(), want).Return(models.ResponseGetSongs{}, nil)
+			if _, err := s.GetSongs(context.Background(), tt.r); err != nil {
+				t.Errorf("Service.GetSongs() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPatch_artist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		group    string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", group: "queen", wantCode: codes.OK},
+		{name: "negative test #1", group: "Queen", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// storage resolves group to artist in song transaction
+			ms.EXPECT().Patch(gomock.Any(), "s", 0, models.RequestPatchSong{Group: &tt.group}).Return(tt.err)
+			err := s.Patch(context.Background(), "s", 0, models.RequestPatchSong{Group: &tt.group})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.Patch() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
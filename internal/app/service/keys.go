@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/roles"
+	"github.com/xEgorka/project4/internal/app/storage"
+	"github.com/xEgorka/project4/internal/app/tracing"
+)
+
+// keySize is number of random bytes of API key.
+const keySize = 32
+
+// hashKey returns API key hash kept by storage.
+func hashKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// AddAPIKey creates API key of caller with role, key is returned once and
+// only its hash is kept.
+func (s *Service) AddAPIKey(ctx context.Context, name, role string) (_ models.ResponseAddAPIKey, err error) {
+	ctx, span := tracing.Start(ctx, "Service.AddAPIKey")
+	defer func() { tracing.End(span, err) }()
+	if !roles.Valid(role) {
+		return models.ResponseAddAPIKey{}, status.Error(codes.InvalidArgument, "invalid role")
+	}
+	b := make([]byte, keySize)
+	if _, err := rand.Read(b); err != nil {
+		logger.Log.Info("failed generate api key", zap.Error(err))
+		return models.ResponseAddAPIKey{}, status.Error(codes.Internal, "internal")
+	}
+	key := base64.RawURLEncoding.EncodeToString(b)
+	d, err := s.s.AddAPIKey(ctx, models.APIKey{Name: name, Role: role, Hash: hashKey(key)})
+	if err != nil {
+		logger.Log.Info("failed add api key", zap.Error(err))
+		return models.ResponseAddAPIKey{}, status.Error(codes.Internal, "internal")
+	}
+	return models.ResponseAddAPIKey{APIKey: d, Key: key}, nil
+}
+
+// GetAPIKeys returns API keys in creation order.
+func (s *Service) GetAPIKeys(ctx context.Context) (_ []models.APIKey, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetAPIKeys")
+	defer func() { tracing.End(span, err) }()
+	dd, err := s.s.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal")
+	}
+	return dd, nil
+}
+
+// DeleteAPIKey revokes API key.
+func (s *Service) DeleteAPIKey(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.DeleteAPIKey")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.DeleteAPIKey(ctx, id); err != nil {
+		if err == storage.ErrNotAffected {
+			return status.Error(codes.NotFound, "not found")
+		}
+		logger.Log.Info("failed delete api key", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// Authenticate returns caller identified by API key.
+func (s *Service) Authenticate(ctx context.Context, key string) (_ models.Caller, err error) {
+	ctx, span := tracing.Start(ctx, "Service.Authenticate")
+	defer func() { tracing.End(span, err) }()
+	d, err := s.s.GetAPIKey(ctx, hashKey(key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Caller{}, status.Error(codes.Unauthenticated, "unauthenticated")
+		}
+		logger.Log.Info("failed get api key", zap.Error(err))
+		return models.Caller{}, status.Error(codes.Internal, "internal")
+	}
+	return models.Caller{ID: d.ID, Name: d.Name, Role: d.Role}, nil
+}
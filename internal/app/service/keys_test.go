@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/roles"
+)
+
+func TestAddAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		role     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", role: roles.Editor, wantCode: codes.OK},
+		{name: "negative test #1", role: roles.Editor, err: errors.New("test"), wantCode: codes.Internal},
+		{name: "negative test #2", role: "root", wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kept models.APIKey
+			if tt.wantCode != codes.InvalidArgument {
+				ms.EXPECT().AddAPIKey(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, k models.APIKey) (models.APIKey, error) {
+						kept = k
+						return k, tt.err
+					})
+			}
+			got, err := s.AddAPIKey(context.Background(), "ci", tt.role)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.AddAPIKey() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			if err == nil && (got.Key == "" || kept.Name != "ci" || kept.Role != tt.role ||
+				kept.Hash != hashKey(got.Key)) {
+				t.Errorf("Service.AddAPIKey() = %v, kept %v", got, kept)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		want     models.Caller
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", want: models.Caller{ID: "1", Name: "ci", Role: roles.Reader}, wantCode: codes.OK},
+		{name: "negative test #1", err: sql.ErrNoRows, wantCode: codes.Unauthenticated},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().GetAPIKey(gomock.Any(), hashKey("secret")).
+				Return(models.APIKey{ID: "1", Name: "ci", Role: roles.Reader, Hash: hashKey("secret")}, tt.err)
+			got, err := s.Authenticate(context.Background(), "secret")
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.Authenticate() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			if got != tt.want {
+				t.Errorf("Service.Authenticate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/storage"
+	"github.com/xEgorka/project4/internal/app/tracing"
+)
+
+// AddPlaylist creates empty playlist.
+func (s *Service) AddPlaylist(ctx context.Context, r models.RequestPlaylist) (_ models.Playlist, err error) {
+	ctx, span := tracing.Start(ctx, "Service.AddPlaylist")
+	defer func() { tracing.End(span, err) }()
+	d, err := s.s.AddPlaylist(ctx, models.Playlist{Name: r.Name})
+	if err != nil {
+		logger.Log.Info("failed add playlist", zap.Error(err))
+		return models.Playlist{}, status.Error(codes.Internal, "internal")
+	}
+	return d, nil
+}
+
+// GetPlaylist returns playlist.
+func (s *Service) GetPlaylist(ctx context.Context, id string) (_ models.Playlist, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetPlaylist")
+	defer func() { tracing.End(span, err) }()
+	d, err := s.s.GetPlaylist(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Playlist{}, status.Error(codes.NotFound, "not found")
+		}
+		return models.Playlist{}, status.Error(codes.Internal, "internal")
+	}
+	return d, nil
+}
+
+// GetPlaylists returns playlists page ordered by name.
+func (s *Service) GetPlaylists(ctx context.Context, page, size int) (_ models.ResponseGetPlaylists, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetPlaylists")
+	defer func() { tracing.End(span, err) }()
+	d, err := s.s.GetPlaylists(ctx, page, size)
+	if err != nil {
+		return d, status.Error(codes.Internal, "internal")
+	}
+	return d, nil
+}
+
+// GetPlaylistSongs returns playlist with page of songs in playlist order.
+func (s *Service) GetPlaylistSongs(ctx context.Context, id string,
+	page, size int) (_ models.ResponseGetPlaylist, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetPlaylistSongs")
+	defer func() { tracing.End(span, err) }()
+	p, err := s.GetPlaylist(ctx, id)
+	if err != nil {
+		return models.ResponseGetPlaylist{}, err
+	}
+	d, err := s.s.GetPlaylistSongs(ctx, id, page, size)
+	if err != nil {
+		return models.ResponseGetPlaylist{}, status.Error(codes.Internal, "internal")
+	}
+	d.Playlist = p
+	return d, nil
+}
+
+// RenamePlaylist changes playlist name.
+func (s *Service) RenamePlaylist(ctx context.Context, id string, r models.RequestPlaylist) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.RenamePlaylist")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.RenamePlaylist(ctx, id, r.Name); err != nil {
+		if err == storage.ErrNotAffected {
+			return status.Error(codes.NotFound, "not found")
+		}
+		logger.Log.Info("failed rename playlist", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// DeletePlaylist removes playlist.
+func (s *Service) DeletePlaylist(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.DeletePlaylist")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.DeletePlaylist(ctx, id); err != nil {
+		if err == storage.ErrNotAffected {
+			return status.Error(codes.NotFound, "not found")
+		}
+		logger.Log.Info("failed delete playlist", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// AddPlaylistSong puts song on playlist position.
+func (s *Service) AddPlaylistSong(ctx context.Context, id string, r models.RequestPlaylistSong) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.AddPlaylistSong")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.AddPlaylistSong(ctx, id, r.SongID, r.Position); err != nil {
+		switch err {
+		case storage.ErrNotAffected:
+			return status.Error(codes.NotFound, "not found")
+		case storage.ErrUniqueViolation:
+			return status.Error(codes.AlreadyExists, "already exists")
+		}
+		logger.Log.Info("failed add playlist song", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// MovePlaylistSong moves playlist song to position.
+func (s *Service) MovePlaylistSong(ctx context.Context, id, songID string,
+	r models.RequestMovePlaylistSong) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.MovePlaylistSong")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.MovePlaylistSong(ctx, id, songID, r.Position); err != nil {
+		if err == storage.ErrNotAffected {
+			return status.Error(codes.NotFound, "not found")
+		}
+		logger.Log.Info("failed move playlist song", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// RemovePlaylistSong removes song from playlist.
+func (s *Service) RemovePlaylistSong(ctx context.Context, id, songID string) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.RemovePlaylistSong")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.RemovePlaylistSong(ctx, id, songID); err != nil {
+		if err == storage.ErrNotAffected {
+			return status.Error(codes.NotFound, "not found")
+		}
+		logger.Log.Info("failed remove playlist song", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
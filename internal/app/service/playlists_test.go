@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestGetPlaylistSongs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name        string
+		playlistErr error
+		err         error
+		wantCode    codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", playlistErr: sql.ErrNoRows, wantCode: codes.NotFound},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().GetPlaylist(gomock.Any(), "1").Return(models.Playlist{ID: "1"}, tt.playlistErr)
+			if tt.playlistErr == nil {
+				ms.EXPECT().GetPlaylistSongs(gomock.Any(), "1", 1, 10).
+					Return(models.ResponseGetPlaylist{Songs: []models.Song{{ID: "2"}}, Total: 1}, tt.err)
+			}
+			got, err := s.GetPlaylistSongs(context.Background(), "1", 1, 10)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.GetPlaylistSongs() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+			if err == nil && (got.Playlist.ID != "1" || len(got.Songs) != 1) {
+				t.Errorf("Service.GetPlaylistSongs() = %v", got)
+			}
+		})
+	}
+}
+
+func TestAddPlaylistSong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrNotAffected, wantCode: codes.NotFound},
+		{name: "negative test #2", err: storage.ErrUniqueViolation, wantCode: codes.AlreadyExists},
+		{name: "negative test #3", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().AddPlaylistSong(gomock.Any(), "1", "2", 3).Return(tt.err)
+			err := s.AddPlaylistSong(context.Background(), "1", models.RequestPlaylistSong{SongID: "2", Position: 3})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.AddPlaylistSong() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestMovePlaylistSong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrNotAffected, wantCode: codes.NotFound},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().MovePlaylistSong(gomock.Any(), "1", "2", 1).Return(tt.err)
+			err := s.MovePlaylistSong(context.Background(), "1", "2", models.RequestMovePlaylistSong{Position: 1})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.MovePlaylistSong() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestDeletePlaylist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrNotAffected, wantCode: codes.NotFound},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().DeletePlaylist(gomock.Any(), "1").Return(tt.err)
+			if err := s.DeletePlaylist(context.Background(), "1"); status.Code(err) != tt.wantCode {
+				t.Errorf("Service.DeletePlaylist() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"time"
@@ -11,26 +12,86 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/diff"
+	"github.com/xEgorka/project4/internal/app/jwks"
 	"github.com/xEgorka/project4/internal/app/logger"
 	"github.com/xEgorka/project4/internal/app/models"
 	"github.com/xEgorka/project4/internal/app/requests"
 	"github.com/xEgorka/project4/internal/app/storage"
+	"github.com/xEgorka/project4/internal/app/tracing"
+	"github.com/xEgorka/project4/internal/app/trgm"
 )
 
 // Service provides business logic.
 type Service struct {
-	cfg *config.Config
-	s   storage.Storage
-	r   *requests.HTTP
+	cfg  *config.Config
+	s    storage.Storage
+	r    *requests.HTTP
+	keys *jwks.Set // nil unless bearer tokens are enabled
 }
 
 // New creates Service.
 func New(config *config.Config, store storage.Storage, requests *requests.HTTP) *Service {
-	return &Service{cfg: config, s: store, r: requests}
+	s := &Service{cfg: config, s: store, r: requests}
+	if len(config.JWKSURL) > 0 {
+		s.keys = jwks.New(config.JWKSURL, config.JWKSRefresh)
+	}
+	return s
+}
+
+// NearDuplicatesError is returned by Add when library has songs with
+// names similar to added one.
+type NearDuplicatesError struct{ Songs []models.Suggestion }
+
+func (e *NearDuplicatesError) Error() string { return "near duplicates exist" }
+
+// NearDuplicateSimilarity is minimal similarity of group and song names
+// of near duplicate songs.
+const NearDuplicateSimilarity = 0.6
+
+// nearDuplicates returns songs with group and song names similar to
+// added ones, exact duplicate is left for unique index.
+func (s *Service) nearDuplicates(ctx context.Context, r models.RequestAddSong) ([]models.Suggestion, error) {
+	name := r.Group + " " + r.Song
+	dd, err := s.s.Suggest(ctx, name, DefaultSizeSuggest)
+	if err != nil {
+		return nil, err
+	}
+	var res []models.Suggestion
+	for _, d := range dd {
+		d.Score = trgm.Similarity(trgm.Fold(name), trgm.Fold(d.Group+" "+d.Song))
+		if d.Score >= NearDuplicateSimilarity && (d.Group != r.Group || d.Song != r.Song) {
+			res = append(res, d)
+		}
+	}
+	return res, nil
 }
 
-// Add creates song in library.
-func (s *Service) Add(ctx context.Context, r models.RequestAddSong) (models.Song, error) {
+// Add creates song in library, group alias is replaced with artist name
+// and songs with similar names are rejected with NearDuplicatesError
+// unless force is set.
+func (s *Service) Add(ctx context.Context, r models.RequestAddSong, force bool) (_ models.Song, err error) {
+	ctx, span := tracing.Start(ctx, "Service.Add")
+	defer func() { tracing.End(span, err) }()
+	a, err := s.resolve(ctx, r.Group)
+	if err != nil {
+		logger.Log.Info("failed resolve artist", zap.Error(err))
+		return models.Song{}, status.Error(codes.Internal, "internal")
+	}
+	if len(a.ID) > 0 {
+		r.Group = a.Name
+	}
+	if !force {
+		dd, err := s.nearDuplicates(ctx, r)
+		if err != nil {
+			logger.Log.Info("failed find near duplicates", zap.Error(err))
+			return models.Song{}, status.Error(codes.Internal, "internal")
+		}
+		if len(dd) > 0 {
+			return models.Song{}, &NearDuplicatesError{Songs: dd}
+		}
+	}
+
 	d, err := s.r.GetSongDetail(ctx, r)
 	if err != nil {
 		logger.Log.Info("unable to get song detail", zap.Error(err))
@@ -48,7 +109,8 @@ func (s *Service) Add(ctx context.Context, r models.RequestAddSong) (models.Song
 		Song:        r.Song,
 		Text:        d.Text,
 		ReleaseDate: ReleaseDateDate,
-		Link:        d.Link})
+		Link:        d.Link,
+		ArtistID:    a.ID})
 
 	if err != nil {
 		if err == storage.ErrUniqueViolation {
@@ -64,21 +126,80 @@ func (s *Service) Add(ctx context.Context, r models.RequestAddSong) (models.Song
 	return song, nil
 }
 
-// Update changes song in library.
-func (s *Service) Update(ctx context.Context, id string,
-	data models.RequestUpdateSong) error {
-	if err := s.s.Update(ctx, id, data); err != nil {
-		if err == storage.ErrNotAffected {
+// Get returns song from library.
+func (s *Service) Get(ctx context.Context, id string) (_ models.Song, err error) {
+	ctx, span := tracing.Start(ctx, "Service.Get")
+	defer func() { tracing.End(span, err) }()
+	d, err := s.s.Get(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Song{}, status.Error(codes.NotFound, "not found")
+		}
+		logger.Log.Info("failed get song", zap.Error(err))
+		return models.Song{}, status.Error(codes.Internal, "internal")
+	}
+	return d, nil
+}
+
+// Update changes song in library, zero version skips version check.
+func (s *Service) Update(ctx context.Context, id string, version int,
+	data models.RequestUpdateSong) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.Update")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.Update(ctx, id, version, data); err != nil {
+		switch err {
+		case storage.ErrNotAffected:
+			return status.Error(codes.NotFound, "not found")
+		case storage.ErrVersionMismatch:
+			return status.Error(codes.FailedPrecondition, "version mismatch")
+		}
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// Patch changes song fields set by patch, zero version skips version
+// check, group alias is replaced with artist name.
+func (s *Service) Patch(ctx context.Context, id string, version int,
+	data models.RequestPatchSong) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.Patch")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.Patch(ctx, id, version, data); err != nil {
+		switch err {
+		case storage.ErrNotAffected:
+			return status.Error(codes.NotFound, "not found")
+		case storage.ErrVersionMismatch:
+			return status.Error(codes.FailedPrecondition, "version mismatch")
+		case storage.ErrUniqueViolation:
+			return status.Error(codes.AlreadyExists, "already exists")
+		}
+		logger.Log.Info("failed patch song", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// Delete removes song from library, zero version skips version check.
+func (s *Service) Delete(ctx context.Context, id string, version int) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.Delete")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.Delete(ctx, id, version); err != nil {
+		switch err {
+		case storage.ErrNotAffected:
 			return status.Error(codes.NotFound, "not found")
+		case storage.ErrVersionMismatch:
+			return status.Error(codes.FailedPrecondition, "version mismatch")
 		}
 		return status.Error(codes.Internal, "internal")
 	}
 	return nil
 }
 
-// Delete removes song from library.
-func (s *Service) Delete(ctx context.Context, id string) error {
-	if err := s.s.Delete(ctx, id); err != nil {
+// Restore brings deleted song back to library.
+func (s *Service) Restore(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.Restore")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.Restore(ctx, id); err != nil {
 		if err == storage.ErrNotAffected {
 			return status.Error(codes.NotFound, "not found")
 		}
@@ -87,6 +208,143 @@ func (s *Service) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Purge permanently removes song from library, zero version skips
+// version check.
+func (s *Service) Purge(ctx context.Context, id string, version int) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.Purge")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.Purge(ctx, id, version); err != nil {
+		switch err {
+		case storage.ErrNotAffected:
+			return status.Error(codes.NotFound, "not found")
+		case storage.ErrVersionMismatch:
+			return status.Error(codes.FailedPrecondition, "version mismatch")
+		}
+		return status.Error(codes.Internal, "internal")
+	}
+	logger.Log.Info("purged song", zap.String("id", id))
+	return nil
+}
+
+// PurgeDeleted permanently removes songs deleted longer than retention
+// period ago.
+func (s *Service) PurgeDeleted(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "Service.PurgeDeleted")
+	defer func() { tracing.End(span, err) }()
+	before := time.Now().AddDate(0, 0, -s.cfg.RetentionDays)
+	n, err := s.s.PurgeDeleted(ctx, before)
+	if err != nil {
+		logger.Log.Error("failed purge deleted songs", zap.Error(err))
+		return 0, status.Error(codes.Internal, "internal")
+	}
+	logger.Log.Info("purged deleted songs",
+		zap.Int64("count", n), zap.Time("deleted_before", before))
+	return n, nil
+}
+
+// Retain purges deleted songs every interval until context is done, it
+// does nothing if retention period is not configured.
+func (s *Service) Retain(ctx context.Context, interval time.Duration) {
+	if s.cfg.RetentionDays <= 0 {
+		return
+	}
+	logger.Log.Info("running retention job...",
+		zap.Int("retention_days", s.cfg.RetentionDays), zap.Duration("interval", interval))
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		s.PurgeDeleted(ctx) // errors are logged and retried on next tick
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("retention job stopping... done")
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// GetRevisions returns song revisions.
+func (s *Service) GetRevisions(ctx context.Context,
+	id string) (_ models.ResponseGetRevisions, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetRevisions")
+	defer func() { tracing.End(span, err) }()
+	revs, err := s.s.GetRevisions(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ResponseGetRevisions{}, status.Error(codes.NotFound, "not found")
+		}
+		return models.ResponseGetRevisions{}, status.Error(codes.Internal, "internal")
+	}
+	return models.ResponseGetRevisions{ID: id, Revisions: revs}, nil
+}
+
+// GetRevision returns song revision.
+func (s *Service) GetRevision(ctx context.Context, id string, rev int) (_ models.Revision, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetRevision")
+	defer func() { tracing.End(span, err) }()
+	r, err := s.s.GetRevision(ctx, id, rev)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Revision{}, status.Error(codes.NotFound, "not found")
+		}
+		return models.Revision{}, status.Error(codes.Internal, "internal")
+	}
+	return r, nil
+}
+
+// Diff compares lyrics of song revisions, zero to compares with current
+// song lyrics.
+func (s *Service) Diff(ctx context.Context, id string,
+	from, to int) (_ models.ResponseGetDiff, err error) {
+	ctx, span := tracing.Start(ctx, "Service.Diff")
+	defer func() { tracing.End(span, err) }()
+	a, err := s.GetRevision(ctx, id, from)
+	if err != nil {
+		return models.ResponseGetDiff{}, err
+	}
+	var text string
+	if to == 0 {
+		d, err := s.s.GetSongs(ctx, models.RequestGetSongs{
+			Filter:  models.Song{ID: id},
+			Deleted: models.DeletedInclude,
+			Page:    1,
+			Size:    1,
+		})
+		if err != nil {
+			return models.ResponseGetDiff{}, status.Error(codes.Internal, "internal")
+		}
+		if len(d.Songs) == 0 {
+			return models.ResponseGetDiff{}, status.Error(codes.NotFound, "not found")
+		}
+		text = d.Songs[0].Text
+	} else {
+		b, err := s.GetRevision(ctx, id, to)
+		if err != nil {
+			return models.ResponseGetDiff{}, err
+		}
+		text = b.Text
+	}
+	return models.ResponseGetDiff{ID: id, From: from, To: to, Lines: diff.Lines(a.Text, text)}, nil
+}
+
+// Revert rolls song back to revision, current state is saved as new
+// revision.
+func (s *Service) Revert(ctx context.Context, id string, rev int) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.Revert")
+	defer func() { tracing.End(span, err) }()
+	r, err := s.GetRevision(ctx, id, rev)
+	if err != nil {
+		return err
+	}
+	return s.Patch(ctx, id, 0, models.RequestPatchSong{
+		Group:       &r.Group,
+		Song:        &r.Song,
+		ReleaseDate: &r.ReleaseDate,
+		Text:        &r.Text,
+		Link:        &r.Link,
+	})
+}
+
 const (
 	// DefaultPage is page by default.
 	DefaultPage = 1
@@ -94,11 +352,22 @@ const (
 	DefaultSizeText = 3
 	// DefaultSizeSongs is size by default for songs list pagination.
 	DefaultSizeSongs = 10
+	// DefaultSizeSuggest is number of suggestions by default.
+	DefaultSizeSuggest = 10
+	// DefaultSizeArtists is size by default for artists list pagination.
+	DefaultSizeArtists = 10
+	// DefaultSizeAlbums is size by default for albums list pagination.
+	DefaultSizeAlbums = 10
+	// DefaultSizePlaylists is size by default for playlists and playlist
+	// songs pagination.
+	DefaultSizePlaylists = 10
 )
 
 // GetText returns song lyrics paginates by verses.
 func (s *Service) GetText(ctx context.Context, id string,
-	page, size int) (models.ResponseGetSongText, error) {
+	page, size int) (_ models.ResponseGetSongText, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetText")
+	defer func() { tracing.End(span, err) }()
 	d, err := s.s.GetText(ctx, id, page, size)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -109,15 +378,53 @@ func (s *Service) GetText(ctx context.Context, id string,
 	return d, nil
 }
 
-// GetSongs filters, paginates and returns library songs.
-func (s *Service) GetSongs(ctx context.Context, d models.Song,
-	page, size int) (models.ResponseGetSongs, error) {
-	dd, err := s.s.GetSongs(ctx, d, page, size)
+// GetSongs filters, paginates and returns library songs, exact group
+// filter matches songs of artist having group as name or alias.
+func (s *Service) GetSongs(ctx context.Context,
+	r models.RequestGetSongs) (_ models.ResponseGetSongs, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetSongs")
+	defer func() { tracing.End(span, err) }()
+	if len(r.Filter.Group) > 0 && cmp.Or(r.Match.Group, models.MatchExact) == models.MatchExact {
+		a, err := s.resolve(ctx, r.Filter.Group)
+		if err != nil {
+			return models.ResponseGetSongs{}, status.Error(codes.Internal, "internal")
+		}
+		if len(a.ID) > 0 {
+			r.Filter.Group, r.Filter.ArtistID = ``, a.ID
+		}
+	}
+	dd, err := s.s.GetSongs(ctx, r)
 	if err != nil {
 		return dd, status.Error(codes.Internal, "internal")
 	}
 	return dd, nil
 }
 
+// Suggest returns songs with group or song names similar to query.
+func (s *Service) Suggest(ctx context.Context, q string, limit int) (_ models.ResponseSuggest, err error) {
+	ctx, span := tracing.Start(ctx, "Service.Suggest")
+	defer func() { tracing.End(span, err) }()
+	dd, err := s.s.Suggest(ctx, q, limit)
+	if err != nil {
+		return models.ResponseSuggest{}, status.Error(codes.Internal, "internal")
+	}
+	return models.ResponseSuggest{Suggestions: dd}, nil
+}
+
+// Search finds songs by full text query.
+func (s *Service) Search(ctx context.Context,
+	r models.RequestSearch) (_ models.ResponseSearch, err error) {
+	ctx, span := tracing.Start(ctx, "Service.Search")
+	defer func() { tracing.End(span, err) }()
+	d, err := s.s.Search(ctx, r)
+	if err != nil {
+		if err == storage.ErrNotSupported {
+			return d, status.Error(codes.Unimplemented, "not supported by storage")
+		}
+		return d, status.Error(codes.Internal, "internal")
+	}
+	return d, nil
+}
+
 // Ping checks storage availability.
 func (s *Service) Ping() error { return s.s.Ping() }
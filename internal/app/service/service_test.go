@@ -12,6 +12,13 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/xEgorka/project4/internal/app/config"
 	"github.com/xEgorka/project4/internal/app/mocks"
@@ -71,7 +78,11 @@ func TestAdd(t *testing.T) {
 		Song:        song.Song,
 		ReleaseDate: releaseDate,
 		Text:        d.Text,
-		Link:        d.Link}
+		Link:        d.Link,
+		ArtistID:    "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"}
+	in := ss
+	in.ArtistID = "" // missing artist is created by storage
+	ms.EXPECT().ResolveArtist(gomock.Any(), "Muse").Return(models.Artist{}, sql.ErrNoRows).AnyTimes()
 	r := args{ctx: context.Background(), song: song}
 	tests := []struct {
 		name    string
@@ -98,8 +109,8 @@ func TestAdd(t *testing.T) {
 				defer func() { srv.Close() }()
 				cfg := &config.Config{MusicInfoURL: srv.URL}
 				s := New(cfg, ms, requests.New(cfg))
-				ms.EXPECT().Add(tt.args.ctx, ss).Return(ss, nil)
-				s.Add(tt.args.ctx, tt.args.song)
+				ms.EXPECT().Add(tt.args.ctx, in).Return(ss, nil)
+				s.Add(tt.args.ctx, tt.args.song, true)
 			}
 			if tt.name == "negative test #1" {
 				srv := httptest.NewServer(http.HandlerFunc(
@@ -110,7 +121,7 @@ func TestAdd(t *testing.T) {
 				defer func() { srv.Close() }()
 				cfg := &config.Config{MusicInfoURL: srv.URL}
 				s := New(cfg, ms, requests.New(cfg))
-				if _, err := s.Add(tt.args.ctx, tt.args.song); (err != nil) != tt.wantErr {
+				if _, err := s.Add(tt.args.ctx, tt.args.song, true); (err != nil) != tt.wantErr {
 					t.Errorf("HTTP.GetSongDetail() error = %v, wantErr %v", err, tt.wantErr)
 				}
 			}
@@ -124,8 +135,8 @@ func TestAdd(t *testing.T) {
 				defer func() { srv.Close() }()
 				cfg := &config.Config{MusicInfoURL: srv.URL}
 				s := New(cfg, ms, requests.New(cfg))
-				ms.EXPECT().Add(tt.args.ctx, ss).Return(ss, storage.ErrUniqueViolation)
-				s.Add(tt.args.ctx, tt.args.song)
+				ms.EXPECT().Add(tt.args.ctx, in).Return(ss, storage.ErrUniqueViolation)
+				s.Add(tt.args.ctx, tt.args.song, true)
 			}
 			if tt.name == "negative test #3" {
 				srv := httptest.NewServer(http.HandlerFunc(
@@ -137,8 +148,8 @@ func TestAdd(t *testing.T) {
 				defer func() { srv.Close() }()
 				cfg := &config.Config{MusicInfoURL: srv.URL}
 				s := New(cfg, ms, requests.New(cfg))
-				ms.EXPECT().Add(tt.args.ctx, ss).Return(ss, sql.ErrNoRows)
-				s.Add(tt.args.ctx, tt.args.song)
+				ms.EXPECT().Add(tt.args.ctx, in).Return(ss, sql.ErrNoRows)
+				s.Add(tt.args.ctx, tt.args.song, true)
 			}
 			if tt.name == "negative test #4" {
 				srv := httptest.NewServer(http.HandlerFunc(
@@ -150,8 +161,8 @@ func TestAdd(t *testing.T) {
 				defer func() { srv.Close() }()
 				cfg := &config.Config{MusicInfoURL: srv.URL}
 				s := New(cfg, ms, requests.New(cfg))
-				ms.EXPECT().Add(tt.args.ctx, ss).Return(ss, errors.New("test"))
-				s.Add(tt.args.ctx, tt.args.song)
+				ms.EXPECT().Add(tt.args.ctx, in).Return(ss, errors.New("test"))
+				s.Add(tt.args.ctx, tt.args.song, true)
 			}
 			if tt.name == "negative test #5" {
 				srv := httptest.NewServer(http.HandlerFunc(
@@ -164,7 +175,39 @@ func TestAdd(t *testing.T) {
 				defer func() { srv.Close() }()
 				cfg := &config.Config{MusicInfoURL: srv.URL}
 				s := New(cfg, ms, requests.New(cfg))
-				s.Add(tt.args.ctx, tt.args.song)
+				s.Add(tt.args.ctx, tt.args.song, true)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	song := models.Song{ID: id, Group: "Muse", Song: "Hysteria", Version: 1}
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		song     models.Song
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", song: song, wantCode: codes.OK},
+		{name: "negative test #1", err: sql.ErrNoRows, wantCode: codes.NotFound},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().Get(gomock.Any(), id).Return(tt.song, tt.err)
+			got, err := s.Get(context.Background(), id)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("Service.Get() code = %v, want %v", code, tt.wantCode)
+			}
+			if !reflect.DeepEqual(got, tt.song) {
+				t.Errorf("Service.Get() = %v, want %v", got, tt.song)
 			}
 		})
 	}
@@ -208,22 +251,53 @@ func TestUpdate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.name == "positive test #1" {
-				ms.EXPECT().Update(tt.args.ctx, tt.args.id, tt.args.d).Return(nil)
-				s.Update(tt.args.ctx, tt.args.id, tt.args.d)
+				ms.EXPECT().Update(tt.args.ctx, tt.args.id, 0, tt.args.d).Return(nil)
+				s.Update(tt.args.ctx, tt.args.id, 0, tt.args.d)
 			}
 			if tt.name == "negative test #1" {
-				ms.EXPECT().Update(tt.args.ctx, tt.args.id, tt.args.d).Return(storage.ErrNotAffected)
-				s.Update(tt.args.ctx, tt.args.id, tt.args.d)
+				ms.EXPECT().Update(tt.args.ctx, tt.args.id, 0, tt.args.d).Return(storage.ErrNotAffected)
+				s.Update(tt.args.ctx, tt.args.id, 0, tt.args.d)
 			}
 			if tt.name == "negative test #2" {
-				ms.EXPECT().Update(tt.args.ctx, tt.args.id, tt.args.d).Return(errors.New("test"))
-				s.Update(tt.args.ctx, tt.args.id, tt.args.d)
+				ms.EXPECT().Update(tt.args.ctx, tt.args.id, 0, tt.args.d).Return(errors.New("test"))
+				s.Update(tt.args.ctx, tt.args.id, 0, tt.args.d)
 			}
 
 		})
 	}
 }
 
+func TestPatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	link := "https://youtu.be/3dm_5qWWDV8"
+	d := models.RequestPatchSong{Link: &link}
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrNotAffected, wantCode: codes.NotFound},
+		{name: "negative test #2", err: storage.ErrUniqueViolation, wantCode: codes.AlreadyExists},
+		{name: "negative test #3", err: errors.New("test"), wantCode: codes.Internal},
+		{name: "negative test #4", err: storage.ErrVersionMismatch, wantCode: codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().Patch(gomock.Any(), id, 0, d).Return(tt.err)
+			err := s.Patch(context.Background(), id, 0, d)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("Service.Patch() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -253,18 +327,360 @@ func TestDelete(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.name == "positive test #1" {
-				ms.EXPECT().Delete(tt.args.ctx, tt.args.id).Return(nil)
-				s.Delete(tt.args.ctx, tt.args.id)
+				ms.EXPECT().Delete(tt.args.ctx, tt.args.id, 0).Return(nil)
+				s.Delete(tt.args.ctx, tt.args.id, 0)
 			}
 			if tt.name == "negative test #1" {
-				ms.EXPECT().Delete(tt.args.ctx, tt.args.id).Return(storage.ErrNotAffected)
-				s.Delete(tt.args.ctx, tt.args.id)
+				ms.EXPECT().Delete(tt.args.ctx, tt.args.id, 0).Return(storage.ErrNotAffected)
+				s.Delete(tt.args.ctx, tt.args.id, 0)
 			}
 			if tt.name == "negative test #2" {
-				ms.EXPECT().Delete(tt.args.ctx, tt.args.id).Return(errors.New("test"))
-				s.Delete(tt.args.ctx, tt.args.id)
+				ms.EXPECT().Delete(tt.args.ctx, tt.args.id, 0).Return(errors.New("test"))
+				s.Delete(tt.args.ctx, tt.args.id, 0)
+			}
+
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	type args struct {
+		ctx context.Context
+		id  string
+	}
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	r := args{ctx: context.Background(), id: id}
+	tests := []struct {
+		name    string
+		args    args
+		err     error
+		wantErr bool
+	}{
+		{name: "positive test #1", args: r},
+		{name: "negative test #1", args: r, err: storage.ErrNotAffected, wantErr: true},
+		{name: "negative test #2", args: r, err: errors.New("test"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().Restore(tt.args.ctx, tt.args.id).Return(tt.err)
+			if err := s.Restore(tt.args.ctx, tt.args.id); (err != nil) != tt.wantErr {
+				t.Errorf("Service.Restore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	type args struct {
+		ctx context.Context
+		id  string
+	}
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	r := args{ctx: context.Background(), id: id}
+	tests := []struct {
+		name    string
+		args    args
+		err     error
+		wantErr bool
+	}{
+		{name: "positive test #1", args: r},
+		{name: "negative test #1", args: r, err: storage.ErrNotAffected, wantErr: true},
+		{name: "negative test #2", args: r, err: errors.New("test"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().Purge(tt.args.ctx, tt.args.id, 0).Return(tt.err)
+			if err := s.Purge(tt.args.ctx, tt.args.id, 0); (err != nil) != tt.wantErr {
+				t.Errorf("Service.Purge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{RetentionDays: 30}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name    string
+		n       int64
+		err     error
+		want    int64
+		wantErr bool
+	}{
+		{name: "positive test #1", n: 2, want: 2},
+		{name: "negative test #1", err: errors.New("test"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ms.EXPECT().PurgeDeleted(ctx, gomock.Any()).DoAndReturn(
+				func(ctx context.Context, before time.Time) (int64, error) {
+					if d := time.Since(before); d < 30*24*time.Hour-time.Hour || d > 30*24*time.Hour+time.Hour {
+						t.Errorf("Service.PurgeDeleted() before = %v", before)
+					}
+					return tt.n, tt.err
+				})
+			got, err := s.PurgeDeleted(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.PurgeDeleted() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Service.PurgeDeleted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	tests := []struct {
+		name string
+		days int
+	}{
+		{name: "positive test #1", days: 1},
+		{name: "positive test #2", days: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{RetentionDays: tt.days}
+			s := New(cfg, ms, requests.New(cfg))
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.days > 0 {
+				ms.EXPECT().PurgeDeleted(ctx, gomock.Any()).DoAndReturn(
+					func(context.Context, time.Time) (int64, error) {
+						cancel()
+						return 1, nil
+					})
+			}
+			done := make(chan struct{})
+			go func() {
+				s.Retain(ctx, time.Hour)
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Errorf("Service.Retain() did not stop")
+			}
+			cancel()
+		})
+	}
+}
+
+func TestGetRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	revs := []models.Revision{{Rev: 1, Group: "Muse", Song: "Hysteria", Action: models.ActionUpdate}}
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name    string
+		revs    []models.Revision
+		err     error
+		want    models.ResponseGetRevisions
+		wantErr bool
+	}{
+		{name: "positive test #1", revs: revs, want: models.ResponseGetRevisions{ID: id, Revisions: revs}},
+		{name: "negative test #1", err: sql.ErrNoRows, wantErr: true},
+		{name: "negative test #2", err: errors.New("test"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().GetRevisions(gomock.Any(), id).Return(tt.revs, tt.err)
+			got, err := s.GetRevisions(context.Background(), id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.GetRevisions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Service.GetRevisions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	rev := models.Revision{Rev: 1, Group: "Muse", Song: "Hysteria", Action: models.ActionUpdate}
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name    string
+		rev     models.Revision
+		err     error
+		wantErr bool
+	}{
+		{name: "positive test #1", rev: rev},
+		{name: "negative test #1", err: sql.ErrNoRows, wantErr: true},
+		{name: "negative test #2", err: errors.New("test"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(tt.rev, tt.err)
+			got, err := s.GetRevision(context.Background(), id, 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.GetRevision() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.rev) {
+				t.Errorf("Service.GetRevision() = %v, want %v", got, tt.rev)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	current := models.RequestGetSongs{
+		Filter: models.Song{ID: id}, Deleted: models.DeletedInclude, Page: 1, Size: 1}
+	tests := []struct {
+		name    string
+		to      int
+		prepare func()
+		want    models.ResponseGetDiff
+		wantErr bool
+	}{
+		{
+			name: "positive test #1",
+			to:   2,
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{Text: "a\nb"}, nil)
+				ms.EXPECT().GetRevision(gomock.Any(), id, 2).Return(models.Revision{Text: "a\nc"}, nil)
+			},
+			want: models.ResponseGetDiff{ID: id, From: 1, To: 2, Lines: []models.DiffLine{
+				{Op: models.DiffEqual, Text: "a"},
+				{Op: models.DiffDelete, Text: "b"},
+				{Op: models.DiffInsert, Text: "c"}}},
+		},
+		{
+			name: "positive test #2",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{Text: "a"}, nil)
+				ms.EXPECT().GetSongs(gomock.Any(), current).Return(models.ResponseGetSongs{
+					Songs: []models.Song{{ID: id, Text: "a"}}}, nil)
+			},
+			want: models.ResponseGetDiff{ID: id, From: 1, Lines: []models.DiffLine{
+				{Op: models.DiffEqual, Text: "a"}}},
+		},
+		{
+			name: "negative test #1",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{}, sql.ErrNoRows)
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative test #2",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{Text: "a"}, nil)
+				ms.EXPECT().GetSongs(gomock.Any(), current).Return(models.ResponseGetSongs{}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative test #3",
+			to:   2,
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{Text: "a"}, nil)
+				ms.EXPECT().GetRevision(gomock.Any(), id, 2).Return(models.Revision{}, errors.New("test"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			got, err := s.Diff(context.Background(), id, 1, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.Diff() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Service.Diff() = %v, want %v", got, tt.want)
 			}
+		})
+	}
+}
 
+func TestRevert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	rev := models.Revision{Rev: 1, Group: "Muse", Song: "Hysteria", Text: "It's bugging me",
+		Link: "https://youtu.be/3dm_5qWWDV8", ReleaseDate: time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC)}
+	d := models.RequestPatchSong{Group: &rev.Group, Song: &rev.Song,
+		ReleaseDate: &rev.ReleaseDate, Text: &rev.Text, Link: &rev.Link}
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name    string
+		prepare func()
+		wantErr bool
+	}{
+		{
+			name: "positive test #1",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(rev, nil)
+				ms.EXPECT().Patch(gomock.Any(), id, 0, d).Return(nil)
+			},
+		},
+		{
+			name: "negative test #1",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(models.Revision{}, sql.ErrNoRows)
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative test #2",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(rev, nil)
+				ms.EXPECT().Patch(gomock.Any(), id, 0, d).Return(storage.ErrNotAffected)
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative test #3",
+			prepare: func() {
+				ms.EXPECT().GetRevision(gomock.Any(), id, 1).Return(rev, nil)
+				ms.EXPECT().Patch(gomock.Any(), id, 0, d).Return(storage.ErrUniqueViolation)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepare()
+			if err := s.Revert(context.Background(), id, 1); (err != nil) != tt.wantErr {
+				t.Errorf("Service.Revert() error = %v, wantErr %v", err, tt.wantErr)
+			}
 		})
 	}
 }
@@ -329,10 +745,12 @@ func TestSongs(t *testing.T) {
 	}
 	type args struct {
 		ctx context.Context
-		req models.Song
+		req models.RequestGetSongs
 	}
-	req := models.Song{
-		ID: "0824f9fb-7397-4f19-95d5-f9ce8bec75de",
+	req := models.RequestGetSongs{
+		Filter: models.Song{ID: "0824f9fb-7397-4f19-95d5-f9ce8bec75de"},
+		Page:   DefaultPage,
+		Size:   DefaultSizeSongs,
 	}
 	cfg := &config.Config{}
 	s := New(cfg, ms, requests.New(cfg))
@@ -349,20 +767,124 @@ func TestSongs(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.name == "positive test #1" {
-				ms.EXPECT().GetSongs(tt.args.ctx, tt.args.req, DefaultPage, DefaultSizeSongs).
+				ms.EXPECT().GetSongs(tt.args.ctx, tt.args.req).
 					Return(models.ResponseGetSongs{}, nil)
-				s.GetSongs(tt.args.ctx, tt.args.req, DefaultPage, DefaultSizeSongs)
+				s.GetSongs(tt.args.ctx, tt.args.req)
 			}
 			if tt.name == "negative test #1" {
-				ms.EXPECT().GetSongs(tt.args.ctx, tt.args.req, DefaultPage, DefaultSizeSongs).
+				ms.EXPECT().GetSongs(tt.args.ctx, tt.args.req).
 					Return(models.ResponseGetSongs{}, errors.New("test"))
-				s.GetSongs(tt.args.ctx, tt.args.req, DefaultPage, DefaultSizeSongs)
+				s.GetSongs(tt.args.ctx, tt.args.req)
 			}
 
 		})
 	}
 }
 
+func TestSearch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	r := models.RequestSearch{Query: "soul", Lang: models.LangEnglish, Page: 1, Size: 10}
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrNotSupported, wantCode: codes.Unimplemented},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().Search(gomock.Any(), r).Return(models.ResponseSearch{}, tt.err)
+			_, err := s.Search(context.Background(), r)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("Service.Search() code = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().Suggest(gomock.Any(), "Supermasive", 10).Return(nil, tt.err)
+			_, err := s.Suggest(context.Background(), "Supermasive", 10)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("Service.Suggest() code = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAdd_nearDuplicates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	r := models.RequestAddSong{Group: "Muse", Song: "Supermasive Black Hole"}
+	ms.EXPECT().ResolveArtist(gomock.Any(), "Muse").Return(models.Artist{}, sql.ErrNoRows).AnyTimes()
+	tests := []struct {
+		name     string
+		found    []models.Suggestion
+		err      error
+		wantNear int
+		wantCode codes.Code
+	}{
+		{
+			name: "positive test #1",
+			found: []models.Suggestion{
+				{ID: "1", Group: "Muse", Song: "Supermassive Black Hole", Score: 0.7},
+				{ID: "2", Group: "Muse", Song: "Hysteria", Score: 0.3},
+			},
+			wantNear: 1,
+		},
+		{
+			name:     "positive test #2",
+			found:    []models.Suggestion{{ID: "1", Group: "Muse", Song: "Supermasive Black Hole", Score: 1}},
+			wantCode: codes.Internal, // exact duplicate reaches music info service
+
+		},
+		{name: "negative test #1", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().Suggest(gomock.Any(), "Muse Supermasive Black Hole", DefaultSizeSuggest).Return(tt.found, tt.err)
+			_, err := s.Add(context.Background(), r, false)
+			var near *NearDuplicatesError
+			if errors.As(err, &near) {
+				if len(near.Songs) != tt.wantNear {
+					t.Errorf("Service.Add() near duplicates = %v, want %v", len(near.Songs), tt.wantNear)
+				}
+				return
+			}
+			if tt.wantNear > 0 {
+				t.Errorf("Service.Add() error = %v, want near duplicates", err)
+			}
+			if code := status.Code(err); code != tt.wantCode {
+				t.Errorf("Service.Add() code = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+}
+
 func TestService_Ping(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -401,3 +923,40 @@ func TestService_Ping(t *testing.T) {
 		})
 	}
 }
+
+func TestService_spans(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	tests := []struct {
+		name string
+		err  error
+		want otelcodes.Code
+	}{
+		{name: "positive test #1", want: otelcodes.Unset},
+		{name: "negative test #1", err: errors.New("test"), want: otelcodes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().Delete(gomock.Any(), id, 0).Return(tt.err)
+			err := s.Delete(context.Background(), id, 0)
+			spans := sr.Ended()
+			if len(spans) == 0 {
+				t.Fatal("no spans ended")
+			}
+			span := spans[len(spans)-1]
+			if span.Name() != "Service.Delete" || span.Status().Code != tt.want {
+				t.Errorf("span %s status = %v, want %v", span.Name(), span.Status().Code, tt.want)
+			}
+			if err != nil && span.Status().Description != err.Error() {
+				t.Errorf("span status description = %q, want %q", span.Status().Description, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"cmp"
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/storage"
+	"github.com/xEgorka/project4/internal/app/tracing"
+)
+
+// AddTags tags song, missing tags are created of request kind, custom
+// by default.
+func (s *Service) AddTags(ctx context.Context, id string, r models.RequestSongTags) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.AddTags")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.AddTags(ctx, id, cmp.Or(r.Kind, models.TagCustom), r.Tags); err != nil {
+		if err == storage.ErrNotAffected {
+			return status.Error(codes.NotFound, "not found")
+		}
+		logger.Log.Info("failed add tags", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// RemoveTags untags song.
+func (s *Service) RemoveTags(ctx context.Context, id string, r models.RequestSongTags) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.RemoveTags")
+	defer func() { tracing.End(span, err) }()
+	if err := s.s.RemoveTags(ctx, id, r.Tags); err != nil {
+		if err == storage.ErrNotAffected {
+			return status.Error(codes.NotFound, "not found")
+		}
+		logger.Log.Info("failed remove tags", zap.Error(err))
+		return status.Error(codes.Internal, "internal")
+	}
+	return nil
+}
+
+// GetTags returns tags of kind with song counts, empty kind lists tags
+// of all kinds.
+func (s *Service) GetTags(ctx context.Context, kind string) (_ models.ResponseGetTags, err error) {
+	ctx, span := tracing.Start(ctx, "Service.GetTags")
+	defer func() { tracing.End(span, err) }()
+	tt, err := s.s.GetTags(ctx, kind)
+	if err != nil {
+		return models.ResponseGetTags{}, status.Error(codes.Internal, "internal")
+	}
+	return models.ResponseGetTags{Tags: tt}, nil
+}
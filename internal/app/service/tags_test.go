@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/mocks"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/storage"
+)
+
+func TestAddTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		kind     string
+		wantKind string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantKind: models.TagCustom, wantCode: codes.OK},
+		{name: "positive test #2", kind: models.TagGenre, wantKind: models.TagGenre, wantCode: codes.OK},
+		{name: "negative test #1", wantKind: models.TagCustom, err: storage.ErrNotAffected, wantCode: codes.NotFound},
+		{name: "negative test #2", wantKind: models.TagCustom, err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().AddTags(gomock.Any(), "1", tt.wantKind, []string{"rock"}).Return(tt.err)
+			err := s.AddTags(context.Background(), "1", models.RequestSongTags{Tags: []string{"rock"}, Kind: tt.kind})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.AddTags() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRemoveTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ms := mocks.NewMockStorage(ctrl)
+	cfg := &config.Config{}
+	s := New(cfg, ms, requests.New(cfg))
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{name: "positive test #1", wantCode: codes.OK},
+		{name: "negative test #1", err: storage.ErrNotAffected, wantCode: codes.NotFound},
+		{name: "negative test #2", err: errors.New("test"), wantCode: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms.EXPECT().RemoveTags(gomock.Any(), "1", []string{"rock"}).Return(tt.err)
+			err := s.RemoveTags(context.Background(), "1", models.RequestSongTags{Tags: []string{"rock"}})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("Service.RemoveTags() code = %v, want %v", status.Code(err), tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/jwks"
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/roles"
+	"github.com/xEgorka/project4/internal/app/tracing"
+)
+
+// tokenMethods are accepted bearer token signing algorithms.
+var tokenMethods = []string{"RS256", "ES256", "HS256"}
+
+// TokenLeeway is clock skew allowed checking bearer token exp and nbf.
+const TokenLeeway = 30 * time.Second
+
+// tokenRole returns most privileged role of role claim holding space
+// separated roles or roles array.
+func tokenRole(v any) string {
+	switch v := v.(type) {
+	case string:
+		return roles.Highest(strings.Fields(v)...)
+	case []any:
+		rr := make([]string, 0, len(v))
+		for _, r := range v {
+			if r, ok := r.(string); ok {
+				rr = append(rr, r)
+			}
+		}
+		return roles.Highest(rr...)
+	}
+	return ""
+}
+
+// AuthenticateToken returns caller identified by subject of bearer token
+// verified by JWKS, caller role is taken from role claim.
+func (s *Service) AuthenticateToken(ctx context.Context, token string) (_ models.Caller, err error) {
+	ctx, span := tracing.Start(ctx, "Service.AuthenticateToken")
+	defer func() { tracing.End(span, err) }()
+	if s.keys == nil {
+		return models.Caller{}, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(tokenMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(TokenLeeway),
+	}
+	if len(s.cfg.JWTAudience) > 0 {
+		opts = append(opts, jwt.WithAudience(s.cfg.JWTAudience))
+	}
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return s.keys.Key(ctx, kid, t.Method.Alg())
+	}, opts...)
+	if errors.Is(err, jwks.ErrUnavailable) {
+		logger.Log.Info("failed load jwks", zap.Error(err))
+		return models.Caller{}, status.Error(codes.Internal, "internal")
+	} else if err != nil {
+		logger.Log.Info("invalid token", zap.Error(err))
+		return models.Caller{}, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	sub, err := claims.GetSubject()
+	if err != nil || len(sub) == 0 {
+		logger.Log.Info("token without subject")
+		return models.Caller{}, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	return models.Caller{ID: sub, Name: sub, Role: tokenRole(claims[s.cfg.JWTRoleClaim])}, nil
+}
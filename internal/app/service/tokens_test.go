@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/requests"
+	"github.com/xEgorka/project4/internal/app/roles"
+)
+
+func TestAuthenticateToken(t *testing.T) {
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	b64 := base64.RawURLEncoding.EncodeToString
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"keys":[
+{"kty":"RSA","kid":"r1","n":%q,"e":%q},
+{"kty":"EC","kid":"e1","crv":"P-256","x":%q,"y":%q},
+{"kty":"oct","kid":"h1","k":%q}]}`,
+			b64(rk.N.Bytes()), b64(big.NewInt(int64(rk.E)).Bytes()),
+			b64(ek.X.FillBytes(make([]byte, 32))), b64(ek.Y.FillBytes(make([]byte, 32))), b64(secret))
+	}))
+	defer srv.Close()
+	cfg := &config.Config{JWKSURL: srv.URL, JWTAudience: "songs", JWTRoleClaim: "role", JWKSRefresh: time.Hour}
+	s := New(cfg, nil, requests.New(cfg))
+
+	now := time.Now()
+	claims := func(role any) jwt.MapClaims {
+		return jwt.MapClaims{"sub": "alice", "aud": "songs", "exp": now.Add(time.Minute).Unix(), "role": role}
+	}
+	sign := func(m jwt.SigningMethod, kid string, key any, c jwt.MapClaims) string {
+		tok := jwt.NewWithClaims(m, c)
+		tok.Header["kid"] = kid
+		v, err := tok.SignedString(key)
+		require.NoError(t, err)
+		return v
+	}
+	with := func(c jwt.MapClaims, k string, v any) jwt.MapClaims {
+		c[k] = v
+		return c
+	}
+	without := func(c jwt.MapClaims, k string) jwt.MapClaims {
+		delete(c, k)
+		return c
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims(roles.Admin)).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		token    string
+		want     models.Caller
+		wantCode codes.Code
+	}{
+		{
+			name:  "positive test #1",
+			token: sign(jwt.SigningMethodRS256, "r1", rk, claims(roles.Editor)),
+			want:  models.Caller{ID: "alice", Name: "alice", Role: roles.Editor},
+		},
+		{
+			name:  "positive test #2",
+			token: sign(jwt.SigningMethodES256, "e1", ek, claims([]any{roles.Reader, roles.Admin})),
+			want:  models.Caller{ID: "alice", Name: "alice", Role: roles.Admin},
+		},
+		{
+			name:  "positive test #3",
+			token: sign(jwt.SigningMethodHS256, "h1", secret, claims("guest reader")),
+			want:  models.Caller{ID: "alice", Name: "alice", Role: roles.Reader},
+		},
+		{
+			name:  "positive test #4",
+			token: sign(jwt.SigningMethodRS256, "r1", rk, without(claims(nil), "role")),
+			want:  models.Caller{ID: "alice", Name: "alice"},
+		},
+		{
+			name:     "negative test #1",
+			token:    sign(jwt.SigningMethodRS256, "r1", rk, with(claims(roles.Admin), "exp", now.Add(-time.Hour).Unix())),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "negative test #2",
+			token:    sign(jwt.SigningMethodRS256, "r1", rk, with(claims(roles.Admin), "nbf", now.Add(time.Hour).Unix())),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "negative test #3",
+			token:    sign(jwt.SigningMethodRS256, "r1", rk, with(claims(roles.Admin), "aud", "other")),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "negative test #4",
+			token:    sign(jwt.SigningMethodRS256, "r1", rk, without(claims(roles.Admin), "exp")),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "negative test #5",
+			token:    sign(jwt.SigningMethodRS256, "missing", rk, claims(roles.Admin)),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "negative test #6",
+			token:    sign(jwt.SigningMethodHS256, "r1", secret, claims(roles.Admin)),
+			wantCode: codes.Unauthenticated,
+		},
+		{name: "negative test #7", token: unsigned, wantCode: codes.Unauthenticated},
+		{
+			name:     "negative test #8",
+			token:    sign(jwt.SigningMethodRS256, "r1", rk, without(claims(roles.Admin), "sub")),
+			wantCode: codes.Unauthenticated,
+		},
+		{name: "negative test #9", token: "not a token", wantCode: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.AuthenticateToken(context.Background(), tt.token)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	token := sign(jwt.SigningMethodRS256, "r1", rk, claims(roles.Admin))
+	_, err = New(&config.Config{}, nil, requests.New(cfg)).AuthenticateToken(context.Background(), token)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	srv.Close()
+	down := &config.Config{JWKSURL: srv.URL, JWTRoleClaim: "role", JWKSRefresh: time.Hour}
+	_, err = New(down, nil, requests.New(down)).AuthenticateToken(context.Background(), token)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
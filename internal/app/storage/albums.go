@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// Postgres errors of album unique constraints violation.
+var (
+	errAlbumViolation = errors.New(`ERROR: duplicate key value violates unique constraint "albums_idx" (SQLSTATE 23505)`)
+	errTrackViolation = errors.New(`ERROR: duplicate key value violates unique constraint "songs_track_idx" (SQLSTATE 23505)`)
+)
+
+// albumColumns lists album columns read by scanAlbum.
+const albumColumns = `al.id, al.title, al.artist_id, a.name, al.release_date, al.cover_link, al.created_at, al.updated_at`
+
+// scanAlbum reads album from query result row.
+func scanAlbum(row interface{ Scan(dest ...any) error }) (models.Album, error) {
+	var d models.Album
+	var releaseDate sql.NullTime
+	if err := row.Scan(&d.ID, &d.Title, &d.ArtistID, &d.Artist, &releaseDate, &d.CoverLink,
+		&d.CreatedAt, &d.UpdatedAt); err != nil {
+		return models.Album{}, err
+	}
+	d.ReleaseDate = nullTime(releaseDate)
+	d.CreatedAt, d.UpdatedAt = d.CreatedAt.UTC(), d.UpdatedAt.UTC()
+	return d, nil
+}
+
+// queryAlbums runs albums query and reads albums.
+func queryAlbums(ctx context.Context, conn *sql.DB, q string, args ...any) ([]models.Album, error) {
+	rows, err := conn.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	res := make([]models.Album, 0)
+	for rows.Next() {
+		d, err := scanAlbum(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+// querySongs runs songs query and reads songs with scan.
+func querySongs(ctx context.Context, conn *sql.DB, scan func(row interface{ Scan(dest ...any) error }) (models.Song, error),
+	q string, args ...any) ([]models.Song, error) {
+	rows, err := conn.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	res := make([]models.Song, 0)
+	for rows.Next() {
+		d, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+// nullDate converts nil date to NULL.
+func nullDate(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: date(*t), Valid: true}
+}
+
+const (
+	queryInsertAlbum = `
+insert into albums (id, title, artist_id, release_date, cover_link, created_at, updated_at)
+values ($1, $2, $3, $4, $5, $6, $6)
+`
+	querySelectAlbum = `
+select ` + albumColumns + ` from albums al join artists a on a.id=al.artist_id where al.id=$1
+`
+	querySelectAlbums = `
+select ` + albumColumns + ` from albums al join artists a on a.id=al.artist_id
+where ($1='' or al.artist_id=$1) order by al.title, al.id limit $2 offset $3
+`
+	queryCountAlbums  = `select count(*) from albums where ($1='' or artist_id=$1)`
+	querySelectTracks = `
+select ` + songColumns + ` from songs where album_id=$1 and deleted=False order by track
+`
+	queryAttachTrack = `
+update songs set album_id=$2, track=$3, version=version+1, updated_at=now()
+where id=$1 and deleted=False and exists (select 1 from albums where id=$2)
+`
+)
+
+// AddAlbum creates album of artist, album title taken by other album of
+// artist returns ErrUniqueViolation.
+func (s *db) AddAlbum(ctx context.Context, d models.Album) (models.Album, error) {
+	d.ID = uuid.New().String()
+	d.CreatedAt = now()
+	d.UpdatedAt = d.CreatedAt
+	_, err := s.conn.ExecContext(ctx, queryInsertAlbum,
+		d.ID, d.Title, d.ArtistID, nullDate(d.ReleaseDate), d.CoverLink, d.CreatedAt)
+	if err != nil && err.Error() == errAlbumViolation.Error() {
+		return models.Album{}, ErrUniqueViolation
+	} else if err != nil {
+		return models.Album{}, err
+	}
+	return s.GetAlbum(ctx, d.ID)
+}
+
+// GetAlbum returns album.
+func (s *db) GetAlbum(ctx context.Context, id string) (models.Album, error) {
+	return scanAlbum(s.conn.QueryRowContext(ctx, querySelectAlbum, id))
+}
+
+// GetAlbums returns albums page ordered by title, empty artist id lists
+// albums of all artists.
+func (s *db) GetAlbums(ctx context.Context, artistID string,
+	page, size int) (models.ResponseGetAlbums, error) {
+	res := models.ResponseGetAlbums{Page: page, Size: size}
+	if err := s.conn.QueryRowContext(ctx, queryCountAlbums, artistID).Scan(&res.Total); err != nil {
+		return models.ResponseGetAlbums{}, err
+	}
+	res.Pages = pages(res.Total, size)
+	var err error
+	if res.Albums, err = queryAlbums(ctx, s.conn, querySelectAlbums,
+		artistID, size, (page-1)*size); err != nil {
+		return models.ResponseGetAlbums{}, err
+	}
+	return res, nil
+}
+
+// GetTracks returns not deleted album songs ordered by track number.
+func (s *db) GetTracks(ctx context.Context, id string) ([]models.Song, error) {
+	return querySongs(ctx, s.conn, scanSong, querySelectTracks, id)
+}
+
+// AttachTrack places song on album position moving it from previous
+// album saving song state as revision, position taken by other song
+// returns ErrUniqueViolation.
+func (s *db) AttachTrack(ctx context.Context, id, songID string, track int) error {
+	err := transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, pgRevision, songID, models.ActionUpdate, 0, false); err != nil {
+			return err
+		}
+		return affected(tx.ExecContext(ctx, queryAttachTrack, songID, id, track))
+	})
+	if err != nil && err.Error() == errTrackViolation.Error() {
+		return ErrUniqueViolation
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+func Test_db_AddAlbum(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	released := time.Date(2006, 7, 3, 0, 0, 0, 0, time.UTC)
+	a := models.Album{Title: "Black Holes and Revelations", ArtistID: "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11",
+		ReleaseDate: &released}
+	columns := []string{"id", "title", "artist_id", "name", "release_date", "cover_link", "created_at", "updated_at"}
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "positive test #1"},
+		{name: "negative test #1", err: errAlbumViolation, wantErr: ErrUniqueViolation},
+		{name: "negative test #2", err: errors.New("test"), wantErr: errors.New("test")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := mock.ExpectExec(regexp.QuoteMeta(queryInsertAlbum)).
+				WithArgs(sqlmock.AnyArg(), a.Title, a.ArtistID, sql.NullTime{Time: released, Valid: true},
+					a.CoverLink, sqlmock.AnyArg())
+			if tt.err != nil {
+				q.WillReturnError(tt.err)
+			} else {
+				q.WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectAlbum)).WithArgs(sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("id", a.Title, a.ArtistID, "Muse",
+						released, "", released, released))
+			}
+			got, err := s.AddAlbum(context.Background(), a)
+			if (err != nil) != (tt.wantErr != nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("db.AddAlbum() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (got.Artist != "Muse" || !reflect.DeepEqual(got.ReleaseDate, a.ReleaseDate)) {
+				t.Errorf("db.AddAlbum() = %v", got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_db_GetAlbums(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	artistID := "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"
+	created := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "title", "artist_id", "name", "release_date", "cover_link", "created_at", "updated_at"}
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		want    models.ResponseGetAlbums
+		wantErr bool
+	}{
+		{
+			name: "positive test #1",
+			rows: sqlmock.NewRows(columns).AddRow("1", "Absolution", artistID, "Muse", nil, "", created, created),
+			want: models.ResponseGetAlbums{Albums: []models.Album{{ID: "1", Title: "Absolution", ArtistID: artistID,
+				Artist: "Muse", CreatedAt: created, UpdatedAt: created}}, Page: 2, Size: 1, Total: 2, Pages: 2},
+		},
+		{
+			name:    "negative test #1",
+			rows:    sqlmock.NewRows(columns).AddRow("1", "Absolution", artistID, "Muse", nil, "", "bad", created),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(queryCountAlbums)).WithArgs(artistID).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+			mock.ExpectQuery(regexp.QuoteMeta(querySelectAlbums)).WithArgs(artistID, 1, 1).WillReturnRows(tt.rows)
+			got, err := s.GetAlbums(context.Background(), artistID, 2, 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("db.GetAlbums() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("db.GetAlbums() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_db_AttachTrack(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	id, songID := "9c3f6f1e-8d2b-4b8a-a6f4-1b6c2d7e8f90", "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	tests := []struct {
+		name     string
+		affected int64
+		err      error
+		wantErr  error
+	}{
+		{name: "positive test #1", affected: 1},
+		{name: "negative test #1", wantErr: ErrNotAffected},
+		{name: "negative test #2", err: errTrackViolation, wantErr: ErrUniqueViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			expectRevise(mock, songID, models.ActionUpdate, 1, false)
+			q := mock.ExpectExec(regexp.QuoteMeta(queryAttachTrack)).WithArgs(songID, id, 3)
+			if tt.err != nil {
+				q.WillReturnError(tt.err)
+			} else {
+				q.WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			}
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+			if err := s.AttachTrack(context.Background(), id, songID, 3); err != tt.wantErr {
+				t.Errorf("db.AttachTrack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
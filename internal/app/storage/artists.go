@@ -0,0 +1,252 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// ErrArtistInUse indicates artist is referenced by songs.
+var ErrArtistInUse = errors.New(`artist in use`)
+
+// errArtistNameViolation is postgres error of artist name or alias taken
+// by other artist.
+var errArtistNameViolation = errors.New(`ERROR: duplicate key value violates unique constraint "artist_names_idx" (SQLSTATE 23505)`)
+
+// aliases returns artist aliases without canonical name and duplicates
+// differing by case only.
+func aliases(a models.Artist) []string {
+	res := make([]string, 0, len(a.Aliases))
+	seen := map[string]bool{strings.ToLower(a.Name): true}
+	for _, v := range a.Aliases {
+		if k := strings.ToLower(v); !seen[k] {
+			seen[k] = true
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// artistColumns lists artist columns read by scanArtists followed by
+// one of artist names.
+const artistColumns = `a.id, a.name, a.country, a.formed_year, a.created_at, a.updated_at, n.name`
+
+// scanArtists reads artists from rows of artist names ordered by artist.
+func scanArtists(rows *sql.Rows) ([]models.Artist, error) {
+	res := make([]models.Artist, 0)
+	for rows.Next() {
+		var a models.Artist
+		var name string
+		if err := rows.Scan(&a.ID, &a.Name, &a.Country, &a.Formed,
+			&a.CreatedAt, &a.UpdatedAt, &name); err != nil {
+			return nil, err
+		}
+		if len(res) == 0 || res[len(res)-1].ID != a.ID {
+			a.CreatedAt, a.UpdatedAt, a.Aliases = a.CreatedAt.UTC(), a.UpdatedAt.UTC(), []string{}
+			res = append(res, a)
+		}
+		if name != a.Name {
+			last := &res[len(res)-1]
+			last.Aliases = append(last.Aliases, name)
+		}
+	}
+	return res, rows.Err()
+}
+
+// queryArtists runs artist names query and reads artists.
+func queryArtists(ctx context.Context, conn *sql.DB, q string, args ...any) ([]models.Artist, error) {
+	rows, err := conn.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	return scanArtists(rows)
+}
+
+const (
+	queryInsertArtist = `
+insert into artists (id, name, country, formed_year, created_at, updated_at)
+values ($1, $2, $3, $4, $5, $5)
+`
+	queryInsertArtistName = `insert into artist_names (artist_id, name) values ($1, $2)`
+)
+
+// insertNames saves canonical name and aliases of artist.
+func insertNames(ctx context.Context, tx *sql.Tx, q string, a models.Artist) error {
+	for _, name := range append([]string{a.Name}, a.Aliases...) {
+		if _, err := tx.ExecContext(ctx, q, a.ID, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddArtist creates artist, name or alias taken by other artist returns
+// ErrUniqueViolation.
+func (s *db) AddArtist(ctx context.Context, a models.Artist) (models.Artist, error) {
+	a.ID, a.Aliases = uuid.New().String(), aliases(a)
+	a.CreatedAt = now()
+	a.UpdatedAt = a.CreatedAt
+	err := transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, queryInsertArtist,
+			a.ID, a.Name, a.Country, a.Formed, a.CreatedAt); err != nil {
+			return err
+		}
+		return insertNames(ctx, tx, queryInsertArtistName, a)
+	})
+	if err != nil && err.Error() == errArtistNameViolation.Error() {
+		return models.Artist{}, ErrUniqueViolation
+	} else if err != nil {
+		return models.Artist{}, err
+	}
+	return a, nil
+}
+
+// songArtistQueries holds dialect queries resolving song group to artist.
+type songArtistQueries struct {
+	lock       string // serializes resolving of name, args name, empty skips lock
+	resolve    string // returns artist id and canonical name, args name
+	insert     string // creates artist, args id, name, country, formed, time
+	insertName string // saves artist name, args artist id, name
+}
+
+// songArtist resolves group to artist creating missing one in song
+// transaction, so new artist is kept only if song change is committed.
+func songArtist(ctx context.Context, tx *sql.Tx, q songArtistQueries, group string) (models.Artist, error) {
+	if len(q.lock) > 0 {
+		if _, err := tx.ExecContext(ctx, q.lock, group); err != nil {
+			return models.Artist{}, err
+		}
+	}
+	var a models.Artist
+	if err := tx.QueryRowContext(ctx, q.resolve, group).Scan(&a.ID, &a.Name); !errors.Is(err, sql.ErrNoRows) {
+		return a, err
+	}
+	a = models.Artist{ID: uuid.New().String(), Name: group}
+	if _, err := tx.ExecContext(ctx, q.insert, a.ID, a.Name, a.Country, a.Formed,
+		now()); err != nil {
+		return models.Artist{}, err
+	}
+	return a, insertNames(ctx, tx, q.insertName, a)
+}
+
+var pgSongArtist = songArtistQueries{
+	lock: `select pg_advisory_xact_lock(hashtext(lower($1)))`,
+	resolve: `
+select a.id, a.name from artist_names n join artists a on a.id=n.artist_id
+where lower(n.name)=lower($1)
+`,
+	insert:     queryInsertArtist,
+	insertName: queryInsertArtistName,
+}
+
+const (
+	querySelectArtist = `
+select ` + artistColumns + ` from artists a join artist_names n on n.artist_id=a.id
+where a.id=$1 order by n.name
+`
+	querySelectArtists = `
+select ` + artistColumns + `
+from (select * from artists order by name, id limit $1 offset $2) a
+join artist_names n on n.artist_id=a.id
+order by a.name, a.id, n.name
+`
+	queryCountArtists     = `select count(*) from artists`
+	queryResolveArtist    = `select artist_id from artist_names where lower(name)=lower($1)`
+	queryUpdateArtist     = `update artists set name=$2, country=$3, formed_year=$4, updated_at=now() where id=$1`
+	queryDeleteNames      = `delete from artist_names where artist_id=$1`
+	queryRenameSongs      = `update songs set "group"=$2, version=version+1, updated_at=now() where artist_id=$1 and "group"<>$2`
+	querySelectArtistUsed = `
+select (select count(*) from songs where artist_id=$1) + (select count(*) from albums where artist_id=$1)
+`
+	queryDeleteArtist = `delete from artists where id=$1`
+)
+
+// GetArtist returns artist with aliases.
+func (s *db) GetArtist(ctx context.Context, id string) (models.Artist, error) {
+	aa, err := queryArtists(ctx, s.conn, querySelectArtist, id)
+	if err != nil {
+		return models.Artist{}, err
+	}
+	if len(aa) == 0 {
+		return models.Artist{}, sql.ErrNoRows
+	}
+	return aa[0], nil
+}
+
+// GetArtists returns artists page ordered by name.
+func (s *db) GetArtists(ctx context.Context, page, size int) (models.ResponseGetArtists, error) {
+	res := models.ResponseGetArtists{Page: page, Size: size}
+	if err := s.conn.QueryRowContext(ctx, queryCountArtists).Scan(&res.Total); err != nil {
+		return models.ResponseGetArtists{}, err
+	}
+	res.Pages = pages(res.Total, size)
+	var err error
+	if res.Artists, err = queryArtists(ctx, s.conn, querySelectArtists, size, (page-1)*size); err != nil {
+		return models.ResponseGetArtists{}, err
+	}
+	return res, nil
+}
+
+// ResolveArtist returns artist by canonical name or alias ignoring case.
+func (s *db) ResolveArtist(ctx context.Context, name string) (models.Artist, error) {
+	var id string
+	if err := s.conn.QueryRowContext(ctx, queryResolveArtist, name).Scan(&id); err != nil {
+		return models.Artist{}, err
+	}
+	return s.GetArtist(ctx, id)
+}
+
+// UpdateArtist replaces artist data and aliases, renamed artist songs
+// get new group saving previous state as revision.
+func (s *db) UpdateArtist(ctx context.Context, a models.Artist) error {
+	a.Aliases = aliases(a)
+	err := transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := affected(tx.ExecContext(ctx, queryUpdateArtist,
+			a.ID, a.Name, a.Country, a.Formed)); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, queryDeleteNames, a.ID); err != nil {
+			return err
+		}
+		if err := insertNames(ctx, tx, queryInsertArtistName, a); err != nil {
+			return err
+		}
+		if err := reviseRenamed(ctx, tx, pgRevision, a.ID, a.Name); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, queryRenameSongs, a.ID, a.Name)
+		return err
+	})
+	if err != nil && (err.Error() == errArtistNameViolation.Error() ||
+		err.Error() == ErrUniqueViolation.Error()) {
+		return ErrUniqueViolation
+	}
+	return err
+}
+
+// DeleteArtist removes artist not referenced by songs and albums,
+// deleted songs included.
+func (s *db) DeleteArtist(ctx context.Context, id string) error {
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		var n int
+		if err := tx.QueryRowContext(ctx, querySelectArtistUsed, id).Scan(&n); err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrArtistInUse
+		}
+		return affected(tx.ExecContext(ctx, queryDeleteArtist, id))
+	})
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+func Test_aliases(t *testing.T) {
+	tests := []struct {
+		name string
+		a    models.Artist
+		want []string
+	}{
+		{name: "positive test #1", a: models.Artist{Name: "Queen"}, want: []string{}},
+		{
+			name: "positive test #2",
+			a:    models.Artist{Name: "The Beatles", Aliases: []string{"Beatles", "the beatles", "BEATLES", "Fab Four"}},
+			want: []string{"Beatles", "Fab Four"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aliases(tt.a); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("aliases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_db_AddArtist(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	a := models.Artist{Name: "The Beatles", Aliases: []string{"Beatles"}, Country: "GB", Formed: 1960}
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "positive test #1"},
+		{name: "negative test #1", err: errArtistNameViolation, wantErr: ErrUniqueViolation},
+		{name: "negative test #2", err: errors.New("test"), wantErr: errors.New("test")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(queryInsertArtist)).
+				WithArgs(sqlmock.AnyArg(), a.Name, a.Country, a.Formed, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta(queryInsertArtistName)).
+				WithArgs(sqlmock.AnyArg(), a.Name).WillReturnResult(sqlmock.NewResult(0, 1))
+			q := mock.ExpectExec(regexp.QuoteMeta(queryInsertArtistName)).WithArgs(sqlmock.AnyArg(), "Beatles")
+			if tt.err != nil {
+				q.WillReturnError(tt.err)
+				mock.ExpectRollback()
+			} else {
+				q.WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+			got, err := s.AddArtist(context.Background(), a)
+			if (err != nil) != (tt.wantErr != nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("db.AddArtist() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (got.ID == "" || !reflect.DeepEqual(got.Aliases, a.Aliases)) {
+				t.Errorf("db.AddArtist() = %v", got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_db_GetArtist(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	id := "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"
+	created := time.Date(2025, 1, 4, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "name", "country", "formed_year", "created_at", "updated_at", "name"}
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		want    models.Artist
+		wantErr bool
+	}{
+		{
+			name: "positive test #1",
+			rows: sqlmock.NewRows(columns).
+				AddRow(id, "The Beatles", "GB", 1960, created, created, "Beatles").
+				AddRow(id, "The Beatles", "GB", 1960, created, created, "Fab Four").
+				AddRow(id, "The Beatles", "GB", 1960, created, created, "The Beatles"),
+			want: models.Artist{ID: id, Name: "The Beatles", Aliases: []string{"Beatles", "Fab Four"},
+				Country: "GB", Formed: 1960, CreatedAt: created, UpdatedAt: created},
+		},
+		{name: "negative test #1", rows: sqlmock.NewRows(columns), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(querySelectArtist)).WithArgs(id).WillReturnRows(tt.rows)
+			got, err := s.GetArtist(context.Background(), id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("db.GetArtist() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && err != sql.ErrNoRows {
+				t.Errorf("db.GetArtist() error = %v, want %v", err, sql.ErrNoRows)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("db.GetArtist() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_db_DeleteArtist(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	id := "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"
+	tests := []struct {
+		name     string
+		songs    int
+		affected int64
+		wantErr  error
+	}{
+		{name: "positive test #1", affected: 1},
+		{name: "negative test #1", songs: 2, wantErr: ErrArtistInUse},
+		{name: "negative test #2", wantErr: ErrNotAffected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(querySelectArtistUsed)).WithArgs(id).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.songs))
+			if tt.songs == 0 {
+				mock.ExpectExec(regexp.QuoteMeta(queryDeleteArtist)).WithArgs(id).
+					WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			}
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+			if err := s.DeleteArtist(context.Background(), id); err != tt.wantErr {
+				t.Errorf("db.DeleteArtist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
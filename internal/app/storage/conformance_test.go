@@ -0,0 +1,1165 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/cursor"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/roles"
+	"github.com/xEgorka/project4/internal/app/trgm"
+)
+
+// conformance runs shared Storage behavior tests against fresh storages
+// returned by open.
+func conformance(t *testing.T, open func(t *testing.T) Storage) {
+	ctx := context.Background()
+	releaseDate := time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)
+	muse := models.Song{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: releaseDate,
+		Text:        "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nOoh\nYou set my soul alight\n\nGlaciers melting in the dead of night",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw"}
+	queen := models.Song{
+		Group:       "Queen",
+		Song:        "Bohemian Rhapsody",
+		ReleaseDate: time.Date(1975, 10, 31, 0, 0, 0, 0, time.UTC),
+		Text:        "Is this the real life?\nIs this just fantasy?",
+		Link:        "https://www.youtube.com/watch?v=fJ9rUzIMcZQ"}
+
+	t.Run("ping", func(t *testing.T) {
+		s := open(t)
+		assert.NoError(t, s.Ping())
+	})
+
+	t.Run("add", func(t *testing.T) {
+		s := open(t)
+		got, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		assert.NotEmpty(t, got.ID)
+		assert.Equal(t, muse.Group, got.Group)
+
+		_, err = s.Add(ctx, muse)
+		assert.ErrorIs(t, err, ErrUniqueViolation)
+
+		other := muse
+		other.Song = "Uprising"
+		_, err = s.Add(ctx, other)
+		assert.NoError(t, err)
+
+		require.NoError(t, s.Delete(ctx, got.ID, 0))
+		_, err = s.Add(ctx, muse)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		assert.False(t, m.CreatedAt.IsZero())
+		assert.Equal(t, m.CreatedAt, m.UpdatedAt)
+
+		got, err := s.Get(ctx, m.ID)
+		require.NoError(t, err)
+		assert.Equal(t, m, got)
+
+		require.NoError(t, s.Update(ctx, m.ID, 0, models.RequestUpdateSong{
+			ReleaseDate: muse.ReleaseDate, Text: "Ooh", Link: muse.Link}))
+		got, err = s.Get(ctx, m.ID)
+		require.NoError(t, err)
+		assert.Equal(t, m.CreatedAt, got.CreatedAt)
+		assert.False(t, got.UpdatedAt.Before(m.UpdatedAt))
+		assert.Equal(t, "Ooh", got.Text)
+
+		require.NoError(t, s.Delete(ctx, m.ID, 0))
+		_, err = s.Get(ctx, m.ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = s.Get(ctx, "0824f9fb-7397-4f19-95d5-f9ce8bec75de")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		s := open(t)
+		song, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		req := models.RequestUpdateSong{
+			ReleaseDate: time.Date(2006, 6, 19, 0, 0, 0, 0, time.UTC),
+			Text:        "Ooh baby",
+			Link:        "https://example.com"}
+		require.NoError(t, s.Update(ctx, song.ID, 0, req))
+
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Filter: models.Song{ID: song.ID}, Page: 1, Size: 10})
+		require.NoError(t, err)
+		require.Len(t, d.Songs, 1)
+		assert.True(t, req.ReleaseDate.Equal(d.Songs[0].ReleaseDate))
+		assert.Equal(t, req.Text, d.Songs[0].Text)
+		assert.Equal(t, req.Link, d.Songs[0].Link)
+
+		assert.ErrorIs(t, s.Update(ctx, "missing", 0, req), ErrNotAffected)
+		require.NoError(t, s.Delete(ctx, song.ID, 0))
+		assert.ErrorIs(t, s.Update(ctx, song.ID, 0, req), ErrNotAffected)
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		q, err := s.Add(ctx, queen)
+		require.NoError(t, err)
+
+		link := "https://youtu.be/3dm_5qWWDV8"
+		require.NoError(t, s.Patch(ctx, m.ID, 0, models.RequestPatchSong{Link: &link}))
+		require.NoError(t, s.Patch(ctx, m.ID, 0, models.RequestPatchSong{}))
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Filter: models.Song{ID: m.ID}, Page: 1, Size: 1})
+		require.NoError(t, err)
+		require.Len(t, d.Songs, 1)
+		assert.Equal(t, link, d.Songs[0].Link)
+		assert.Equal(t, muse.Text, d.Songs[0].Text)
+		assert.True(t, muse.ReleaseDate.Equal(d.Songs[0].ReleaseDate))
+		revs, err := s.GetRevisions(ctx, m.ID)
+		require.NoError(t, err)
+		assert.Len(t, revs, 1)
+
+		song := "Hysteria"
+		require.NoError(t, s.Patch(ctx, m.ID, 0, models.RequestPatchSong{Song: &song}))
+		d, err = s.GetSongs(ctx, models.RequestGetSongs{Filter: models.Song{Song: song}, Page: 1, Size: 1})
+		require.NoError(t, err)
+		require.Len(t, d.Songs, 1)
+		assert.Equal(t, m.ID, d.Songs[0].ID)
+
+		assert.ErrorIs(t, s.Patch(ctx, q.ID, 0, models.RequestPatchSong{
+			Group: &muse.Group, Song: &song}), ErrUniqueViolation)
+		require.NoError(t, s.Delete(ctx, m.ID, 0))
+		assert.ErrorIs(t, s.Patch(ctx, m.ID, 0, models.RequestPatchSong{Link: &link}), ErrNotAffected)
+		assert.ErrorIs(t, s.Patch(ctx, m.ID, 0, models.RequestPatchSong{}), ErrNotAffected)
+		assert.ErrorIs(t, s.Patch(ctx, q.ID, 0, models.RequestPatchSong{
+			Group: &muse.Group, Song: &song}), ErrUniqueViolation) // deleted song keeps songs_idx slot
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		s := open(t)
+		song, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		require.NoError(t, s.Delete(ctx, song.ID, 0))
+		assert.ErrorIs(t, s.Delete(ctx, song.ID, 0), ErrNotAffected)
+		assert.ErrorIs(t, s.Delete(ctx, "missing", 0), ErrNotAffected)
+
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10})
+		require.NoError(t, err)
+		assert.Empty(t, d.Songs)
+	})
+
+	t.Run("get text", func(t *testing.T) {
+		s := open(t)
+		song, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+
+		d, err := s.GetText(ctx, song.ID, 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, models.ResponseGetSongText{
+			ID:    song.ID,
+			Group: muse.Group,
+			Song:  muse.Song,
+			Verses: []string{
+				"Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?",
+				"Ooh\nYou set my soul alight"},
+			Total:   3,
+			Page:    1,
+			Size:    2,
+			Version: 1,
+		}, d)
+
+		d, err = s.GetText(ctx, song.ID, 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Glaciers melting in the dead of night"}, d.Verses)
+
+		d, err = s.GetText(ctx, song.ID, 9, 2)
+		require.NoError(t, err)
+		assert.Empty(t, d.Verses)
+		assert.Equal(t, 3, d.Total)
+
+		_, err = s.GetText(ctx, "missing", 1, 2)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		require.NoError(t, s.Delete(ctx, song.ID, 0))
+		_, err = s.GetText(ctx, song.ID, 1, 2)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("get songs", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		q, err := s.Add(ctx, queen)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name   string
+			filter models.Song
+			want   []string
+		}{
+			{name: "all", want: []string{m.ID, q.ID}},
+			{name: "id", filter: models.Song{ID: q.ID}, want: []string{q.ID}},
+			{name: "group", filter: models.Song{Group: "Muse"}, want: []string{m.ID}},
+			{name: "song", filter: models.Song{Song: "Bohemian Rhapsody"}, want: []string{q.ID}},
+			{name: "release date", filter: models.Song{ReleaseDate: releaseDate}, want: []string{m.ID}},
+			{name: "text", filter: models.Song{Text: "soul alight"}, want: []string{m.ID}},
+			{name: "text case", filter: models.Song{Text: "SOUL"}},
+			{name: "link", filter: models.Song{Link: queen.Link}, want: []string{q.ID}},
+			{name: "several", filter: models.Song{Group: "Muse", Song: "Bohemian Rhapsody"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				d, err := s.GetSongs(ctx, models.RequestGetSongs{Filter: tt.filter, Page: 1, Size: 10})
+				require.NoError(t, err)
+				var ids []string
+				for _, v := range d.Songs {
+					ids = append(ids, v.ID)
+				}
+				assert.ElementsMatch(t, tt.want, ids)
+				assert.Equal(t, 1, d.Page)
+				assert.Equal(t, 10, d.Size)
+				assert.Equal(t, len(tt.want), d.Total)
+				assert.Equal(t, min(len(tt.want), 1), d.Pages)
+			})
+		}
+
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Filter: models.Song{ID: m.ID}, Page: 1, Size: 10})
+		require.NoError(t, err)
+		require.Len(t, d.Songs, 1)
+		got := d.Songs[0]
+		assert.Equal(t, muse.Group, got.Group)
+		assert.Equal(t, muse.Song, got.Song)
+		assert.True(t, muse.ReleaseDate.Equal(got.ReleaseDate))
+		assert.Equal(t, muse.Text, got.Text)
+		assert.Equal(t, muse.Link, got.Link)
+
+		first, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 1})
+		require.NoError(t, err)
+		second, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 2, Size: 1})
+		require.NoError(t, err)
+		third, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 3, Size: 1})
+		require.NoError(t, err)
+		require.Len(t, first.Songs, 1)
+		require.Len(t, second.Songs, 1)
+		assert.NotEqual(t, first.Songs[0].ID, second.Songs[0].ID)
+		assert.Empty(t, third.Songs)
+		assert.Equal(t, 2, third.Total)
+		assert.Equal(t, 2, third.Pages)
+
+		d, err = s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 1, CountOnly: true})
+		require.NoError(t, err)
+		assert.Empty(t, d.Songs)
+		assert.Equal(t, 2, d.Total)
+		assert.Equal(t, 2, d.Pages)
+
+		require.NoError(t, s.Delete(ctx, q.ID, 0))
+		d, err = s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10})
+		require.NoError(t, err)
+		require.Len(t, d.Songs, 1)
+		assert.Equal(t, m.ID, d.Songs[0].ID)
+		assert.False(t, d.Songs[0].Deleted)
+	})
+
+	t.Run("match", func(t *testing.T) {
+		s := open(t)
+		ids := make(map[string]string)
+		for _, g := range []string{"Muse", "Museum", "100% Muse", "Mu_se", "Кино"} {
+			d := muse
+			d.Group = g
+			v, err := s.Add(ctx, d)
+			require.NoError(t, err)
+			ids[g] = v.ID
+		}
+		tests := []struct {
+			name  string
+			group string
+			match models.Match
+			want  []string
+		}{
+			{name: "exact", group: "Muse", want: []string{"Muse"}},
+			{name: "exact case", group: "muse"},
+			{name: "prefix", group: "Muse", match: models.Match{Group: models.MatchPrefix}, want: []string{"Muse", "Museum"}},
+			{name: "prefix case", group: "mus", match: models.Match{Group: models.MatchPrefix}},
+			{name: "contains", group: "use", match: models.Match{Group: models.MatchContains},
+				want: []string{"Muse", "Museum", "100% Muse"}},
+			{name: "ci", group: "MUSE", match: models.Match{Group: models.MatchCI},
+				want: []string{"Muse", "Museum", "100% Muse"}},
+			{name: "ci cyrillic", group: "кИн", match: models.Match{Group: models.MatchCI}, want: []string{"Кино"}},
+			{name: "percent", group: "0% M", match: models.Match{Group: models.MatchContains}, want: []string{"100% Muse"}},
+			{name: "percent wildcard", group: "%", match: models.Match{Group: models.MatchPrefix}},
+			{name: "underscore", group: "Mu_", match: models.Match{Group: models.MatchPrefix}, want: []string{"Mu_se"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				d, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10,
+					Filter: models.Song{Group: tt.group}, Match: tt.match})
+				require.NoError(t, err)
+				var got, want []string
+				for _, v := range d.Songs {
+					got = append(got, v.ID)
+				}
+				for _, g := range tt.want {
+					want = append(want, ids[g])
+				}
+				assert.ElementsMatch(t, want, got)
+			})
+		}
+
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10,
+			Filter: models.Song{Song: "supermassive", Text: "GLACIERS"},
+			Match:  models.Match{Song: models.MatchCI, Text: models.MatchCI}})
+		require.NoError(t, err)
+		assert.Len(t, d.Songs, 5)
+		d, err = s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10,
+			Filter: models.Song{Text: "Ooh baby"}, Match: models.Match{Text: models.MatchPrefix}})
+		require.NoError(t, err)
+		assert.Len(t, d.Songs, 5)
+	})
+
+	t.Run("suggest", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		q, err := s.Add(ctx, queen)
+		require.NoError(t, err)
+		d := queen
+		d.Song = "Another One Bites the Dust"
+		gone, err := s.Add(ctx, d)
+		require.NoError(t, err)
+		require.NoError(t, s.Delete(ctx, gone.ID, 0))
+
+		tests := []struct {
+			name  string
+			q     string
+			limit int
+			want  []string
+		}{
+			{name: "typo", q: "Supermasive Blak Hole", limit: 10, want: []string{m.ID}},
+			{name: "group", q: "queen", limit: 10, want: []string{q.ID}},
+			{name: "homoglyph", q: "Musе", limit: 10, want: []string{m.ID}}, // cyrillic е
+			{name: "deleted", q: "Another One Bites", limit: 10},
+			{name: "unrelated", q: "Кино", limit: 10},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := s.Suggest(ctx, tt.q, tt.limit)
+				require.NoError(t, err)
+				var ids []string
+				for _, v := range got {
+					ids = append(ids, v.ID)
+					assert.GreaterOrEqual(t, v.Score, trgm.Threshold)
+				}
+				assert.Equal(t, tt.want, ids)
+			})
+		}
+
+		d = muse
+		d.Song = "Supermassive Black Hole (Live)"
+		_, err = s.Add(ctx, d)
+		require.NoError(t, err)
+		got, err := s.Suggest(ctx, "Supermassive Black Hole", 1)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, m.ID, got[0].ID)
+	})
+
+	t.Run("release range", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		q, err := s.Add(ctx, queen)
+		require.NoError(t, err)
+		day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+		tests := []struct {
+			name     string
+			from, to time.Time
+			want     []string
+		}{
+			{name: "open", want: []string{m.ID, q.ID}},
+			{name: "from inclusive", from: releaseDate, want: []string{m.ID}},
+			{name: "from", from: releaseDate.AddDate(0, 0, 1)},
+			{name: "to exclusive", to: releaseDate, want: []string{q.ID}},
+			{name: "to", to: releaseDate.AddDate(0, 0, 1), want: []string{m.ID, q.ID}},
+			{name: "seventies", from: day(1970, 1, 1), to: day(1980, 1, 1), want: []string{q.ID}},
+			{name: "empty", from: day(1980, 1, 1), to: day(2000, 1, 1)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				d, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10,
+					ReleasedFrom: tt.from, ReleasedTo: tt.to})
+				require.NoError(t, err)
+				var ids []string
+				for _, v := range d.Songs {
+					ids = append(ids, v.ID)
+				}
+				assert.ElementsMatch(t, tt.want, ids)
+				assert.Equal(t, len(tt.want), d.Total)
+			})
+		}
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		s := open(t)
+		var ids []string
+		for _, g := range []string{"A", "B", "C", "D", "E"} {
+			d := muse
+			d.Group = g
+			v, err := s.Add(ctx, d)
+			require.NoError(t, err)
+			ids = append(ids, v.ID)
+		}
+		all, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10})
+		require.NoError(t, err)
+		require.Len(t, all.Songs, 5)
+		assert.Empty(t, all.NextCursor)
+
+		first, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 2})
+		require.NoError(t, err)
+		require.NotEmpty(t, first.NextCursor)
+		got := []models.Song{}
+		for d := first; ; {
+			got = append(got, d.Songs...)
+			if len(d.NextCursor) == 0 {
+				break
+			}
+			c, err := cursor.Decode(d.NextCursor, nil)
+			require.NoError(t, err)
+			d, err = s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 2, Cursor: &c})
+			require.NoError(t, err)
+			assert.Equal(t, 5, d.Total)
+		}
+		assert.Equal(t, all.Songs, got)
+
+		// deleting seen song neither skips nor repeats songs of next page
+		require.NoError(t, s.Delete(ctx, first.Songs[0].ID, 0))
+		c, err := cursor.Decode(first.NextCursor, nil)
+		require.NoError(t, err)
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 2, Cursor: &c})
+		require.NoError(t, err)
+		assert.Equal(t, all.Songs[2:4], d.Songs)
+		assert.ElementsMatch(t, ids, []string{all.Songs[0].ID, all.Songs[1].ID,
+			all.Songs[2].ID, all.Songs[3].ID, all.Songs[4].ID})
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		s := open(t)
+		for _, v := range []struct {
+			group, song string
+			year        int
+		}{
+			{"Muse", "Hysteria", 2003},
+			{"Muse", "Uprising", 2009},
+			{"Muse", "Starlight", 2006},
+			{"Queen", "Innuendo", 1991},
+			{"Queen", "Bicycle Race", 1978},
+			{"Radiohead", "Creep", 1992},
+			{"Coldplay", "Yellow", 2000},
+		} {
+			d := muse
+			d.Group, d.Song = v.group, v.song
+			d.ReleaseDate = time.Date(v.year, 1, 1, 0, 0, 0, 0, time.UTC)
+			if v.song == "Starlight" || v.song == "Creep" {
+				d.ReleaseDate = time.Date(2003, 1, 1, 0, 0, 0, 0, time.UTC) // ties
+			}
+			_, err := s.Add(ctx, d)
+			require.NoError(t, err)
+		}
+		names := func(dd []models.Song) (ss []string) {
+			for _, d := range dd {
+				ss = append(ss, d.Song)
+			}
+			return ss
+		}
+		tests := []struct {
+			name string
+			sort []models.SortKey
+			want []string
+		}{
+			{
+				name: "group song",
+				sort: []models.SortKey{{Column: models.SortGroup}, {Column: models.SortSong}},
+				want: []string{"Yellow", "Hysteria", "Starlight", "Uprising", "Bicycle Race", "Innuendo", "Creep"},
+			},
+			{
+				name: "release date desc group song",
+				sort: []models.SortKey{{Column: models.SortReleaseDate, Desc: true},
+					{Column: models.SortGroup}, {Column: models.SortSong}},
+				want: []string{"Uprising", "Hysteria", "Starlight", "Creep", "Yellow", "Innuendo", "Bicycle Race"},
+			},
+			{
+				name: "group desc song desc",
+				sort: []models.SortKey{{Column: models.SortGroup, Desc: true}, {Column: models.SortSong, Desc: true}},
+				want: []string{"Creep", "Innuendo", "Bicycle Race", "Uprising", "Starlight", "Hysteria", "Yellow"},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				d, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10, Sort: tt.sort})
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, names(d.Songs))
+
+				var got []string
+				for d := (models.ResponseGetSongs{NextCursor: "first"}); len(d.NextCursor) > 0; {
+					r := models.RequestGetSongs{Page: 1, Size: 2, Sort: tt.sort}
+					if d.NextCursor != "first" {
+						c, err := cursor.Decode(d.NextCursor, tt.sort)
+						require.NoError(t, err)
+						r.Cursor = &c
+					}
+					d, err = s.GetSongs(ctx, r)
+					require.NoError(t, err)
+					got = append(got, names(d.Songs)...)
+				}
+				assert.Equal(t, tt.want, got)
+			})
+		}
+
+		// songs of same sort values are ordered by id
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10,
+			Sort: []models.SortKey{{Column: models.SortReleaseDate}}})
+		require.NoError(t, err)
+		var ties []string
+		for _, v := range d.Songs {
+			if v.ReleaseDate.Year() == 2003 {
+				ties = append(ties, v.ID)
+			}
+		}
+		assert.Len(t, ties, 3)
+		assert.IsIncreasing(t, ties)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		_, err = s.Add(ctx, queen)
+		require.NoError(t, err)
+		assert.ErrorIs(t, s.Restore(ctx, m.ID), ErrNotAffected)
+		assert.ErrorIs(t, s.Restore(ctx, "missing"), ErrNotAffected)
+		require.NoError(t, s.Delete(ctx, m.ID, 0))
+
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Deleted: models.DeletedOnly, Page: 1, Size: 10})
+		require.NoError(t, err)
+		require.Len(t, d.Songs, 1)
+		assert.Equal(t, m.ID, d.Songs[0].ID)
+		assert.True(t, d.Songs[0].Deleted)
+		require.NotNil(t, d.Songs[0].DeletedAt)
+		assert.WithinDuration(t, time.Now(), *d.Songs[0].DeletedAt, time.Minute)
+
+		d, err = s.GetSongs(ctx, models.RequestGetSongs{Deleted: models.DeletedInclude, Page: 1, Size: 10})
+		require.NoError(t, err)
+		assert.Len(t, d.Songs, 2)
+		d, err = s.GetSongs(ctx, models.RequestGetSongs{
+			Filter: models.Song{Group: "Queen"}, Deleted: models.DeletedOnly, Page: 1, Size: 10})
+		require.NoError(t, err)
+		assert.Empty(t, d.Songs)
+
+		require.NoError(t, s.Restore(ctx, m.ID))
+		assert.ErrorIs(t, s.Restore(ctx, m.ID), ErrNotAffected)
+		_, err = s.GetText(ctx, m.ID, 1, 1)
+		assert.NoError(t, err)
+		_, err = s.Add(ctx, muse)
+		assert.ErrorIs(t, err, ErrUniqueViolation)
+		d, err = s.GetSongs(ctx, models.RequestGetSongs{Deleted: models.DeletedOnly, Page: 1, Size: 10})
+		require.NoError(t, err)
+		assert.Empty(t, d.Songs)
+		d, err = s.GetSongs(ctx, models.RequestGetSongs{Filter: models.Song{ID: m.ID}, Page: 1, Size: 10})
+		require.NoError(t, err)
+		require.Len(t, d.Songs, 1)
+		assert.Nil(t, d.Songs[0].DeletedAt)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		require.NoError(t, s.Purge(ctx, m.ID, 0))
+		assert.ErrorIs(t, s.Purge(ctx, m.ID, 0), ErrNotAffected)
+		_, err = s.GetText(ctx, m.ID, 1, 1)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		m, err = s.Add(ctx, muse) // songs_idx slot is free again
+		require.NoError(t, err)
+		require.NoError(t, s.Delete(ctx, m.ID, 0))
+		require.NoError(t, s.Purge(ctx, m.ID, 0))
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Deleted: models.DeletedInclude, Page: 1, Size: 10})
+		require.NoError(t, err)
+		assert.Empty(t, d.Songs)
+	})
+
+	t.Run("purge deleted", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		q, err := s.Add(ctx, queen)
+		require.NoError(t, err)
+		require.NoError(t, s.Delete(ctx, m.ID, 0))
+
+		n, err := s.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+
+		n, err = s.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		d, err := s.GetSongs(ctx, models.RequestGetSongs{Deleted: models.DeletedInclude, Page: 1, Size: 10})
+		require.NoError(t, err)
+		require.Len(t, d.Songs, 1)
+		assert.Equal(t, q.ID, d.Songs[0].ID)
+		_, err = s.Add(ctx, muse)
+		assert.NoError(t, err)
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		assert.Equal(t, 1, m.Version)
+		req := models.RequestUpdateSong{ReleaseDate: muse.ReleaseDate, Text: "Ooh", Link: muse.Link}
+
+		assert.ErrorIs(t, s.Update(ctx, m.ID, 2, req), ErrVersionMismatch)
+		require.NoError(t, s.Update(ctx, m.ID, 1, req))
+		link := "https://youtu.be/3dm_5qWWDV8"
+		assert.ErrorIs(t, s.Patch(ctx, m.ID, 1, models.RequestPatchSong{Link: &link}), ErrVersionMismatch)
+		assert.ErrorIs(t, s.Patch(ctx, m.ID, 1, models.RequestPatchSong{}), ErrVersionMismatch)
+		require.NoError(t, s.Patch(ctx, m.ID, 2, models.RequestPatchSong{Link: &link}))
+		require.NoError(t, s.Update(ctx, m.ID, 0, req))
+		d, err := s.GetText(ctx, m.ID, 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 4, d.Version)
+		revs, err := s.GetRevisions(ctx, m.ID)
+		require.NoError(t, err)
+		assert.Len(t, revs, 3) // failed preconditions leave no revisions
+
+		assert.ErrorIs(t, s.Delete(ctx, m.ID, 3), ErrVersionMismatch)
+		require.NoError(t, s.Delete(ctx, m.ID, 4))
+		assert.ErrorIs(t, s.Delete(ctx, m.ID, 5), ErrNotAffected)
+		require.NoError(t, s.Restore(ctx, m.ID))
+		songs, err := s.GetSongs(ctx, models.RequestGetSongs{Filter: models.Song{ID: m.ID}, Page: 1, Size: 1})
+		require.NoError(t, err)
+		require.Len(t, songs.Songs, 1)
+		assert.Equal(t, 6, songs.Songs[0].Version)
+
+		assert.ErrorIs(t, s.Purge(ctx, m.ID, 5), ErrVersionMismatch)
+		require.NoError(t, s.Purge(ctx, m.ID, 6))
+		assert.ErrorIs(t, s.Purge(ctx, m.ID, 6), ErrNotAffected)
+	})
+
+	t.Run("revisions", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		revs, err := s.GetRevisions(ctx, m.ID)
+		require.NoError(t, err)
+		assert.Empty(t, revs)
+
+		require.NoError(t, s.Update(ctx, m.ID, 0, models.RequestUpdateSong{
+			ReleaseDate: muse.ReleaseDate, Text: "Corrected lyrics", Link: muse.Link}))
+		require.NoError(t, s.Delete(ctx, m.ID, 0))
+		assert.ErrorIs(t, s.Update(ctx, m.ID, 0, models.RequestUpdateSong{Text: "x"}), ErrNotAffected)
+
+		revs, err = s.GetRevisions(ctx, m.ID)
+		require.NoError(t, err)
+		require.Len(t, revs, 2)
+		assert.Equal(t, 1, revs[0].Rev)
+		assert.Equal(t, models.ActionUpdate, revs[0].Action)
+		assert.Equal(t, muse.Text, revs[0].Text)
+		assert.True(t, muse.ReleaseDate.Equal(revs[0].ReleaseDate))
+		assert.False(t, revs[0].CreatedAt.IsZero())
+		assert.Equal(t, 2, revs[1].Rev)
+		assert.Equal(t, models.ActionDelete, revs[1].Action)
+		assert.Equal(t, "Corrected lyrics", revs[1].Text)
+
+		r, err := s.GetRevision(ctx, m.ID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, revs[1], r)
+		_, err = s.GetRevision(ctx, m.ID, 3)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = s.GetRevisions(ctx, "0824f9fb-7397-4f19-95d5-f9ce8bec75de")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		require.NoError(t, s.Restore(ctx, m.ID))
+		assert.ErrorIs(t, s.Restore(ctx, m.ID), ErrNotAffected)
+		require.NoError(t, s.AddTags(ctx, m.ID, models.TagGenre, []string{"rock"}))
+		require.NoError(t, s.RemoveTags(ctx, m.ID, []string{"rock"}))
+		revs, err = s.GetRevisions(ctx, m.ID)
+		require.NoError(t, err)
+		require.Len(t, revs, 5)
+		assert.Equal(t, models.ActionRestore, revs[2].Action)
+		assert.Equal(t, models.ActionUpdate, revs[3].Action)
+		assert.Equal(t, models.ActionUpdate, revs[4].Action)
+
+		require.NoError(t, s.Purge(ctx, m.ID, 0))
+		_, err = s.GetRevision(ctx, m.ID, 1)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("artists", func(t *testing.T) {
+		s := open(t)
+		beatles, err := s.AddArtist(ctx, models.Artist{Name: "The Beatles",
+			Aliases: []string{"Beatles", "beatles", "The Beatles", "Fab Four"}, Country: "GB", Formed: 1960})
+		require.NoError(t, err)
+		assert.NotEmpty(t, beatles.ID)
+		assert.Equal(t, []string{"Beatles", "Fab Four"}, beatles.Aliases)
+		_, err = s.AddArtist(ctx, models.Artist{Name: "BEATLES"})
+		assert.ErrorIs(t, err, ErrUniqueViolation)
+		queen, err := s.AddArtist(ctx, models.Artist{Name: "Queen"})
+		require.NoError(t, err)
+		assert.Empty(t, queen.Aliases)
+
+		got, err := s.GetArtist(ctx, beatles.ID)
+		require.NoError(t, err)
+		assert.Equal(t, beatles, got)
+		_, err = s.GetArtist(ctx, "missing")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		got, err = s.ResolveArtist(ctx, "fab four")
+		require.NoError(t, err)
+		assert.Equal(t, beatles.ID, got.ID)
+		_, err = s.ResolveArtist(ctx, "Fab")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+
+		list, err := s.GetArtists(ctx, 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Total)
+		assert.Equal(t, 2, list.Pages)
+		require.Len(t, list.Artists, 1)
+		assert.Equal(t, queen.ID, list.Artists[0].ID)
+		list, err = s.GetArtists(ctx, 2, 1)
+		require.NoError(t, err)
+		require.Len(t, list.Artists, 1)
+		assert.Equal(t, beatles, list.Artists[0])
+
+		d := queen
+		d.Name = "Queen"
+		d.Aliases = []string{"beatles"}
+		assert.ErrorIs(t, s.UpdateArtist(ctx, d), ErrUniqueViolation)
+		d.ID = "missing"
+		d.Aliases = nil
+		assert.ErrorIs(t, s.UpdateArtist(ctx, d), ErrNotAffected)
+
+		song := muse
+		song.Group, song.ArtistID = beatles.Name, beatles.ID
+		v, err := s.Add(ctx, song)
+		require.NoError(t, err)
+		assert.Equal(t, beatles.ID, v.ArtistID)
+		songs, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10,
+			Filter: models.Song{ArtistID: beatles.ID}})
+		require.NoError(t, err)
+		require.Len(t, songs.Songs, 1)
+		assert.Equal(t, beatles.ID, songs.Songs[0].ArtistID)
+
+		beatles.Name, beatles.Aliases = "Beatles", []string{"The Beatles"}
+		require.NoError(t, s.UpdateArtist(ctx, beatles))
+		got, err = s.ResolveArtist(ctx, "the beatles")
+		require.NoError(t, err)
+		assert.Equal(t, "Beatles", got.Name)
+		assert.Equal(t, []string{"The Beatles"}, got.Aliases)
+		_, err = s.ResolveArtist(ctx, "Fab Four")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		renamed, err := s.Get(ctx, v.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Beatles", renamed.Group)
+		assert.Equal(t, 2, renamed.Version)
+		revs, err := s.GetRevisions(ctx, v.ID)
+		require.NoError(t, err)
+		require.Len(t, revs, 1)
+		assert.Equal(t, "The Beatles", revs[0].Group)
+
+		_, err = s.Add(ctx, muse)
+		require.NoError(t, err)
+		beatles.Name = muse.Group
+		assert.ErrorIs(t, s.UpdateArtist(ctx, beatles), ErrUniqueViolation)
+		require.NoError(t, s.Patch(ctx, v.ID, 0, models.RequestPatchSong{Group: &queen.Name, ArtistID: &queen.ID}))
+		renamed, err = s.Get(ctx, v.ID)
+		require.NoError(t, err)
+		assert.Equal(t, queen.ID, renamed.ArtistID)
+
+		assert.ErrorIs(t, s.DeleteArtist(ctx, queen.ID), ErrArtistInUse)
+		assert.ErrorIs(t, s.DeleteArtist(ctx, "missing"), ErrNotAffected)
+		require.NoError(t, s.DeleteArtist(ctx, beatles.ID))
+		_, err = s.GetArtist(ctx, beatles.ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = s.ResolveArtist(ctx, "The Beatles")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("song artists", func(t *testing.T) {
+		s := open(t)
+		m, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		require.NotEmpty(t, m.ArtistID)
+		a, err := s.GetArtist(ctx, m.ArtistID)
+		require.NoError(t, err)
+		assert.Equal(t, muse.Group, a.Name)
+		other := muse
+		other.Group, other.Song = "MUSE", "Uprising"
+		u, err := s.Add(ctx, other)
+		require.NoError(t, err)
+		assert.Equal(t, m.ArtistID, u.ArtistID)
+		assert.Equal(t, muse.Group, u.Group)
+
+		q, err := s.Add(ctx, queen)
+		require.NoError(t, err)
+		_, err = s.Add(ctx, muse)
+		assert.ErrorIs(t, err, ErrUniqueViolation)
+		assert.ErrorIs(t, s.Patch(ctx, q.ID, 0, models.RequestPatchSong{
+			Group: &muse.Group, Song: &muse.Song}), ErrUniqueViolation)
+		group := "Placebo"
+		assert.ErrorIs(t, s.Patch(ctx, q.ID, 5, models.RequestPatchSong{Group: &group}), ErrVersionMismatch)
+		list, err := s.GetArtists(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Total) // failed changes leave no artists
+
+		require.NoError(t, s.Patch(ctx, q.ID, 0, models.RequestPatchSong{Group: &group}))
+		got, err := s.Get(ctx, q.ID)
+		require.NoError(t, err)
+		a, err = s.ResolveArtist(ctx, group)
+		require.NoError(t, err)
+		assert.Equal(t, a.ID, got.ArtistID)
+	})
+
+	t.Run("albums", func(t *testing.T) {
+		s := open(t)
+		artist, err := s.AddArtist(ctx, models.Artist{Name: muse.Group})
+		require.NoError(t, err)
+		released := time.Date(2006, 7, 3, 15, 0, 0, 0, time.UTC)
+		album, err := s.AddAlbum(ctx, models.Album{Title: "Black Holes and Revelations",
+			ArtistID: artist.ID, ReleaseDate: &released, CoverLink: "https://example.com/bhar.jpg"})
+		require.NoError(t, err)
+		assert.NotEmpty(t, album.ID)
+		assert.Equal(t, muse.Group, album.Artist)
+		require.NotNil(t, album.ReleaseDate)
+		assert.Equal(t, date(released), *album.ReleaseDate)
+		_, err = s.AddAlbum(ctx, models.Album{Title: album.Title, ArtistID: artist.ID})
+		assert.ErrorIs(t, err, ErrUniqueViolation)
+		single, err := s.AddAlbum(ctx, models.Album{Title: "Absolution", ArtistID: artist.ID})
+		require.NoError(t, err)
+		assert.Nil(t, single.ReleaseDate)
+
+		got, err := s.GetAlbum(ctx, album.ID)
+		require.NoError(t, err)
+		assert.Equal(t, album, got)
+		_, err = s.GetAlbum(ctx, "missing")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		list, err := s.GetAlbums(ctx, artist.ID, 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Total)
+		assert.Equal(t, 2, list.Pages)
+		require.Len(t, list.Albums, 1)
+		assert.Equal(t, single.ID, list.Albums[0].ID)
+		list, err = s.GetAlbums(ctx, "missing", 1, 10)
+		require.NoError(t, err)
+		assert.Empty(t, list.Albums)
+
+		first, second := muse, muse
+		first.ArtistID, second.ArtistID, second.Song = artist.ID, artist.ID, "Starlight"
+		first, err = s.Add(ctx, first)
+		require.NoError(t, err)
+		second, err = s.Add(ctx, second)
+		require.NoError(t, err)
+		require.NoError(t, s.AttachTrack(ctx, album.ID, second.ID, 2))
+		require.NoError(t, s.AttachTrack(ctx, album.ID, first.ID, 1))
+		assert.ErrorIs(t, s.AttachTrack(ctx, album.ID, first.ID, 2), ErrUniqueViolation)
+		assert.ErrorIs(t, s.AttachTrack(ctx, "missing", first.ID, 3), ErrNotAffected)
+		assert.ErrorIs(t, s.AttachTrack(ctx, album.ID, "missing", 3), ErrNotAffected)
+		revs, err := s.GetRevisions(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Len(t, revs, 1) // failed attachments leave no revisions
+
+		tracks, err := s.GetTracks(ctx, album.ID)
+		require.NoError(t, err)
+		require.Len(t, tracks, 2)
+		assert.Equal(t, first.ID, tracks[0].ID)
+		assert.Equal(t, 1, tracks[0].Track)
+		assert.Equal(t, album.ID, tracks[0].AlbumID)
+		assert.Equal(t, 2, tracks[0].Version)
+		songs, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10,
+			Filter: models.Song{AlbumID: album.ID}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, songs.Total)
+
+		require.NoError(t, s.AttachTrack(ctx, single.ID, second.ID, 1))
+		tracks, err = s.GetTracks(ctx, album.ID)
+		require.NoError(t, err)
+		require.Len(t, tracks, 1)
+		require.NoError(t, s.Delete(ctx, first.ID, 0))
+		tracks, err = s.GetTracks(ctx, album.ID)
+		require.NoError(t, err)
+		assert.Empty(t, tracks)
+
+		assert.ErrorIs(t, s.DeleteArtist(ctx, artist.ID), ErrArtistInUse)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		s := open(t)
+		rock, err := s.Add(ctx, muse)
+		require.NoError(t, err)
+		other := muse
+		other.Song = "Starlight"
+		ballad, err := s.Add(ctx, other)
+		require.NoError(t, err)
+
+		require.NoError(t, s.AddTags(ctx, rock.ID, models.TagGenre, []string{"rock", "Rock", "english"}))
+		require.NoError(t, s.AddTags(ctx, ballad.ID, models.TagMood, []string{"ROCK", "calm"}))
+		assert.ErrorIs(t, s.AddTags(ctx, "missing", models.TagCustom, []string{"rock"}), ErrNotAffected)
+		got, err := s.Get(ctx, rock.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"english", "rock"}, got.Tags)
+		assert.Equal(t, 2, got.Version)
+		got, err = s.Get(ctx, ballad.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"calm", "rock"}, got.Tags)
+
+		tags, err := s.GetTags(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, []models.Tag{
+			{Name: "calm", Kind: models.TagMood, Songs: 1},
+			{Name: "english", Kind: models.TagGenre, Songs: 1},
+			{Name: "rock", Kind: models.TagGenre, Songs: 2},
+		}, tags)
+		tags, err = s.GetTags(ctx, models.TagMood)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Tag{{Name: "calm", Kind: models.TagMood, Songs: 1}}, tags)
+
+		songs, err := s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10,
+			Filter: models.Song{Tags: []string{"English", "calm"}}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, songs.Total)
+		songs, err = s.GetSongs(ctx, models.RequestGetSongs{Page: 1, Size: 10,
+			Filter: models.Song{Tags: []string{"rock", "calm"}}, Match: models.Match{Tags: models.MatchAll}})
+		require.NoError(t, err)
+		require.Len(t, songs.Songs, 1)
+		assert.Equal(t, ballad.ID, songs.Songs[0].ID)
+
+		require.NoError(t, s.RemoveTags(ctx, ballad.ID, []string{"Calm", "rock"}))
+		got, err = s.Get(ctx, ballad.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Tags)
+		assert.ErrorIs(t, s.RemoveTags(ctx, "missing", []string{"rock"}), ErrNotAffected)
+		require.NoError(t, s.Delete(ctx, rock.ID, 0))
+		tags, err = s.GetTags(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, tags, 3)
+		assert.Zero(t, tags[2].Songs)
+	})
+
+	t.Run("playlists", func(t *testing.T) {
+		s := open(t)
+		ids := make(map[string]string)
+		for _, name := range []string{"a", "b", "c", "d"} {
+			v := muse
+			v.Song = name
+			v, err := s.Add(ctx, v)
+			require.NoError(t, err)
+			ids[name] = v.ID
+		}
+		order := func(id string) []string {
+			t.Helper()
+			d, err := s.GetPlaylistSongs(ctx, id, 1, 10)
+			require.NoError(t, err)
+			res := make([]string, 0, len(d.Songs))
+			for _, v := range d.Songs {
+				res = append(res, v.Song)
+			}
+			return res
+		}
+		p, err := s.AddPlaylist(ctx, models.Playlist{Name: "Road trip"})
+		require.NoError(t, err)
+		require.NoError(t, s.AddPlaylistSong(ctx, p.ID, ids["a"], 0))
+		require.NoError(t, s.AddPlaylistSong(ctx, p.ID, ids["b"], 5))
+		require.NoError(t, s.AddPlaylistSong(ctx, p.ID, ids["c"], 1))
+		require.NoError(t, s.AddPlaylistSong(ctx, p.ID, ids["d"], 2))
+		assert.Equal(t, []string{"c", "d", "a", "b"}, order(p.ID))
+		assert.ErrorIs(t, s.AddPlaylistSong(ctx, p.ID, ids["a"], 0), ErrUniqueViolation)
+		assert.ErrorIs(t, s.AddPlaylistSong(ctx, p.ID, "missing", 0), ErrNotAffected)
+		assert.ErrorIs(t, s.AddPlaylistSong(ctx, "missing", ids["a"], 0), ErrNotAffected)
+
+		require.NoError(t, s.Delete(ctx, ids["d"], 0))
+		assert.Equal(t, []string{"c", "a", "b"}, order(p.ID))
+		assert.ErrorIs(t, s.MovePlaylistSong(ctx, p.ID, ids["d"], 1), ErrNotAffected)
+		require.NoError(t, s.MovePlaylistSong(ctx, p.ID, ids["b"], 1))
+		assert.Equal(t, []string{"b", "c", "a"}, order(p.ID))
+		require.NoError(t, s.Restore(ctx, ids["d"]))
+		assert.Equal(t, []string{"b", "c", "d", "a"}, order(p.ID))
+		require.NoError(t, s.MovePlaylistSong(ctx, p.ID, ids["c"], 99))
+		assert.Equal(t, []string{"b", "d", "a", "c"}, order(p.ID))
+		require.NoError(t, s.MovePlaylistSong(ctx, p.ID, ids["b"], 3))
+		assert.Equal(t, []string{"d", "a", "b", "c"}, order(p.ID))
+
+		require.NoError(t, s.RemovePlaylistSong(ctx, p.ID, ids["d"]))
+		assert.ErrorIs(t, s.RemovePlaylistSong(ctx, p.ID, ids["d"]), ErrNotAffected)
+		assert.ErrorIs(t, s.RemovePlaylistSong(ctx, "missing", ids["a"]), ErrNotAffected)
+		require.NoError(t, s.AddPlaylistSong(ctx, p.ID, ids["d"], 0))
+		assert.Equal(t, []string{"a", "b", "c", "d"}, order(p.ID))
+		page, err := s.GetPlaylistSongs(ctx, p.ID, 2, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 4, page.Total)
+		assert.Equal(t, 2, page.Pages)
+		require.Len(t, page.Songs, 1)
+		assert.Equal(t, ids["d"], page.Songs[0].ID)
+
+		other, err := s.AddPlaylist(ctx, models.Playlist{Name: "Chill"})
+		require.NoError(t, err)
+		require.NoError(t, s.RenamePlaylist(ctx, p.ID, "Workout"))
+		assert.ErrorIs(t, s.RenamePlaylist(ctx, "missing", "Workout"), ErrNotAffected)
+		got, err := s.GetPlaylist(ctx, p.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Workout", got.Name)
+		list, err := s.GetPlaylists(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Total)
+		require.Len(t, list.Playlists, 2)
+		assert.Equal(t, other, list.Playlists[0])
+
+		require.NoError(t, s.DeletePlaylist(ctx, p.ID))
+		assert.ErrorIs(t, s.DeletePlaylist(ctx, p.ID), ErrNotAffected)
+		_, err = s.GetPlaylist(ctx, p.ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = s.Get(ctx, ids["a"])
+		require.NoError(t, err)
+	})
+
+	t.Run("api keys", func(t *testing.T) {
+		s := open(t)
+		keys, err := s.GetAPIKeys(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+		ci, err := s.AddAPIKey(ctx, models.APIKey{Name: "ci", Role: roles.Editor, Hash: "h1"})
+		require.NoError(t, err)
+		assert.NotEmpty(t, ci.ID)
+		_, err = s.AddAPIKey(ctx, models.APIKey{Name: "ops", Role: roles.Admin, Hash: "h2"})
+		require.NoError(t, err)
+		got, err := s.GetAPIKey(ctx, "h1")
+		require.NoError(t, err)
+		assert.Equal(t, ci, got)
+		_, err = s.GetAPIKey(ctx, "missing")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		keys, err = s.GetAPIKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, ci, keys[0])
+		require.NoError(t, s.DeleteAPIKey(ctx, ci.ID))
+		assert.ErrorIs(t, s.DeleteAPIKey(ctx, ci.ID), ErrNotAffected)
+		_, err = s.GetAPIKey(ctx, "h1")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestMemory_Conformance(t *testing.T) {
+	conformance(t, func(t *testing.T) Storage {
+		s, err := Open(context.Background(), &config.Config{DBDriver: config.DriverMemory})
+		require.NoError(t, err)
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+func TestLite_Conformance(t *testing.T) {
+	conformance(t, func(t *testing.T) Storage {
+		s, err := Open(context.Background(), &config.Config{
+			DBDriver: config.DriverSQLite,
+			DBURI:    "sqlite://" + filepath.Join(t.TempDir(), "songs.db")})
+		require.NoError(t, err)
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+// TestDB_Conformance runs against migrated postgres database set by
+// TEST_DB_URI environment variable, all songs are removed before each test.
+func TestDB_Conformance(t *testing.T) {
+	uri := os.Getenv("TEST_DB_URI")
+	if uri == "" {
+		t.Skip("TEST_DB_URI is not set")
+	}
+	conformance(t, func(t *testing.T) Storage {
+		conn, err := sql.Open(config.DriverPgx, uri)
+		require.NoError(t, err)
+		_, err = conn.Exec(`truncate songs, artists, tags, playlists, api_keys cascade`)
+		require.NoError(t, err)
+		s := new(&config.Config{DBDriver: config.DriverPgx, DBURI: uri}, conn)
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+// TestDB_Search runs postgres full text search against database set by
+// TEST_DB_URI environment variable.
+func TestDB_Search(t *testing.T) {
+	uri := os.Getenv("TEST_DB_URI")
+	if uri == "" {
+		t.Skip("TEST_DB_URI is not set")
+	}
+	ctx := context.Background()
+	conn, err := sql.Open(config.DriverPgx, uri)
+	require.NoError(t, err)
+	_, err = conn.Exec(`truncate songs cascade`)
+	require.NoError(t, err)
+	s := new(&config.Config{DBDriver: config.DriverPgx, DBURI: uri}, conn)
+	defer s.Close()
+	m, err := s.Add(ctx, models.Song{Group: "Muse", Song: "Supermassive Black Hole",
+		Text: "Ooh baby, don't you know I suffer?\n\nOoh\nYou set my soul alight\n\nGlaciers melting in the dead of night"})
+	require.NoError(t, err)
+	k, err := s.Add(ctx, models.Song{Group: "Кино", Song: "Группа крови",
+		Text: "Тёплое место, но улицы ждут\nОтпечатков наших ног\n\nГруппа крови на рукаве"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		r        models.RequestSearch
+		want     []string
+		headline string
+	}{
+		{name: "word", r: models.RequestSearch{Query: "melting"}, want: []string{m.ID},
+			headline: "Glaciers <mark>melting</mark> in the dead of night"},
+		{name: "phrase", r: models.RequestSearch{Query: `"soul alight"`}, want: []string{m.ID},
+			headline: "Ooh\nYou set my <mark>soul</mark> <mark>alight</mark>"},
+		{name: "phrase order", r: models.RequestSearch{Query: `"alight soul"`}},
+		{name: "or", r: models.RequestSearch{Query: "glaciers or blood"}, want: []string{m.ID}},
+		{name: "not", r: models.RequestSearch{Query: "glaciers -suffer"}},
+		{name: "group", r: models.RequestSearch{Query: "muse"}, want: []string{m.ID}},
+		{name: "russian", r: models.RequestSearch{Query: "рукава", Lang: models.LangRussian}, want: []string{k.ID},
+			headline: "Группа крови на <mark>рукаве</mark>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.r.Lang = cmp.Or(tt.r.Lang, models.LangEnglish)
+			tt.r.Page, tt.r.Size = 1, 10
+			d, err := s.Search(ctx, tt.r)
+			require.NoError(t, err)
+			var ids []string
+			for _, v := range d.Hits {
+				ids = append(ids, v.ID)
+				assert.Positive(t, v.Rank)
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Equal(t, len(tt.want), d.Total)
+			if len(tt.headline) > 0 {
+				assert.Equal(t, tt.headline, d.Hits[0].Headline)
+			}
+		})
+	}
+}
+
+func TestSearch_notSupported(t *testing.T) {
+	for _, cfg := range []*config.Config{
+		{DBDriver: config.DriverMemory},
+		{DBDriver: config.DriverSQLite, DBURI: "sqlite://" + filepath.Join(t.TempDir(), "songs.db")},
+	} {
+		s, err := Open(context.Background(), cfg)
+		require.NoError(t, err)
+		_, err = s.Search(context.Background(), models.RequestSearch{Query: "soul", Lang: models.LangEnglish})
+		assert.ErrorIs(t, err, ErrNotSupported)
+		require.NoError(t, s.Close())
+	}
+}
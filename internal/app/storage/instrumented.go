@@ -0,0 +1,328 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/xEgorka/project4/internal/app/metrics"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/tracing"
+)
+
+// instrumented records latency and trace span of wrapped Storage queries.
+type instrumented struct{ Storage }
+
+// Instrument wraps Storage recording latency metrics and trace spans of its
+// queries by method.
+func Instrument(s Storage) Storage { return &instrumented{Storage: s} }
+
+// notFound reports whether err is missing or not changed row rather than
+// storage failure.
+func notFound(err error) bool { return errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrNotAffected) }
+
+// status returns metrics status of query finished with err.
+func status(err error) string {
+	switch {
+	case err == nil:
+		return metrics.StatusOK
+	case notFound(err):
+		return metrics.StatusNotFound
+	}
+	return metrics.StatusError
+}
+
+// observe runs query in span and records its latency.
+func observe[T any](ctx context.Context, query string, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, span := tracing.Start(ctx, "storage."+query,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBOperationName(query)))
+	start := time.Now()
+	v, err := fn(ctx)
+	metrics.ObserveStorage(query, time.Since(start), status(err))
+	if notFound(err) {
+		tracing.End(span, nil) // expected outcome, not failure
+	} else {
+		tracing.End(span, err)
+	}
+	return v, err
+}
+
+// observeErr runs query returning only error in span and records its
+// latency.
+func observeErr(ctx context.Context, query string, fn func(ctx context.Context) error) error {
+	_, err := observe(ctx, query, func(ctx context.Context) (struct{}, error) { return struct{}{}, fn(ctx) })
+	return err
+}
+
+// Add observes wrapped Add.
+func (s *instrumented) Add(ctx context.Context, d models.Song) (models.Song, error) {
+	return observe(ctx, "Add", func(ctx context.Context) (models.Song, error) { return s.Storage.Add(ctx, d) })
+}
+
+// Get observes wrapped Get.
+func (s *instrumented) Get(ctx context.Context, id string) (models.Song, error) {
+	return observe(ctx, "Get", func(ctx context.Context) (models.Song, error) { return s.Storage.Get(ctx, id) })
+}
+
+// Update observes wrapped Update.
+func (s *instrumented) Update(ctx context.Context, id string, version int, data models.RequestUpdateSong) error {
+	return observeErr(ctx, "Update", func(ctx context.Context) error {
+		return s.Storage.Update(ctx, id, version, data)
+	})
+}
+
+// Patch observes wrapped Patch.
+func (s *instrumented) Patch(ctx context.Context, id string, version int, data models.RequestPatchSong) error {
+	return observeErr(ctx, "Patch", func(ctx context.Context) error { return s.Storage.Patch(ctx, id, version, data) })
+}
+
+// Delete observes wrapped Delete.
+func (s *instrumented) Delete(ctx context.Context, id string, version int) error {
+	return observeErr(ctx, "Delete", func(ctx context.Context) error { return s.Storage.Delete(ctx, id, version) })
+}
+
+// Restore observes wrapped Restore.
+func (s *instrumented) Restore(ctx context.Context, id string) error {
+	return observeErr(ctx, "Restore", func(ctx context.Context) error { return s.Storage.Restore(ctx, id) })
+}
+
+// Purge observes wrapped Purge.
+func (s *instrumented) Purge(ctx context.Context, id string, version int) error {
+	return observeErr(ctx, "Purge", func(ctx context.Context) error { return s.Storage.Purge(ctx, id, version) })
+}
+
+// PurgeDeleted observes wrapped PurgeDeleted.
+func (s *instrumented) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return observe(ctx, "PurgeDeleted", func(ctx context.Context) (int64, error) {
+		return s.Storage.PurgeDeleted(ctx, before)
+	})
+}
+
+// GetRevisions observes wrapped GetRevisions.
+func (s *instrumented) GetRevisions(ctx context.Context, id string) ([]models.Revision, error) {
+	return observe(ctx, "GetRevisions", func(ctx context.Context) ([]models.Revision, error) {
+		return s.Storage.GetRevisions(ctx, id)
+	})
+}
+
+// GetRevision observes wrapped GetRevision.
+func (s *instrumented) GetRevision(ctx context.Context, id string, rev int) (models.Revision, error) {
+	return observe(ctx, "GetRevision", func(ctx context.Context) (models.Revision, error) {
+		return s.Storage.GetRevision(ctx, id, rev)
+	})
+}
+
+// GetText observes wrapped GetText.
+func (s *instrumented) GetText(ctx context.Context, id string, page, size int) (models.ResponseGetSongText, error) {
+	return observe(ctx, "GetText", func(ctx context.Context) (models.ResponseGetSongText, error) {
+		return s.Storage.GetText(ctx, id, page, size)
+	})
+}
+
+// GetSongs observes wrapped GetSongs.
+func (s *instrumented) GetSongs(ctx context.Context, r models.RequestGetSongs) (models.ResponseGetSongs, error) {
+	return observe(ctx, "GetSongs", func(ctx context.Context) (models.ResponseGetSongs, error) {
+		return s.Storage.GetSongs(ctx, r)
+	})
+}
+
+// Search observes wrapped Search.
+func (s *instrumented) Search(ctx context.Context, r models.RequestSearch) (models.ResponseSearch, error) {
+	return observe(ctx, "Search", func(ctx context.Context) (models.ResponseSearch, error) {
+		return s.Storage.Search(ctx, r)
+	})
+}
+
+// Suggest observes wrapped Suggest.
+func (s *instrumented) Suggest(ctx context.Context, q string, limit int) ([]models.Suggestion, error) {
+	return observe(ctx, "Suggest", func(ctx context.Context) ([]models.Suggestion, error) {
+		return s.Storage.Suggest(ctx, q, limit)
+	})
+}
+
+// AddArtist observes wrapped AddArtist.
+func (s *instrumented) AddArtist(ctx context.Context, a models.Artist) (models.Artist, error) {
+	return observe(ctx, "AddArtist", func(ctx context.Context) (models.Artist, error) {
+		return s.Storage.AddArtist(ctx, a)
+	})
+}
+
+// GetArtist observes wrapped GetArtist.
+func (s *instrumented) GetArtist(ctx context.Context, id string) (models.Artist, error) {
+	return observe(ctx, "GetArtist", func(ctx context.Context) (models.Artist, error) {
+		return s.Storage.GetArtist(ctx, id)
+	})
+}
+
+// GetArtists observes wrapped GetArtists.
+func (s *instrumented) GetArtists(ctx context.Context, page, size int) (models.ResponseGetArtists, error) {
+	return observe(ctx, "GetArtists", func(ctx context.Context) (models.ResponseGetArtists, error) {
+		return s.Storage.GetArtists(ctx, page, size)
+	})
+}
+
+// ResolveArtist observes wrapped ResolveArtist.
+func (s *instrumented) ResolveArtist(ctx context.Context, name string) (models.Artist, error) {
+	return observe(ctx, "ResolveArtist", func(ctx context.Context) (models.Artist, error) {
+		return s.Storage.ResolveArtist(ctx, name)
+	})
+}
+
+// UpdateArtist observes wrapped UpdateArtist.
+func (s *instrumented) UpdateArtist(ctx context.Context, a models.Artist) error {
+	return observeErr(ctx, "UpdateArtist", func(ctx context.Context) error { return s.Storage.UpdateArtist(ctx, a) })
+}
+
+// DeleteArtist observes wrapped DeleteArtist.
+func (s *instrumented) DeleteArtist(ctx context.Context, id string) error {
+	return observeErr(ctx, "DeleteArtist", func(ctx context.Context) error { return s.Storage.DeleteArtist(ctx, id) })
+}
+
+// AddAlbum observes wrapped AddAlbum.
+func (s *instrumented) AddAlbum(ctx context.Context, a models.Album) (models.Album, error) {
+	return observe(ctx, "AddAlbum", func(ctx context.Context) (models.Album, error) {
+		return s.Storage.AddAlbum(ctx, a)
+	})
+}
+
+// GetAlbum observes wrapped GetAlbum.
+func (s *instrumented) GetAlbum(ctx context.Context, id string) (models.Album, error) {
+	return observe(ctx, "GetAlbum", func(ctx context.Context) (models.Album, error) {
+		return s.Storage.GetAlbum(ctx, id)
+	})
+}
+
+// GetAlbums observes wrapped GetAlbums.
+func (s *instrumented) GetAlbums(ctx context.Context, artistID string, page, size int) (models.ResponseGetAlbums, error) {
+	return observe(ctx, "GetAlbums", func(ctx context.Context) (models.ResponseGetAlbums, error) {
+		return s.Storage.GetAlbums(ctx, artistID, page, size)
+	})
+}
+
+// GetTracks observes wrapped GetTracks.
+func (s *instrumented) GetTracks(ctx context.Context, id string) ([]models.Song, error) {
+	return observe(ctx, "GetTracks", func(ctx context.Context) ([]models.Song, error) {
+		return s.Storage.GetTracks(ctx, id)
+	})
+}
+
+// AttachTrack observes wrapped AttachTrack.
+func (s *instrumented) AttachTrack(ctx context.Context, id, songID string, track int) error {
+	return observeErr(ctx, "AttachTrack", func(ctx context.Context) error {
+		return s.Storage.AttachTrack(ctx, id, songID, track)
+	})
+}
+
+// AddTags observes wrapped AddTags.
+func (s *instrumented) AddTags(ctx context.Context, id, kind string, names []string) error {
+	return observeErr(ctx, "AddTags", func(ctx context.Context) error {
+		return s.Storage.AddTags(ctx, id, kind, names)
+	})
+}
+
+// RemoveTags observes wrapped RemoveTags.
+func (s *instrumented) RemoveTags(ctx context.Context, id string, names []string) error {
+	return observeErr(ctx, "RemoveTags", func(ctx context.Context) error {
+		return s.Storage.RemoveTags(ctx, id, names)
+	})
+}
+
+// GetTags observes wrapped GetTags.
+func (s *instrumented) GetTags(ctx context.Context, kind string) ([]models.Tag, error) {
+	return observe(ctx, "GetTags", func(ctx context.Context) ([]models.Tag, error) {
+		return s.Storage.GetTags(ctx, kind)
+	})
+}
+
+// AddPlaylist observes wrapped AddPlaylist.
+func (s *instrumented) AddPlaylist(ctx context.Context, p models.Playlist) (models.Playlist, error) {
+	return observe(ctx, "AddPlaylist", func(ctx context.Context) (models.Playlist, error) {
+		return s.Storage.AddPlaylist(ctx, p)
+	})
+}
+
+// GetPlaylist observes wrapped GetPlaylist.
+func (s *instrumented) GetPlaylist(ctx context.Context, id string) (models.Playlist, error) {
+	return observe(ctx, "GetPlaylist", func(ctx context.Context) (models.Playlist, error) {
+		return s.Storage.GetPlaylist(ctx, id)
+	})
+}
+
+// GetPlaylists observes wrapped GetPlaylists.
+func (s *instrumented) GetPlaylists(ctx context.Context, page, size int) (models.ResponseGetPlaylists, error) {
+	return observe(ctx, "GetPlaylists", func(ctx context.Context) (models.ResponseGetPlaylists, error) {
+		return s.Storage.GetPlaylists(ctx, page, size)
+	})
+}
+
+// RenamePlaylist observes wrapped RenamePlaylist.
+func (s *instrumented) RenamePlaylist(ctx context.Context, id, name string) error {
+	return observeErr(ctx, "RenamePlaylist", func(ctx context.Context) error {
+		return s.Storage.RenamePlaylist(ctx, id, name)
+	})
+}
+
+// DeletePlaylist observes wrapped DeletePlaylist.
+func (s *instrumented) DeletePlaylist(ctx context.Context, id string) error {
+	return observeErr(ctx, "DeletePlaylist", func(ctx context.Context) error {
+		return s.Storage.DeletePlaylist(ctx, id)
+	})
+}
+
+// GetPlaylistSongs observes wrapped GetPlaylistSongs.
+func (s *instrumented) GetPlaylistSongs(ctx context.Context, id string, page, size int) (models.ResponseGetPlaylist, error) {
+	return observe(ctx, "GetPlaylistSongs", func(ctx context.Context) (models.ResponseGetPlaylist, error) {
+		return s.Storage.GetPlaylistSongs(ctx, id, page, size)
+	})
+}
+
+// AddPlaylistSong observes wrapped AddPlaylistSong.
+func (s *instrumented) AddPlaylistSong(ctx context.Context, id, songID string, position int) error {
+	return observeErr(ctx, "AddPlaylistSong", func(ctx context.Context) error {
+		return s.Storage.AddPlaylistSong(ctx, id, songID, position)
+	})
+}
+
+// MovePlaylistSong observes wrapped MovePlaylistSong.
+func (s *instrumented) MovePlaylistSong(ctx context.Context, id, songID string, position int) error {
+	return observeErr(ctx, "MovePlaylistSong", func(ctx context.Context) error {
+		return s.Storage.MovePlaylistSong(ctx, id, songID, position)
+	})
+}
+
+// RemovePlaylistSong observes wrapped RemovePlaylistSong.
+func (s *instrumented) RemovePlaylistSong(ctx context.Context, id, songID string) error {
+	return observeErr(ctx, "RemovePlaylistSong", func(ctx context.Context) error {
+		return s.Storage.RemovePlaylistSong(ctx, id, songID)
+	})
+}
+
+// AddAPIKey observes wrapped AddAPIKey.
+func (s *instrumented) AddAPIKey(ctx context.Context, k models.APIKey) (models.APIKey, error) {
+	return observe(ctx, "AddAPIKey", func(ctx context.Context) (models.APIKey, error) {
+		return s.Storage.AddAPIKey(ctx, k)
+	})
+}
+
+// GetAPIKey observes wrapped GetAPIKey.
+func (s *instrumented) GetAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	return observe(ctx, "GetAPIKey", func(ctx context.Context) (models.APIKey, error) {
+		return s.Storage.GetAPIKey(ctx, hash)
+	})
+}
+
+// GetAPIKeys observes wrapped GetAPIKeys.
+func (s *instrumented) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return observe(ctx, "GetAPIKeys", func(ctx context.Context) ([]models.APIKey, error) {
+		return s.Storage.GetAPIKeys(ctx)
+	})
+}
+
+// DeleteAPIKey observes wrapped DeleteAPIKey.
+func (s *instrumented) DeleteAPIKey(ctx context.Context, id string) error {
+	return observeErr(ctx, "DeleteAPIKey", func(ctx context.Context) error { return s.Storage.DeleteAPIKey(ctx, id) })
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/metrics"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+func TestInstrument(t *testing.T) {
+	s := Instrument(newMemory(&config.Config{}))
+	ctx := context.Background()
+	song, err := s.Add(ctx, models.Song{Group: "Muse", Song: "Uprising"})
+	require.NoError(t, err)
+	got, err := s.Get(ctx, song.ID)
+	require.NoError(t, err)
+	assert.Equal(t, song, got)
+	_, err = s.Get(ctx, "unknown")
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	b, err := io.ReadAll(rec.Result().Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `songs_storage_query_duration_seconds_count{query="Add",status="ok"} 1`)
+	assert.Contains(t, string(b), `songs_storage_query_duration_seconds_count{query="Get",status="ok"} 1`)
+	assert.Contains(t, string(b), `songs_storage_query_duration_seconds_count{query="Get",status="not_found"} 1`)
+}
+
+func Test_status(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "positive test #1", want: metrics.StatusOK},
+		{name: "positive test #2", err: sql.ErrNoRows, want: metrics.StatusNotFound},
+		{name: "positive test #3", err: fmt.Errorf("wrapped: %w", ErrNotAffected), want: metrics.StatusNotFound},
+		{name: "negative test #1", err: ErrVersionMismatch, want: metrics.StatusError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, status(tt.err))
+		})
+	}
+}
+
+func TestInstrument_spans(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	s := Instrument(newMemory(&config.Config{}))
+	ctx := context.Background()
+	song, err := s.Add(ctx, models.Song{Group: "Muse", Song: "Uprising"})
+	require.NoError(t, err)
+	_, err = s.Get(ctx, "unknown")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.ErrorIs(t, s.Update(ctx, song.ID, 5, models.RequestUpdateSong{}), ErrVersionMismatch)
+
+	spans := sr.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "storage.Add", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "storage.Get", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code) // not found is no failure
+	assert.Empty(t, spans[1].Events())
+	assert.Equal(t, "storage.Update", spans[2].Name())
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// scanAPIKey reads API key from query result row.
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (models.APIKey, error) {
+	var d models.APIKey
+	if err := row.Scan(&d.ID, &d.Name, &d.Role, &d.Hash, &d.CreatedAt); err != nil {
+		return models.APIKey{}, err
+	}
+	d.CreatedAt = d.CreatedAt.UTC()
+	return d, nil
+}
+
+// queryAPIKeys runs API keys query and reads API keys.
+func queryAPIKeys(ctx context.Context, conn *sql.DB, q string, args ...any) ([]models.APIKey, error) {
+	rows, err := conn.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	res := make([]models.APIKey, 0)
+	for rows.Next() {
+		d, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+const (
+	queryInsertAPIKey  = `insert into api_keys (id, name, role, hash, created_at) values ($1, $2, $3, $4, $5)`
+	querySelectAPIKey  = `select id, name, role, hash, created_at from api_keys where hash=$1`
+	querySelectAPIKeys = `select id, name, role, hash, created_at from api_keys order by created_at, id`
+	queryDeleteAPIKey  = `delete from api_keys where id=$1`
+)
+
+// AddAPIKey keeps API key hash.
+func (s *db) AddAPIKey(ctx context.Context, d models.APIKey) (models.APIKey, error) {
+	d.ID = uuid.New().String()
+	d.CreatedAt = now()
+	if _, err := s.conn.ExecContext(ctx, queryInsertAPIKey, d.ID, d.Name, d.Role, d.Hash, d.CreatedAt); err != nil {
+		return models.APIKey{}, err
+	}
+	return d, nil
+}
+
+// GetAPIKey returns API key by its hash.
+func (s *db) GetAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	return scanAPIKey(s.conn.QueryRowContext(ctx, querySelectAPIKey, hash))
+}
+
+// GetAPIKeys returns API keys in creation order.
+func (s *db) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return queryAPIKeys(ctx, s.conn, querySelectAPIKeys)
+}
+
+// DeleteAPIKey revokes API key.
+func (s *db) DeleteAPIKey(ctx context.Context, id string) error {
+	return affected(s.conn.ExecContext(ctx, queryDeleteAPIKey, id))
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+func Test_db_GetAPIKey(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	created := time.Date(2025, 1, 12, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "name", "role", "hash", "created_at"}
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		want    models.APIKey
+		wantErr error
+	}{
+		{
+			name: "positive test #1",
+			rows: sqlmock.NewRows(columns).AddRow("1", "ci", "editor", "h1", created),
+			want: models.APIKey{ID: "1", Name: "ci", Role: "editor", Hash: "h1", CreatedAt: created},
+		},
+		{name: "negative test #1", rows: sqlmock.NewRows(columns), wantErr: sql.ErrNoRows},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(querySelectAPIKey)).WithArgs("h1").WillReturnRows(tt.rows)
+			got, err := s.GetAPIKey(context.Background(), "h1")
+			if err != tt.wantErr {
+				t.Errorf("db.GetAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("db.GetAPIKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_db_DeleteAPIKey(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{name: "positive test #1", affected: 1},
+		{name: "negative test #1", wantErr: ErrNotAffected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectExec(regexp.QuoteMeta(queryDeleteAPIKey)).WithArgs("1").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if err := s.DeleteAPIKey(context.Background(), "1"); err != tt.wantErr {
+				t.Errorf("db.DeleteAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
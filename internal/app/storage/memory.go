@@ -0,0 +1,1021 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/cursor"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// memory keeps songs in process memory and mimics db behavior.
+type memory struct {
+	mu        sync.RWMutex
+	cfg       *config.Config
+	songs     []*models.Song // in insertion order
+	revs      map[string][]models.Revision
+	artists   []*models.Artist
+	albums    []*models.Album
+	tags      []*models.Tag // songs counts are not kept
+	playlists []*playlist
+	keys      []models.APIKey
+}
+
+func newMemory(config *config.Config) *memory {
+	return &memory{cfg: config, revs: make(map[string][]models.Revision)}
+}
+
+// Ping checks storage availability.
+func (s *memory) Ping() error { return nil }
+
+// Close releases storage.
+func (s *memory) Close() error { return nil }
+
+// date truncates time to date the way postgres date column does.
+func date(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// find returns song by id and deleted flag.
+func (s *memory) find(id string, deleted bool) *models.Song {
+	for _, v := range s.songs {
+		if v.ID == id && v.Deleted == deleted {
+			return v
+		}
+	}
+	return nil
+}
+
+// Add creates song in memory, song without artist gets artist of group
+// created if missing.
+func (s *memory) Add(ctx context.Context, song models.Song) (models.Song, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var a *models.Artist
+	if len(song.ArtistID) == 0 && len(song.Group) > 0 {
+		a = s.songArtist(song.Group)
+		song.Group, song.ArtistID = a.Name, a.ID
+	}
+	for _, v := range s.songs { // songs_idx covers deleted songs too
+		if v.Group == song.Group && v.Song == song.Song {
+			if v.Deleted {
+				return models.Song{}, sql.ErrNoRows
+			}
+			return models.Song{}, ErrUniqueViolation
+		}
+	}
+	song.ID = uuid.New().String()
+	song.ReleaseDate = date(song.ReleaseDate)
+	song.Deleted, song.DeletedAt, song.Version = false, nil, 1
+	song.CreatedAt = now()
+	song.UpdatedAt = song.CreatedAt
+	v := song
+	s.songs = append(s.songs, &v)
+	s.keepArtist(a)
+	return song, nil
+}
+
+// Get returns not deleted song.
+func (s *memory) Get(ctx context.Context, id string) (models.Song, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v := s.find(id, false)
+	if v == nil {
+		return models.Song{}, sql.ErrNoRows
+	}
+	return *v, nil
+}
+
+// active returns not deleted song of version, zero version matches any.
+func (s *memory) active(id string, version int) (*models.Song, error) {
+	v := s.find(id, false)
+	if v == nil {
+		return nil, ErrNotAffected
+	}
+	if version != 0 && v.Version != version {
+		return nil, ErrVersionMismatch
+	}
+	return v, nil
+}
+
+// revise saves current song state as revision.
+func (s *memory) revise(v *models.Song, action string) {
+	s.revs[v.ID] = append(s.revs[v.ID], models.Revision{
+		Rev:         len(s.revs[v.ID]) + 1,
+		Group:       v.Group,
+		Song:        v.Song,
+		ReleaseDate: v.ReleaseDate,
+		Text:        v.Text,
+		Link:        v.Link,
+		Action:      action,
+		CreatedAt:   now(),
+	})
+}
+
+// Update updates song in memory.
+func (s *memory) Update(ctx context.Context, id string, version int, d models.RequestUpdateSong) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, err := s.active(id, version)
+	if err != nil {
+		return err
+	}
+	s.revise(v, models.ActionUpdate)
+	v.ReleaseDate, v.Text, v.Link = date(d.ReleaseDate), d.Text, d.Link
+	v.Version, v.UpdatedAt = v.Version+1, now()
+	return nil
+}
+
+// Patch updates song fields set by patch in memory, group without artist
+// gets artist created if missing.
+func (s *memory) Patch(ctx context.Context, id string, version int, d models.RequestPatchSong) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, err := s.active(id, version)
+	if err != nil {
+		return err
+	}
+	var a *models.Artist
+	if d.Group != nil && d.ArtistID == nil {
+		a = s.songArtist(*d.Group)
+		d.Group, d.ArtistID = &a.Name, &a.ID
+	}
+	group, song := v.Group, v.Song
+	if d.Group != nil {
+		group = *d.Group
+	}
+	if d.Song != nil {
+		song = *d.Song
+	}
+	for _, o := range s.songs { // songs_idx covers deleted songs too
+		if o != v && o.Group == group && o.Song == song {
+			return ErrUniqueViolation
+		}
+	}
+	if d == (models.RequestPatchSong{}) {
+		return nil
+	}
+	s.revise(v, models.ActionUpdate)
+	v.Group, v.Song = group, song
+	if d.ReleaseDate != nil {
+		v.ReleaseDate = date(*d.ReleaseDate)
+	}
+	if d.Text != nil {
+		v.Text = *d.Text
+	}
+	if d.Link != nil {
+		v.Link = *d.Link
+	}
+	if d.ArtistID != nil {
+		v.ArtistID = *d.ArtistID
+	}
+	v.Version, v.UpdatedAt = v.Version+1, now()
+	s.keepArtist(a)
+	return nil
+}
+
+// Delete soft deletes song from memory.
+func (s *memory) Delete(ctx context.Context, id string, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, err := s.active(id, version)
+	if err != nil {
+		return err
+	}
+	s.revise(v, models.ActionDelete)
+	at := now()
+	v.Deleted, v.DeletedAt = true, &at
+	v.Version, v.UpdatedAt = v.Version+1, at
+	return nil
+}
+
+// Restore brings soft deleted song back to memory saving deleted state
+// as revision.
+func (s *memory) Restore(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.find(id, true)
+	if v == nil {
+		return ErrNotAffected
+	}
+	s.revise(v, models.ActionRestore)
+	v.Deleted, v.DeletedAt = false, nil
+	v.Version, v.UpdatedAt = v.Version+1, now()
+	return nil
+}
+
+// Purge permanently removes song from memory.
+func (s *memory) Purge(ctx context.Context, id string, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, v := range s.songs {
+		if v.ID == id {
+			if version != 0 && v.Version != version {
+				return ErrVersionMismatch
+			}
+			s.songs = append(s.songs[:i], s.songs[i+1:]...)
+			s.forget(id)
+			return nil
+		}
+	}
+	return ErrNotAffected
+}
+
+// forget removes revisions and playlist positions of purged song.
+func (s *memory) forget(id string) {
+	delete(s.revs, id)
+	for _, p := range s.playlists {
+		delete(p.songs, id)
+	}
+}
+
+// PurgeDeleted permanently removes songs deleted before specified time
+// and returns their count.
+func (s *memory) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	songs := s.songs[:0]
+	for _, v := range s.songs {
+		if v.Deleted && v.DeletedAt.Before(before) {
+			s.forget(v.ID)
+			continue
+		}
+		songs = append(songs, v)
+	}
+	n := int64(len(s.songs) - len(songs))
+	clear(s.songs[len(songs):])
+	s.songs = songs
+	return n, nil
+}
+
+// GetRevisions returns song revisions including deleted songs ones.
+func (s *memory) GetRevisions(ctx context.Context, id string) ([]models.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.find(id, false) == nil && s.find(id, true) == nil {
+		return nil, sql.ErrNoRows
+	}
+	return append(make([]models.Revision, 0, len(s.revs[id])), s.revs[id]...), nil
+}
+
+// GetRevision returns song revision.
+func (s *memory) GetRevision(ctx context.Context, id string, rev int) (models.Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	revs := s.revs[id]
+	if rev < 1 || rev > len(revs) {
+		return models.Revision{}, sql.ErrNoRows
+	}
+	return revs[rev-1], nil
+}
+
+// GetText returns song text.
+func (s *memory) GetText(ctx context.Context, id string,
+	page, size int) (models.ResponseGetSongText, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v := s.find(id, false)
+	if v == nil {
+		return models.ResponseGetSongText{}, sql.ErrNoRows
+	}
+	return verses(models.ResponseGetSongText{
+		ID:      id,
+		Group:   v.Group,
+		Song:    v.Song,
+		Page:    page,
+		Size:    size,
+		Version: v.Version,
+	}, v.Text), nil
+}
+
+// matches reports whether value s matches filter value v in mode.
+func matches(mode, s, v string) bool {
+	switch mode {
+	case models.MatchPrefix:
+		return strings.HasPrefix(s, v)
+	case models.MatchContains:
+		return strings.Contains(s, v)
+	case models.MatchCI:
+		return strings.Contains(strings.ToLower(s), strings.ToLower(v))
+	}
+	return s == v
+}
+
+// matchSong reports whether song satisfies filter, release date range
+// and deleted mode.
+func matchSong(v models.Song, r models.RequestGetSongs) bool {
+	d, deleted := r.Filter, r.Deleted
+	switch {
+	case deleted == models.DeletedOnly && !v.Deleted,
+		deleted != models.DeletedOnly && deleted != models.DeletedInclude && v.Deleted:
+		return false
+	case !r.ReleasedFrom.IsZero() && v.ReleaseDate.Before(date(r.ReleasedFrom)),
+		!r.ReleasedTo.IsZero() && !v.ReleaseDate.Before(date(r.ReleasedTo)):
+		return false
+	case d.ID != `` && v.ID != d.ID,
+		d.ArtistID != `` && v.ArtistID != d.ArtistID,
+		d.AlbumID != `` && v.AlbumID != d.AlbumID,
+		d.Group != `` && !matches(cmp.Or(r.Match.Group, models.MatchExact), v.Group, d.Group),
+		d.Song != `` && !matches(cmp.Or(r.Match.Song, models.MatchExact), v.Song, d.Song),
+		!d.ReleaseDate.IsZero() && !v.ReleaseDate.Equal(date(d.ReleaseDate)),
+		d.Text != `` && !matches(cmp.Or(r.Match.Text, models.MatchContains), v.Text, d.Text),
+		d.Link != `` && v.Link != d.Link:
+		return false
+	}
+	if tt := tagNames(d.Tags); len(tt) > 0 {
+		n := 0
+		for _, t := range tt {
+			if slices.ContainsFunc(v.Tags, func(o string) bool { return strings.EqualFold(o, t) }) {
+				n++
+			}
+		}
+		if n == 0 || r.Match.Tags == models.MatchAll && n < len(tt) {
+			return false
+		}
+	}
+	return true
+}
+
+// before reports whether song a precedes song b in sort order.
+func before(a, b models.Song, order []models.SortKey) bool {
+	for _, k := range cursor.Order(order) {
+		var c int
+		switch x := cursor.Value(a, k.Column).(type) {
+		case string:
+			c = strings.Compare(x, cursor.Value(b, k.Column).(string))
+		case time.Time:
+			c = x.Compare(cursor.Value(b, k.Column).(time.Time))
+		}
+		if c != 0 {
+			return c < 0 != k.Desc
+		}
+	}
+	return a.ID < b.ID
+}
+
+// last returns song with sort columns values of cursor.
+func last(c models.Cursor, order []models.SortKey) models.Song {
+	d := models.Song{ID: c.ID}
+	for i, v := range cursor.Values(c, order) {
+		switch cursor.Order(order)[i].Column {
+		case models.SortGroup:
+			d.Group = v.(string)
+		case models.SortSong:
+			d.Song = v.(string)
+		case models.SortReleaseDate:
+			d.ReleaseDate = v.(time.Time)
+		case models.SortCreated:
+			d.CreatedAt = v.(time.Time)
+		case models.SortUpdated:
+			d.UpdatedAt = v.(time.Time)
+		}
+	}
+	return d
+}
+
+// GetSongs returns filtered and paginated songs with total count of
+// filtered songs.
+func (s *memory) GetSongs(ctx context.Context,
+	r models.RequestGetSongs) (models.ResponseGetSongs, error) {
+	page, size := r.Page, r.Size
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var dd []models.Song
+	for _, v := range s.songs {
+		if matchSong(*v, r) {
+			dd = append(dd, *v)
+		}
+	}
+	res := models.ResponseGetSongs{Page: page, Size: size, Total: len(dd), Pages: pages(len(dd), size)}
+	if r.CountOnly {
+		return res, nil
+	}
+	sort.Slice(dd, func(i, j int) bool { return before(dd[i], dd[j], r.Sort) })
+	if c := r.Cursor; c != nil {
+		l := last(*c, r.Sort)
+		i := sort.Search(len(dd), func(i int) bool { return before(l, dd[i], r.Sort) })
+		res.Songs = dd[i:min(len(dd), i+size+1)] // extra song tells next page exists
+	} else if skip := (page - 1) * size; skip < len(dd) {
+		res.Songs = dd[skip:min(len(dd), skip+size)]
+	}
+	return next(r, res), nil
+}
+
+// Search is not supported, full text search requires postgres.
+func (s *memory) Search(ctx context.Context, r models.RequestSearch) (models.ResponseSearch, error) {
+	return models.ResponseSearch{}, ErrNotSupported
+}
+
+// Suggest returns songs with group or song names similar to query, most
+// similar first.
+func (s *memory) Suggest(ctx context.Context, q string, limit int) ([]models.Suggestion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var dd []models.Suggestion
+	for _, v := range s.songs {
+		if !v.Deleted {
+			dd = append(dd, models.Suggestion{ID: v.ID, Group: v.Group, Song: v.Song})
+		}
+	}
+	return suggest(q, dd, limit), nil
+}
+
+// named returns artist having name or alias equal to name ignoring case.
+func (s *memory) named(name string) *models.Artist {
+	for _, a := range s.artists {
+		if strings.EqualFold(a.Name, name) {
+			return a
+		}
+		for _, v := range a.Aliases {
+			if strings.EqualFold(v, name) {
+				return a
+			}
+		}
+	}
+	return nil
+}
+
+// taken reports whether canonical name or alias of artist belongs to
+// other artist.
+func (s *memory) taken(a models.Artist) bool {
+	for _, name := range append([]string{a.Name}, a.Aliases...) {
+		if o := s.named(name); o != nil && o.ID != a.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// copyArtist returns artist copy not sharing aliases.
+func copyArtist(a *models.Artist) models.Artist {
+	v := *a
+	v.Aliases = append(make([]string, 0, len(a.Aliases)), a.Aliases...)
+	return v
+}
+
+// songArtist returns artist named group or new artist kept by keepArtist
+// once song is saved.
+func (s *memory) songArtist(group string) *models.Artist {
+	if a := s.named(group); a != nil {
+		return a
+	}
+	at := now()
+	return &models.Artist{ID: uuid.New().String(), Name: group, Aliases: []string{},
+		CreatedAt: at, UpdatedAt: at}
+}
+
+// keepArtist saves song artist unless it is saved already.
+func (s *memory) keepArtist(a *models.Artist) {
+	if a != nil && !slices.Contains(s.artists, a) {
+		s.artists = append(s.artists, a)
+	}
+}
+
+// AddArtist creates artist in memory.
+func (s *memory) AddArtist(ctx context.Context, a models.Artist) (models.Artist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a.ID, a.Aliases = uuid.New().String(), aliases(a)
+	slices.SortFunc(a.Aliases, strings.Compare)
+	if s.taken(a) {
+		return models.Artist{}, ErrUniqueViolation
+	}
+	a.CreatedAt = now()
+	a.UpdatedAt = a.CreatedAt
+	v := copyArtist(&a)
+	s.artists = append(s.artists, &v)
+	return a, nil
+}
+
+// GetArtist returns artist with aliases.
+func (s *memory) GetArtist(ctx context.Context, id string) (models.Artist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, a := range s.artists {
+		if a.ID == id {
+			return copyArtist(a), nil
+		}
+	}
+	return models.Artist{}, sql.ErrNoRows
+}
+
+// GetArtists returns artists page ordered by name.
+func (s *memory) GetArtists(ctx context.Context, page, size int) (models.ResponseGetArtists, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	aa := make([]models.Artist, 0, len(s.artists))
+	for _, a := range s.artists {
+		aa = append(aa, copyArtist(a))
+	}
+	slices.SortFunc(aa, func(a, b models.Artist) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.ID, b.ID))
+	})
+	from := min((page-1)*size, len(aa))
+	return models.ResponseGetArtists{
+		Artists: aa[from:min(from+size, len(aa))],
+		Page:    page,
+		Size:    size,
+		Total:   len(aa),
+		Pages:   pages(len(aa), size),
+	}, nil
+}
+
+// ResolveArtist returns artist by canonical name or alias ignoring case.
+func (s *memory) ResolveArtist(ctx context.Context, name string) (models.Artist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if a := s.named(name); a != nil {
+		return copyArtist(a), nil
+	}
+	return models.Artist{}, sql.ErrNoRows
+}
+
+// UpdateArtist replaces artist data and aliases, renamed artist songs
+// get new group saving previous state as revision.
+func (s *memory) UpdateArtist(ctx context.Context, a models.Artist) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.artists, func(v *models.Artist) bool { return v.ID == a.ID })
+	if i < 0 {
+		return ErrNotAffected
+	}
+	a.Aliases = aliases(a)
+	slices.SortFunc(a.Aliases, strings.Compare)
+	if s.taken(a) {
+		return ErrUniqueViolation
+	}
+	var renamed []*models.Song
+	for _, v := range s.songs {
+		if v.ArtistID == a.ID && v.Group != a.Name {
+			renamed = append(renamed, v)
+		}
+	}
+	for _, v := range renamed { // songs_idx covers deleted songs too
+		for _, o := range s.songs {
+			if o.Group == a.Name && o.Song == v.Song && o.ArtistID != a.ID {
+				return ErrUniqueViolation
+			}
+		}
+	}
+	at := now()
+	for _, v := range renamed {
+		s.revise(v, models.ActionUpdate)
+		v.Group, v.Version, v.UpdatedAt = a.Name, v.Version+1, at
+	}
+	a.CreatedAt, a.UpdatedAt = s.artists[i].CreatedAt, at
+	s.artists[i] = &a
+	return nil
+}
+
+// DeleteArtist removes artist not referenced by songs and albums,
+// deleted songs included.
+func (s *memory) DeleteArtist(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range s.songs {
+		if v.ArtistID == id {
+			return ErrArtistInUse
+		}
+	}
+	for _, v := range s.albums {
+		if v.ArtistID == id {
+			return ErrArtistInUse
+		}
+	}
+	i := slices.IndexFunc(s.artists, func(v *models.Artist) bool { return v.ID == id })
+	if i < 0 {
+		return ErrNotAffected
+	}
+	s.artists = slices.Delete(s.artists, i, i+1)
+	return nil
+}
+
+// album returns album with artist name.
+func (s *memory) album(a *models.Album) models.Album {
+	v := *a
+	if i := slices.IndexFunc(s.artists, func(r *models.Artist) bool { return r.ID == a.ArtistID }); i >= 0 {
+		v.Artist = s.artists[i].Name
+	}
+	return v
+}
+
+// AddAlbum creates album in memory.
+func (s *memory) AddAlbum(ctx context.Context, a models.Album) (models.Album, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.ContainsFunc(s.albums, func(v *models.Album) bool {
+		return v.ArtistID == a.ArtistID && v.Title == a.Title
+	}) {
+		return models.Album{}, ErrUniqueViolation
+	}
+	a.ID = uuid.New().String()
+	if a.ReleaseDate != nil {
+		d := date(*a.ReleaseDate)
+		a.ReleaseDate = &d
+	}
+	a.CreatedAt = now()
+	a.UpdatedAt = a.CreatedAt
+	v := a
+	s.albums = append(s.albums, &v)
+	return s.album(&v), nil
+}
+
+// GetAlbum returns album.
+func (s *memory) GetAlbum(ctx context.Context, id string) (models.Album, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, a := range s.albums {
+		if a.ID == id {
+			return s.album(a), nil
+		}
+	}
+	return models.Album{}, sql.ErrNoRows
+}
+
+// GetAlbums returns albums page ordered by title, empty artist id lists
+// albums of all artists.
+func (s *memory) GetAlbums(ctx context.Context, artistID string,
+	page, size int) (models.ResponseGetAlbums, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	aa := make([]models.Album, 0, len(s.albums))
+	for _, a := range s.albums {
+		if artistID == `` || a.ArtistID == artistID {
+			aa = append(aa, s.album(a))
+		}
+	}
+	slices.SortFunc(aa, func(a, b models.Album) int {
+		return cmp.Or(strings.Compare(a.Title, b.Title), strings.Compare(a.ID, b.ID))
+	})
+	from := min((page-1)*size, len(aa))
+	return models.ResponseGetAlbums{
+		Albums: aa[from:min(from+size, len(aa))],
+		Page:   page,
+		Size:   size,
+		Total:  len(aa),
+		Pages:  pages(len(aa), size),
+	}, nil
+}
+
+// GetTracks returns not deleted album songs ordered by track number.
+func (s *memory) GetTracks(ctx context.Context, id string) ([]models.Song, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]models.Song, 0)
+	for _, v := range s.songs {
+		if v.AlbumID == id && !v.Deleted {
+			res = append(res, *v)
+		}
+	}
+	slices.SortFunc(res, func(a, b models.Song) int { return cmp.Compare(a.Track, b.Track) })
+	return res, nil
+}
+
+// AttachTrack places song on album position moving it from previous
+// album saving song state as revision, position taken by other song
+// returns ErrUniqueViolation.
+func (s *memory) AttachTrack(ctx context.Context, id, songID string, track int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.find(songID, false)
+	if v == nil || !slices.ContainsFunc(s.albums, func(a *models.Album) bool { return a.ID == id }) {
+		return ErrNotAffected
+	}
+	for _, o := range s.songs { // songs_track_idx covers deleted songs too
+		if o.AlbumID == id && o.Track == track && o.ID != songID {
+			return ErrUniqueViolation
+		}
+	}
+	s.revise(v, models.ActionUpdate)
+	v.AlbumID, v.Track, v.Version, v.UpdatedAt = id, track, v.Version+1, now()
+	return nil
+}
+
+// tag returns registered tag having name ignoring case.
+func (s *memory) tag(name string) *models.Tag {
+	for _, t := range s.tags {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// AddTags tags not deleted song creating missing tags of kind, existing
+// tags keep their kind, song state is saved as revision.
+func (s *memory) AddTags(ctx context.Context, id, kind string, names []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.find(id, false)
+	if v == nil {
+		return ErrNotAffected
+	}
+	tags := slices.Clone(v.Tags) // song copies returned earlier share tags
+	for _, name := range tagNames(names) {
+		t := s.tag(name)
+		if t == nil {
+			t = &models.Tag{Name: name, Kind: kind}
+			s.tags = append(s.tags, t)
+		}
+		if !slices.Contains(tags, t.Name) {
+			tags = append(tags, t.Name)
+		}
+	}
+	slices.Sort(tags)
+	s.revise(v, models.ActionUpdate)
+	v.Tags, v.Version, v.UpdatedAt = tags, v.Version+1, now()
+	return nil
+}
+
+// RemoveTags untags not deleted song saving its state as revision, tags
+// are kept.
+func (s *memory) RemoveTags(ctx context.Context, id string, names []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.find(id, false)
+	if v == nil {
+		return ErrNotAffected
+	}
+	tags := slices.DeleteFunc(slices.Clone(v.Tags), func(t string) bool {
+		return slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, t) })
+	})
+	if len(tags) == 0 {
+		tags = nil
+	}
+	s.revise(v, models.ActionUpdate)
+	v.Tags, v.Version, v.UpdatedAt = tags, v.Version+1, now()
+	return nil
+}
+
+// GetTags returns tags of kind ordered by name with numbers of not
+// deleted songs tagged, empty kind lists tags of all kinds.
+func (s *memory) GetTags(ctx context.Context, kind string) ([]models.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]models.Tag, 0, len(s.tags))
+	for _, t := range s.tags {
+		if kind != `` && t.Kind != kind {
+			continue
+		}
+		d := *t
+		for _, v := range s.songs {
+			if !v.Deleted && slices.Contains(v.Tags, t.Name) {
+				d.Songs++
+			}
+		}
+		res = append(res, d)
+	}
+	slices.SortFunc(res, func(a, b models.Tag) int { return strings.Compare(a.Name, b.Name) })
+	return res, nil
+}
+
+// playlist keeps playlist songs positions the way playlist_songs does.
+type playlist struct {
+	models.Playlist
+	songs map[string]int // positions by song id
+}
+
+// listed returns ids of not deleted playlist songs in playlist order.
+func (s *memory) listed(p *playlist) []string {
+	res := make([]string, 0, len(p.songs))
+	for id := range p.songs {
+		if s.find(id, false) != nil {
+			res = append(res, id)
+		}
+	}
+	slices.SortFunc(res, func(a, b string) int { return cmp.Compare(p.songs[a], p.songs[b]) })
+	return res
+}
+
+// slot returns position of listed song at requested one based position,
+// zero or position past listed songs returns position after last song.
+func (s *memory) slot(p *playlist, position int) (int, bool) {
+	if ids := s.listed(p); position > 0 && position <= len(ids) {
+		return p.songs[ids[position-1]], true
+	}
+	last := 0
+	for _, pos := range p.songs {
+		last = max(last, pos)
+	}
+	return last + 1, false
+}
+
+// shift shifts playlist positions in range by delta.
+func (p *playlist) shift(from, to, delta int) {
+	for id, pos := range p.songs {
+		if pos >= from && pos <= to {
+			p.songs[id] = pos + delta
+		}
+	}
+}
+
+// playlist returns playlist by id.
+func (s *memory) playlist(id string) *playlist {
+	for _, p := range s.playlists {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// AddPlaylist creates empty playlist.
+func (s *memory) AddPlaylist(ctx context.Context, d models.Playlist) (models.Playlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d.ID = uuid.New().String()
+	d.CreatedAt = now()
+	d.UpdatedAt = d.CreatedAt
+	s.playlists = append(s.playlists, &playlist{Playlist: d, songs: make(map[string]int)})
+	return d, nil
+}
+
+// GetPlaylist returns playlist.
+func (s *memory) GetPlaylist(ctx context.Context, id string) (models.Playlist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if p := s.playlist(id); p != nil {
+		return p.Playlist, nil
+	}
+	return models.Playlist{}, sql.ErrNoRows
+}
+
+// GetPlaylists returns playlists page ordered by name.
+func (s *memory) GetPlaylists(ctx context.Context, page, size int) (models.ResponseGetPlaylists, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pp := make([]models.Playlist, 0, len(s.playlists))
+	for _, p := range s.playlists {
+		pp = append(pp, p.Playlist)
+	}
+	slices.SortFunc(pp, func(a, b models.Playlist) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.ID, b.ID))
+	})
+	from := min((page-1)*size, len(pp))
+	return models.ResponseGetPlaylists{
+		Playlists: pp[from:min(from+size, len(pp))],
+		Page:      page,
+		Size:      size,
+		Total:     len(pp),
+		Pages:     pages(len(pp), size),
+	}, nil
+}
+
+// RenamePlaylist changes playlist name.
+func (s *memory) RenamePlaylist(ctx context.Context, id, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.playlist(id)
+	if p == nil {
+		return ErrNotAffected
+	}
+	p.Name, p.UpdatedAt = name, now()
+	return nil
+}
+
+// DeletePlaylist removes playlist, songs are kept.
+func (s *memory) DeletePlaylist(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.playlists, func(p *playlist) bool { return p.ID == id })
+	if i < 0 {
+		return ErrNotAffected
+	}
+	s.playlists = slices.Delete(s.playlists, i, i+1)
+	return nil
+}
+
+// GetPlaylistSongs returns page of not deleted playlist songs in playlist
+// order.
+func (s *memory) GetPlaylistSongs(ctx context.Context, id string,
+	page, size int) (models.ResponseGetPlaylist, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := models.ResponseGetPlaylist{Page: page, Size: size, Songs: make([]models.Song, 0)}
+	p := s.playlist(id)
+	if p == nil {
+		return res, nil
+	}
+	ids := s.listed(p)
+	res.Total, res.Pages = len(ids), pages(len(ids), size)
+	from := min((page-1)*size, len(ids))
+	for _, v := range ids[from:min(from+size, len(ids))] {
+		res.Songs = append(res.Songs, *s.find(v, false))
+	}
+	return res, nil
+}
+
+// AddPlaylistSong puts not deleted song on playlist position, song
+// already in playlist returns ErrUniqueViolation.
+func (s *memory) AddPlaylistSong(ctx context.Context, id, songID string, position int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.playlist(id)
+	if p == nil || s.find(songID, false) == nil {
+		return ErrNotAffected
+	}
+	if _, ok := p.songs[songID]; ok {
+		return ErrUniqueViolation
+	}
+	pos, taken := s.slot(p, position)
+	if taken {
+		p.shift(pos, maxPosition, 1)
+	}
+	p.songs[songID], p.UpdatedAt = pos, now()
+	return nil
+}
+
+// MovePlaylistSong moves not deleted playlist song to position.
+func (s *memory) MovePlaylistSong(ctx context.Context, id, songID string, position int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.playlist(id)
+	if p == nil {
+		return ErrNotAffected
+	}
+	old, ok := p.songs[songID]
+	if !ok || s.find(songID, false) == nil {
+		return ErrNotAffected
+	}
+	pos, taken := s.slot(p, position)
+	if !taken {
+		pos-- // last position
+	}
+	switch {
+	case pos < old:
+		p.shift(pos, old-1, 1)
+	case pos > old:
+		p.shift(old+1, pos, -1)
+	}
+	p.songs[songID], p.UpdatedAt = pos, now()
+	return nil
+}
+
+// RemovePlaylistSong removes song from playlist.
+func (s *memory) RemovePlaylistSong(ctx context.Context, id, songID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.playlist(id)
+	if p == nil {
+		return ErrNotAffected
+	}
+	pos, ok := p.songs[songID]
+	if !ok {
+		return ErrNotAffected
+	}
+	delete(p.songs, songID)
+	p.shift(pos+1, maxPosition, -1)
+	p.UpdatedAt = now()
+	return nil
+}
+
+// AddAPIKey keeps API key hash.
+func (s *memory) AddAPIKey(ctx context.Context, d models.APIKey) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d.ID = uuid.New().String()
+	d.CreatedAt = now()
+	s.keys = append(s.keys, d)
+	return d, nil
+}
+
+// GetAPIKey returns API key by its hash.
+func (s *memory) GetAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i := slices.IndexFunc(s.keys, func(k models.APIKey) bool { return k.Hash == hash }); i >= 0 {
+		return s.keys[i], nil
+	}
+	return models.APIKey{}, sql.ErrNoRows
+}
+
+// GetAPIKeys returns API keys in creation order.
+func (s *memory) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(make([]models.APIKey, 0, len(s.keys)), s.keys...), nil
+}
+
+// DeleteAPIKey revokes API key.
+func (s *memory) DeleteAPIKey(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.keys, func(k models.APIKey) bool { return k.ID == id })
+	if i < 0 {
+		return ErrNotAffected
+	}
+	s.keys = slices.Delete(s.keys, i, i+1)
+	return nil
+}
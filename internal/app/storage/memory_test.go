@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_date(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{name: "positive test #1", t: time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC), want: time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "positive test #2", t: time.Date(2006, 7, 16, 23, 59, 0, 0, msk), want: time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "positive test #3", t: time.Time{}, want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := date(tt.t); !got.Equal(tt.want) {
+				t.Errorf("date() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
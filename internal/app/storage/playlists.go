@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// playlistQueries holds dialect queries changing playlist songs order.
+// Positions are kept over all playlist songs, deleted songs included, and
+// requested positions count listed songs only.
+type playlistQueries struct {
+	touch   string // sets playlist update time, args id, time
+	song    string // counts not deleted songs, args song id
+	listed  string // counts song in playlist, args id, song id
+	slot    string // position of listed song, args id, offset
+	last    string // last position, args id
+	shift   string // shifts positions in range by delta, args id, from, to, delta
+	insert  string // args id, song id, position
+	current string // position of listed song, args id, song id
+	move    string // args id, song id, position
+	remove  string // returns position of removed song, args id, song id
+}
+
+// slot returns position of listed song at requested one based position,
+// zero or position past listed songs returns position after last song.
+func slot(ctx context.Context, tx *sql.Tx, q playlistQueries, id string, position int) (int, bool, error) {
+	var pos int
+	if position > 0 {
+		err := tx.QueryRowContext(ctx, q.slot, id, position-1).Scan(&pos)
+		if err == nil {
+			return pos, true, nil
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return 0, false, err
+		}
+	}
+	if err := tx.QueryRowContext(ctx, q.last, id).Scan(&pos); err != nil {
+		return 0, false, err
+	}
+	return pos + 1, false, nil
+}
+
+// addPlaylistSong puts not deleted song on playlist position.
+func addPlaylistSong(ctx context.Context, conn *sql.DB, q playlistQueries,
+	id, songID string, position int) error {
+	return transact(ctx, conn, func(tx *sql.Tx) error {
+		if err := affected(tx.ExecContext(ctx, q.touch, id, now())); err != nil {
+			return err
+		}
+		var n int
+		if err := tx.QueryRowContext(ctx, q.song, songID).Scan(&n); err != nil {
+			return err
+		}
+		if n == 0 {
+			return ErrNotAffected
+		}
+		if err := tx.QueryRowContext(ctx, q.listed, id, songID).Scan(&n); err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrUniqueViolation
+		}
+		pos, taken, err := slot(ctx, tx, q, id, position)
+		if err != nil {
+			return err
+		}
+		if taken {
+			if _, err := tx.ExecContext(ctx, q.shift, id, pos, maxPosition, 1); err != nil {
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, q.insert, id, songID, pos)
+		return err
+	})
+}
+
+// maxPosition bounds open ended positions range.
+const maxPosition = 1<<31 - 1
+
+// movePlaylistSong moves listed song to playlist position.
+func movePlaylistSong(ctx context.Context, conn *sql.DB, q playlistQueries,
+	id, songID string, position int) error {
+	return transact(ctx, conn, func(tx *sql.Tx) error {
+		if err := affected(tx.ExecContext(ctx, q.touch, id, now())); err != nil {
+			return err
+		}
+		var old int
+		if err := tx.QueryRowContext(ctx, q.current, id, songID).Scan(&old); errors.Is(err, sql.ErrNoRows) {
+			return ErrNotAffected
+		} else if err != nil {
+			return err
+		}
+		pos, taken, err := slot(ctx, tx, q, id, position)
+		if err != nil {
+			return err
+		}
+		if !taken {
+			pos-- // last position
+		}
+		switch {
+		case pos < old:
+			_, err = tx.ExecContext(ctx, q.shift, id, pos, old-1, 1)
+		case pos > old:
+			_, err = tx.ExecContext(ctx, q.shift, id, old+1, pos, -1)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, q.move, id, songID, pos)
+		return err
+	})
+}
+
+// removePlaylistSong removes song from playlist closing its position.
+func removePlaylistSong(ctx context.Context, conn *sql.DB, q playlistQueries, id, songID string) error {
+	return transact(ctx, conn, func(tx *sql.Tx) error {
+		if err := affected(tx.ExecContext(ctx, q.touch, id, now())); err != nil {
+			return err
+		}
+		var pos int
+		if err := tx.QueryRowContext(ctx, q.remove, id, songID).Scan(&pos); errors.Is(err, sql.ErrNoRows) {
+			return ErrNotAffected
+		} else if err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, q.shift, id, pos+1, maxPosition, -1)
+		return err
+	})
+}
+
+// scanPlaylist reads playlist from query result row.
+func scanPlaylist(row interface{ Scan(dest ...any) error }) (models.Playlist, error) {
+	var d models.Playlist
+	if err := row.Scan(&d.ID, &d.Name, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return models.Playlist{}, err
+	}
+	d.CreatedAt, d.UpdatedAt = d.CreatedAt.UTC(), d.UpdatedAt.UTC()
+	return d, nil
+}
+
+// queryPlaylists runs playlists query and reads playlists.
+func queryPlaylists(ctx context.Context, conn *sql.DB, q string, args ...any) ([]models.Playlist, error) {
+	rows, err := conn.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	res := make([]models.Playlist, 0)
+	for rows.Next() {
+		d, err := scanPlaylist(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+// pgPlaylist holds postgres playlist songs order queries.
+var pgPlaylist = playlistQueries{
+	touch:  `update playlists set updated_at=$2 where id=$1`,
+	song:   `select count(*) from songs where id=$1 and deleted=False`,
+	listed: `select count(*) from playlist_songs where playlist_id=$1 and song_id=$2`,
+	slot: `
+select p.position from playlist_songs p join songs s on s.id=p.song_id
+where p.playlist_id=$1 and s.deleted=False order by p.position offset $2 limit 1
+`,
+	last:   `select coalesce(max(position), 0) from playlist_songs where playlist_id=$1`,
+	shift:  `update playlist_songs set position=position+$4 where playlist_id=$1 and position between $2 and $3`,
+	insert: `insert into playlist_songs (playlist_id, song_id, position) values ($1, $2, $3)`,
+	current: `
+select p.position from playlist_songs p join songs s on s.id=p.song_id
+where p.playlist_id=$1 and p.song_id=$2 and s.deleted=False
+`,
+	move:   `update playlist_songs set position=$3 where playlist_id=$1 and song_id=$2`,
+	remove: `delete from playlist_songs where playlist_id=$1 and song_id=$2 returning position`,
+}
+
+const (
+	queryInsertPlaylist  = `insert into playlists (id, name, created_at, updated_at) values ($1, $2, $3, $3)`
+	querySelectPlaylist  = `select id, name, created_at, updated_at from playlists where id=$1`
+	querySelectPlaylists = `
+select id, name, created_at, updated_at from playlists order by name, id limit $1 offset $2
+`
+	queryCountPlaylists = `select count(*) from playlists`
+	queryRenamePlaylist = `update playlists set name=$2, updated_at=now() where id=$1`
+	queryDeletePlaylist = `delete from playlists where id=$1`
+	// playlist_songs has no columns named as songs ones
+	queryCountPlaylistSongs = `
+select count(*) from playlist_songs p join songs on songs.id=p.song_id where p.playlist_id=$1 and deleted=False
+`
+	querySelectPlaylistSongs = `
+select ` + songColumns + ` from playlist_songs p join songs on songs.id=p.song_id
+where p.playlist_id=$1 and deleted=False order by p.position limit $2 offset $3
+`
+)
+
+// AddPlaylist creates empty playlist.
+func (s *db) AddPlaylist(ctx context.Context, d models.Playlist) (models.Playlist, error) {
+	d.ID = uuid.New().String()
+	d.CreatedAt = now()
+	d.UpdatedAt = d.CreatedAt
+	if _, err := s.conn.ExecContext(ctx, queryInsertPlaylist, d.ID, d.Name, d.CreatedAt); err != nil {
+		return models.Playlist{}, err
+	}
+	return d, nil
+}
+
+// GetPlaylist returns playlist.
+func (s *db) GetPlaylist(ctx context.Context, id string) (models.Playlist, error) {
+	return scanPlaylist(s.conn.QueryRowContext(ctx, querySelectPlaylist, id))
+}
+
+// GetPlaylists returns playlists page ordered by name.
+func (s *db) GetPlaylists(ctx context.Context, page, size int) (models.ResponseGetPlaylists, error) {
+	res := models.ResponseGetPlaylists{Page: page, Size: size}
+	if err := s.conn.QueryRowContext(ctx, queryCountPlaylists).Scan(&res.Total); err != nil {
+		return models.ResponseGetPlaylists{}, err
+	}
+	res.Pages = pages(res.Total, size)
+	var err error
+	if res.Playlists, err = queryPlaylists(ctx, s.conn, querySelectPlaylists, size, (page-1)*size); err != nil {
+		return models.ResponseGetPlaylists{}, err
+	}
+	return res, nil
+}
+
+// RenamePlaylist changes playlist name.
+func (s *db) RenamePlaylist(ctx context.Context, id, name string) error {
+	return affected(s.conn.ExecContext(ctx, queryRenamePlaylist, id, name))
+}
+
+// DeletePlaylist removes playlist, songs are kept.
+func (s *db) DeletePlaylist(ctx context.Context, id string) error {
+	return affected(s.conn.ExecContext(ctx, queryDeletePlaylist, id))
+}
+
+// GetPlaylistSongs returns page of not deleted playlist songs in playlist
+// order.
+func (s *db) GetPlaylistSongs(ctx context.Context, id string,
+	page, size int) (models.ResponseGetPlaylist, error) {
+	res := models.ResponseGetPlaylist{Page: page, Size: size}
+	if err := s.conn.QueryRowContext(ctx, queryCountPlaylistSongs, id).Scan(&res.Total); err != nil {
+		return models.ResponseGetPlaylist{}, err
+	}
+	res.Pages = pages(res.Total, size)
+	var err error
+	if res.Songs, err = querySongs(ctx, s.conn, scanSong, querySelectPlaylistSongs,
+		id, size, (page-1)*size); err != nil {
+		return models.ResponseGetPlaylist{}, err
+	}
+	return res, nil
+}
+
+// AddPlaylistSong puts not deleted song on playlist position, song
+// already in playlist returns ErrUniqueViolation.
+func (s *db) AddPlaylistSong(ctx context.Context, id, songID string, position int) error {
+	return addPlaylistSong(ctx, s.conn, pgPlaylist, id, songID, position)
+}
+
+// MovePlaylistSong moves not deleted playlist song to position.
+func (s *db) MovePlaylistSong(ctx context.Context, id, songID string, position int) error {
+	return movePlaylistSong(ctx, s.conn, pgPlaylist, id, songID, position)
+}
+
+// RemovePlaylistSong removes song from playlist.
+func (s *db) RemovePlaylistSong(ctx context.Context, id, songID string) error {
+	return removePlaylistSong(ctx, s.conn, pgPlaylist, id, songID)
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/xEgorka/project4/internal/app/config"
+)
+
+func Test_db_AddPlaylistSong(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	id, songID := "1", "2"
+	tests := []struct {
+		name     string
+		position int
+		touched  int64
+		songs    int
+		listed   int
+		wantErr  error
+	}{
+		{name: "positive test #1", position: 1, touched: 1, songs: 1},
+		{name: "positive test #2", touched: 1, songs: 1},
+		{name: "negative test #1", wantErr: ErrNotAffected},
+		{name: "negative test #2", touched: 1, wantErr: ErrNotAffected},
+		{name: "negative test #3", touched: 1, songs: 1, listed: 1, wantErr: ErrUniqueViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(pgPlaylist.touch)).WithArgs(id, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, tt.touched))
+			if tt.touched > 0 {
+				mock.ExpectQuery(regexp.QuoteMeta(pgPlaylist.song)).WithArgs(songID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.songs))
+			}
+			if tt.songs > 0 {
+				mock.ExpectQuery(regexp.QuoteMeta(pgPlaylist.listed)).WithArgs(id, songID).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.listed))
+			}
+			if tt.wantErr == nil {
+				if tt.position > 0 {
+					mock.ExpectQuery(regexp.QuoteMeta(pgPlaylist.slot)).WithArgs(id, tt.position-1).
+						WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(3))
+					mock.ExpectExec(regexp.QuoteMeta(pgPlaylist.shift)).WithArgs(id, 3, maxPosition, 1).
+						WillReturnResult(sqlmock.NewResult(0, 2))
+					mock.ExpectExec(regexp.QuoteMeta(pgPlaylist.insert)).WithArgs(id, songID, 3).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				} else {
+					mock.ExpectQuery(regexp.QuoteMeta(pgPlaylist.last)).WithArgs(id).
+						WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(4))
+					mock.ExpectExec(regexp.QuoteMeta(pgPlaylist.insert)).WithArgs(id, songID, 5).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+			if err := s.AddPlaylistSong(context.Background(), id, songID, tt.position); err != tt.wantErr {
+				t.Errorf("db.AddPlaylistSong() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_db_RemovePlaylistSong(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	id, songID := "1", "2"
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "positive test #1"},
+		{name: "negative test #1", err: sql.ErrNoRows, wantErr: ErrNotAffected},
+		{name: "negative test #2", err: errors.New("test"), wantErr: errors.New("test")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(pgPlaylist.touch)).WithArgs(id, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			q := mock.ExpectQuery(regexp.QuoteMeta(pgPlaylist.remove)).WithArgs(id, songID)
+			if tt.err != nil {
+				q.WillReturnError(tt.err)
+				mock.ExpectRollback()
+			} else {
+				q.WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(2))
+				mock.ExpectExec(regexp.QuoteMeta(pgPlaylist.shift)).WithArgs(id, 3, maxPosition, -1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+			err := s.RemovePlaylistSong(context.Background(), id, songID)
+			if (err != nil) != (tt.wantErr != nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("db.RemovePlaylistSong() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// transact runs fn in single transaction committed if fn succeeds.
+func transact(ctx context.Context, conn *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.Log.Error("failed rollback", zap.Error(err))
+		}
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// revisionQueries holds dialect queries saving song states as revisions.
+// Songs are locked before next revision number is read, so concurrent
+// changes of song take revision numbers in turn.
+type revisionQueries struct {
+	lock          string // locks song returning version and deleted flag, args id
+	insert        string // saves song state as next revision, args id, action, time
+	lockRenamed   string // locks artist songs to rename, args artist id, name, empty skips lock
+	insertRenamed string // saves artist songs to rename states, args artist id, name, action, time
+}
+
+// revise locks song and saves its current state as revision of action,
+// song missing or differing from deleted state returns ErrNotAffected,
+// zero version skips version check.
+func revise(ctx context.Context, tx *sql.Tx, q revisionQueries,
+	id, action string, version int, deleted bool) error {
+	var v int
+	var d bool
+	if err := tx.QueryRowContext(ctx, q.lock, id).Scan(&v, &d); errors.Is(err, sql.ErrNoRows) {
+		return ErrNotAffected
+	} else if err != nil {
+		return err
+	}
+	if d != deleted {
+		return ErrNotAffected
+	}
+	if version != 0 && v != version {
+		return ErrVersionMismatch
+	}
+	_, err := tx.ExecContext(ctx, q.insert, id, action, now())
+	return err
+}
+
+// reviseRenamed saves states of artist songs having group other than name
+// as revisions, deleted songs included.
+func reviseRenamed(ctx context.Context, tx *sql.Tx, q revisionQueries, artistID, name string) error {
+	if len(q.lockRenamed) > 0 {
+		if _, err := tx.ExecContext(ctx, q.lockRenamed, artistID, name); err != nil {
+			return err
+		}
+	}
+	_, err := tx.ExecContext(ctx, q.insertRenamed, artistID, name, models.ActionUpdate, now())
+	return err
+}
+
+var pgRevision = revisionQueries{
+	lock: `select version, deleted from songs where id=$1 for update`,
+	insert: `
+insert into song_revisions (song_id, rev, "group", song, release_date, text, link, action, created_at)
+select id, coalesce((select max(rev) from song_revisions where song_id=$1), 0)+1,
+    "group", song, release_date, text, link, $2, $3
+from songs where id=$1
+`,
+	lockRenamed: `select id from songs where artist_id=$1 and "group"<>$2 for update`,
+	insertRenamed: `
+insert into song_revisions (song_id, rev, "group", song, release_date, text, link, action, created_at)
+select id, coalesce((select max(r.rev) from song_revisions r where r.song_id=songs.id), 0)+1,
+    "group", song, release_date, text, link, $3, $4
+from songs where artist_id=$1 and "group"<>$2
+`,
+}
+
+// liteRevision skips locks as sqlite connection is single writer.
+var liteRevision = revisionQueries{
+	lock: `select version, deleted from songs where id=?`,
+	insert: `
+insert into song_revisions (song_id, rev, "group", song, release_date, text, link, action, created_at)
+select id, coalesce((select max(rev) from song_revisions where song_id=?1), 0)+1,
+    "group", song, release_date, text, link, ?2, ?3
+from songs where id=?1
+`,
+	insertRenamed: `
+insert into song_revisions (song_id, rev, "group", song, release_date, text, link, action, created_at)
+select id, coalesce((select max(r.rev) from song_revisions r where r.song_id=songs.id), 0)+1,
+    "group", song, release_date, text, link, ?3, ?4
+from songs where artist_id=?1 and "group"<>?2
+`,
+}
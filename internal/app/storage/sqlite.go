@@ -0,0 +1,908 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/cursor"
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// openLite opens sqlite database file set by sqlite://path URI.
+func openLite(cfg *config.Config) (Storage, error) {
+	logger.Log.Info("opening sqlite database...", zap.String("conninfo", cfg.DBURI))
+	conn, err := sql.Open(cfg.DBDriver, liteDSN(cfg.DBURI))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMaxOpenConns(1) // sqlite allows single writer
+	if e := conn.Ping(); e != nil {
+		return nil, e
+	}
+	s := newLite(cfg, conn)
+	if err := bootstrap(conn, cfg.DBDriver); err != nil {
+		logger.Log.Error("failed bootstrap", zap.Error(err))
+		return nil, err
+	}
+	return s, nil
+}
+
+// liteDSN converts sqlite://path URI to driver data source name which
+// keeps times in format understood by sqlite date functions and enforces
+// foreign keys.
+func liteDSN(uri string) string {
+	const params = "_time_format=sqlite&_pragma=foreign_keys(1)"
+	dsn := strings.TrimPrefix(uri, "sqlite://")
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + params
+	}
+	return dsn + "?" + params
+}
+
+// lite implements Storage on embedded sqlite database.
+type lite struct {
+	conn *sql.DB
+	cfg  *config.Config
+}
+
+func newLite(config *config.Config, conn *sql.DB) *lite { return &lite{cfg: config, conn: conn} }
+
+// Ping checks database connection.
+func (s *lite) Ping() error { return s.conn.Ping() }
+
+// Close closes database connection.
+func (s *lite) Close() error { return s.conn.Close() }
+
+// uniqueViolation reports whether err is sqlite unique constraint failure.
+func uniqueViolation(err error) bool {
+	var e *sqlite.Error
+	return errors.As(err, &e) && e.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+const (
+	queryLiteInsertSong = `
+insert into songs (id, "group", song, release_date, text, link, created_at, updated_at, artist_id)
+values (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?7, ?8)
+`
+	queryLiteSelectSong = `select id from songs where "group"=? and song=? and deleted=false`
+)
+
+// Add creates song in database, song without artist gets artist of group
+// created if missing.
+func (s *lite) Add(ctx context.Context, song models.Song) (models.Song, error) {
+	id := uuid.New().String()
+	song.ReleaseDate = date(song.ReleaseDate)
+	at := now()
+	err := transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if len(song.ArtistID) == 0 && len(song.Group) > 0 {
+			a, err := songArtist(ctx, tx, liteSongArtist, song.Group)
+			if err != nil {
+				return err
+			}
+			song.Group, song.ArtistID = a.Name, a.ID
+		}
+		_, err := tx.ExecContext(ctx, queryLiteInsertSong, id,
+			song.Group, song.Song, song.ReleaseDate, song.Text, song.Link, at, nullString(song.ArtistID))
+		return err
+	})
+	if uniqueViolation(err) {
+		row := s.conn.QueryRowContext(ctx, queryLiteSelectSong, song.Group, song.Song)
+		var id string
+		if err := row.Scan(&id); err != nil {
+			return models.Song{}, err // ErrNoRows if song deleted
+		}
+		return models.Song{}, ErrUniqueViolation
+	} else if err != nil {
+		return models.Song{}, err
+	}
+	song.ID, song.Version, song.CreatedAt, song.UpdatedAt = id, 1, at, at
+	return song, nil
+}
+
+// scanLiteSong reads song from query result row.
+func scanLiteSong(row interface{ Scan(dest ...any) error }) (models.Song, error) {
+	var d models.Song
+	var deletedAt sql.NullTime
+	var tags string
+	if err := row.Scan(&d.ID, &d.Group, &d.Song, &d.ReleaseDate, &d.Text, &d.Link,
+		&d.Deleted, &deletedAt, &d.Version, &d.CreatedAt, &d.UpdatedAt, &d.ArtistID,
+		&d.AlbumID, &d.Track, &tags); err != nil {
+		return models.Song{}, err
+	}
+	d.ReleaseDate, d.DeletedAt, d.Tags = d.ReleaseDate.UTC(), nullTime(deletedAt), splitTags(tags)
+	d.CreatedAt, d.UpdatedAt = d.CreatedAt.UTC(), d.UpdatedAt.UTC()
+	return d, nil
+}
+
+const queryLiteSelectSongByID = `select ` + songColumns + ` from songs where id=? and deleted=false`
+
+// Get returns not deleted song.
+func (s *lite) Get(ctx context.Context, id string) (models.Song, error) {
+	return scanLiteSong(s.conn.QueryRowContext(ctx, queryLiteSelectSongByID, id))
+}
+
+const queryLiteSelectSongVersion = `select version from songs where id=? and deleted=false`
+
+// change saves current song state as revision and applies change in
+// single transaction, zero version skips version check.
+func (s *lite) change(ctx context.Context, id, action string, version int,
+	apply func(tx *sql.Tx) (sql.Result, error)) error {
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, liteRevision, id, action, version, false); err != nil {
+			return err
+		}
+		return affected(apply(tx))
+	})
+}
+
+const queryLiteUpdateSong = `
+update songs set release_date=?, text=?, link=?, version=version+1, updated_at=?
+where id=? and deleted=false
+`
+
+// Update updates song in library.
+func (s *lite) Update(ctx context.Context, id string, version int, d models.RequestUpdateSong) error {
+	return s.change(ctx, id, models.ActionUpdate, version, func(tx *sql.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, queryLiteUpdateSong,
+			date(d.ReleaseDate), d.Text, d.Link, now(), id)
+	})
+}
+
+// Patch updates song columns set by patch, group without artist gets
+// artist created if missing, renaming onto existing song returns
+// ErrUniqueViolation.
+func (s *lite) Patch(ctx context.Context, id string, version int, d models.RequestPatchSong) error {
+	if d == (models.RequestPatchSong{}) {
+		return checkVersion(s.conn.QueryRowContext(ctx, queryLiteSelectSongVersion, id), version)
+	}
+	err := s.change(ctx, id, models.ActionUpdate, version, func(tx *sql.Tx) (sql.Result, error) {
+		if d.Group != nil && d.ArtistID == nil {
+			a, err := songArtist(ctx, tx, liteSongArtist, *d.Group)
+			if err != nil {
+				return nil, err
+			}
+			d.Group, d.ArtistID = &a.Name, &a.ID
+		}
+		cols, args := patchColumns(d)
+		q := `update songs set ` + strings.Join(cols, `=?, `) +
+			`=?, version=version+1, updated_at=? where id=? and deleted=false`
+		logger.Log.Debug("executing", zap.String("query", q))
+		return tx.ExecContext(ctx, q, append(args, now(), id)...)
+	})
+	if uniqueViolation(err) {
+		return ErrUniqueViolation
+	}
+	return err
+}
+
+const queryLiteDeleteSong = `
+update songs set deleted=true, deleted_at=?1, version=version+1, updated_at=?1
+where id=?2 and deleted=false
+`
+
+// Delete soft deletes song from library.
+func (s *lite) Delete(ctx context.Context, id string, version int) error {
+	return s.change(ctx, id, models.ActionDelete, version, func(tx *sql.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, queryLiteDeleteSong, now(), id)
+	})
+}
+
+const queryLiteRestoreSong = `
+update songs set deleted=false, deleted_at=null, version=version+1, updated_at=?
+where id=? and deleted=true
+`
+
+// Restore brings soft deleted song back to library saving deleted state
+// as revision.
+func (s *lite) Restore(ctx context.Context, id string) error {
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, liteRevision, id, models.ActionRestore, 0, true); err != nil {
+			return err
+		}
+		return affected(tx.ExecContext(ctx, queryLiteRestoreSong, now(), id))
+	})
+}
+
+const (
+	queryLitePurgeSong        = `delete from songs where id=?1 and (?2=0 or version=?2)`
+	queryLiteSelectAnyVersion = `select version from songs where id=?`
+)
+
+// Purge permanently removes song from library.
+func (s *lite) Purge(ctx context.Context, id string, version int) error {
+	err := affected(s.conn.ExecContext(ctx, queryLitePurgeSong, id, version))
+	if err == ErrNotAffected && version != 0 {
+		if err := checkVersion(s.conn.QueryRowContext(ctx, queryLiteSelectAnyVersion, id), version); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+const queryLitePurgeDeleted = `delete from songs where deleted=true and deleted_at<?`
+
+// PurgeDeleted permanently removes songs deleted before specified time
+// and returns their count.
+func (s *lite) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.conn.ExecContext(ctx, queryLitePurgeDeleted, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+const (
+	queryLiteSelectSongID    = `select id from songs where id=?`
+	queryLiteSelectRevisions = `
+select rev, "group", song, release_date, text, link, action, created_at
+from song_revisions where song_id=? order by rev
+`
+	queryLiteSelectRevision = `
+select rev, "group", song, release_date, text, link, action, created_at
+from song_revisions where song_id=? and rev=?
+`
+)
+
+// GetRevisions returns song revisions including deleted songs ones.
+func (s *lite) GetRevisions(ctx context.Context, id string) ([]models.Revision, error) {
+	if err := s.conn.QueryRowContext(ctx, queryLiteSelectSongID, id).Scan(&id); err != nil {
+		return nil, err // ErrNoRows if song not found
+	}
+	rows, err := s.conn.QueryContext(ctx, queryLiteSelectRevisions, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	revs := make([]models.Revision, 0)
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// GetRevision returns song revision.
+func (s *lite) GetRevision(ctx context.Context, id string, rev int) (models.Revision, error) {
+	return scanRevision(s.conn.QueryRowContext(ctx, queryLiteSelectRevision, id, rev))
+}
+
+const queryLiteSelectSongText = `select "group", song, text, version from songs where id=? and deleted=false`
+
+// GetText returns song text.
+func (s *lite) GetText(ctx context.Context, id string,
+	page, size int) (models.ResponseGetSongText, error) {
+	row := s.conn.QueryRowContext(ctx, queryLiteSelectSongText, id)
+	var group, song, text string
+	var version int
+	if err := row.Scan(&group, &song, &text, &version); err != nil {
+		return models.ResponseGetSongText{}, err
+	}
+	return verses(models.ResponseGetSongText{
+		ID:      id,
+		Group:   group,
+		Song:    song,
+		Page:    page,
+		Size:    size,
+		Version: version,
+	}, text), nil
+}
+
+func init() {
+	// lower of sqlite folds ascii letters only
+	sqlite.MustRegisterDeterministicScalarFunction("casefold", 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			if v, ok := args[0].(string); ok {
+				return strings.ToLower(v), nil
+			}
+			return args[0], nil
+		})
+}
+
+// liteMatch returns condition of column matching single argument in
+// mode, like is avoided as case insensitive in sqlite.
+func liteMatch(column, mode string) string {
+	switch mode {
+	case models.MatchPrefix:
+		return `instr(` + column + `, ?)=1`
+	case models.MatchContains:
+		return `instr(` + column + `, ?)>0`
+	case models.MatchCI:
+		return `instr(casefold(` + column + `), casefold(?))>0`
+	}
+	return column + `=?`
+}
+
+// filterLiteSongs returns songs query condition and arguments for filter.
+func filterLiteSongs(r models.RequestGetSongs) (q string, args []any) {
+	d := r.Filter
+	q = whereDeleted(r.Deleted)
+	if d.ID != `` {
+		q += ` and id=?`
+		args = append(args, d.ID)
+	}
+	if d.ArtistID != `` {
+		q += ` and artist_id=?`
+		args = append(args, d.ArtistID)
+	}
+	if d.AlbumID != `` {
+		q += ` and album_id=?`
+		args = append(args, d.AlbumID)
+	}
+	if d.Group != `` {
+		q += ` and ` + liteMatch(`"group"`, cmp.Or(r.Match.Group, models.MatchExact))
+		args = append(args, d.Group)
+	}
+	if d.Song != `` {
+		q += ` and ` + liteMatch(`song`, cmp.Or(r.Match.Song, models.MatchExact))
+		args = append(args, d.Song)
+	}
+	if !d.ReleaseDate.IsZero() {
+		q += ` and release_date=?`
+		args = append(args, date(d.ReleaseDate))
+	}
+	if !r.ReleasedFrom.IsZero() {
+		q += ` and release_date>=?`
+		args = append(args, date(r.ReleasedFrom))
+	}
+	if !r.ReleasedTo.IsZero() {
+		q += ` and release_date<?`
+		args = append(args, date(r.ReleasedTo))
+	}
+	if d.Text != `` {
+		q += ` and ` + liteMatch(`text`, cmp.Or(r.Match.Text, models.MatchContains))
+		args = append(args, d.Text)
+	}
+	if d.Link != `` {
+		q += ` and link=?`
+		args = append(args, d.Link)
+	}
+	if tt := tagNames(d.Tags); len(tt) > 0 {
+		p := make([]string, len(tt))
+		for i, v := range tt {
+			p[i] = `?`
+			args = append(args, v)
+		}
+		q += ` and ` + tagged(r.Match.Tags, p)
+	}
+	return q, args
+}
+
+// GetSongs returns filtered and paginated songs with total count of
+// filtered songs.
+func (s *lite) GetSongs(ctx context.Context,
+	r models.RequestGetSongs) (models.ResponseGetSongs, error) {
+	page, size := r.Page, r.Size
+	where, args := filterLiteSongs(r)
+
+	q := `select count(*) from songs` + where
+	logger.Log.Debug("executing", zap.String("query", q))
+	var total int
+	if err := s.conn.QueryRowContext(ctx, q, args...).Scan(&total); err != nil {
+		return models.ResponseGetSongs{}, err
+	}
+	res := models.ResponseGetSongs{Page: page, Size: size, Total: total, Pages: pages(total, size)}
+	if r.CountOnly {
+		return res, nil
+	}
+
+	q = `select ` + songColumns + ` from songs` + where
+	if c := r.Cursor; c != nil {
+		p := make([]string, len(c.Keys)+1)
+		for i := range p {
+			p[i] = fmt.Sprintf(`?%d`, len(args)+1+i) // numbered to reuse values
+		}
+		q += keyset(r.Sort, p) + orderBy(r.Sort) + ` limit ?`
+		args = append(append(args, cursor.Values(*c, r.Sort)...), c.ID,
+			size+1) // extra song tells next page exists
+	} else {
+		q += orderBy(r.Sort) + ` limit ? offset ?`
+		args = append(args, size, (page-1)*size)
+	}
+	logger.Log.Debug("executing", zap.String("query", q))
+	rows, err := s.conn.QueryContext(ctx, q, args...)
+	if err != nil {
+		return models.ResponseGetSongs{}, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+
+	for rows.Next() {
+		d, err := scanLiteSong(rows)
+		if err != nil {
+			return models.ResponseGetSongs{}, err
+		}
+		res.Songs = append(res.Songs, d)
+	}
+	if err = rows.Err(); err != nil {
+		return models.ResponseGetSongs{}, err
+	}
+
+	return next(r, res), nil
+}
+
+// Search is not supported, full text search requires postgres.
+func (s *lite) Search(ctx context.Context, r models.RequestSearch) (models.ResponseSearch, error) {
+	return models.ResponseSearch{}, ErrNotSupported
+}
+
+const queryLiteSelectNames = `select id, "group", song from songs where deleted=false`
+
+// Suggest returns songs with group or song names similar to query, most
+// similar first, similarity is computed in process.
+func (s *lite) Suggest(ctx context.Context, q string, limit int) ([]models.Suggestion, error) {
+	rows, err := s.conn.QueryContext(ctx, queryLiteSelectNames)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	var dd []models.Suggestion
+	for rows.Next() {
+		var d models.Suggestion
+		if err := rows.Scan(&d.ID, &d.Group, &d.Song); err != nil {
+			return nil, err
+		}
+		dd = append(dd, d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return suggest(q, dd, limit), nil
+}
+
+const (
+	queryLiteInsertArtist = `
+insert into artists (id, name, country, formed_year, created_at, updated_at)
+values (?1, ?2, ?3, ?4, ?5, ?5)
+`
+	queryLiteInsertArtistName = `insert into artist_names (artist_id, name) values (?, ?)`
+	queryLiteSelectArtist     = `
+select ` + artistColumns + ` from artists a join artist_names n on n.artist_id=a.id
+where a.id=? order by n.name
+`
+	queryLiteSelectArtists = `
+select ` + artistColumns + `
+from (select * from artists order by name, id limit ? offset ?) a
+join artist_names n on n.artist_id=a.id
+order by a.name, a.id, n.name
+`
+	queryLiteResolveArtist    = `select artist_id from artist_names where name=?` // nocase column
+	queryLiteUpdateArtist     = `update artists set name=?2, country=?3, formed_year=?4, updated_at=?5 where id=?1`
+	queryLiteDeleteNames      = `delete from artist_names where artist_id=?`
+	queryLiteRenameSongs      = `update songs set "group"=?2, version=version+1, updated_at=?3 where artist_id=?1 and "group"<>?2`
+	queryLiteSelectArtistUsed = `
+select (select count(*) from songs where artist_id=?1) + (select count(*) from albums where artist_id=?1)
+`
+	queryLiteDeleteArtist = `delete from artists where id=?`
+)
+
+// liteSongArtist skips lock as sqlite connection is single writer.
+var liteSongArtist = songArtistQueries{
+	resolve: `
+select a.id, a.name from artist_names n join artists a on a.id=n.artist_id
+where n.name=?
+`,
+	insert:     queryLiteInsertArtist,
+	insertName: queryLiteInsertArtistName,
+}
+
+// AddArtist creates artist, name or alias taken by other artist returns
+// ErrUniqueViolation.
+func (s *lite) AddArtist(ctx context.Context, a models.Artist) (models.Artist, error) {
+	a.ID, a.Aliases = uuid.New().String(), aliases(a)
+	a.CreatedAt = now()
+	a.UpdatedAt = a.CreatedAt
+	err := transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, queryLiteInsertArtist,
+			a.ID, a.Name, a.Country, a.Formed, a.CreatedAt); err != nil {
+			return err
+		}
+		return insertNames(ctx, tx, queryLiteInsertArtistName, a)
+	})
+	if uniqueViolation(err) {
+		return models.Artist{}, ErrUniqueViolation
+	} else if err != nil {
+		return models.Artist{}, err
+	}
+	return a, nil
+}
+
+// GetArtist returns artist with aliases.
+func (s *lite) GetArtist(ctx context.Context, id string) (models.Artist, error) {
+	aa, err := queryArtists(ctx, s.conn, queryLiteSelectArtist, id)
+	if err != nil {
+		return models.Artist{}, err
+	}
+	if len(aa) == 0 {
+		return models.Artist{}, sql.ErrNoRows
+	}
+	return aa[0], nil
+}
+
+// GetArtists returns artists page ordered by name.
+func (s *lite) GetArtists(ctx context.Context, page, size int) (models.ResponseGetArtists, error) {
+	res := models.ResponseGetArtists{Page: page, Size: size}
+	if err := s.conn.QueryRowContext(ctx, queryCountArtists).Scan(&res.Total); err != nil {
+		return models.ResponseGetArtists{}, err
+	}
+	res.Pages = pages(res.Total, size)
+	var err error
+	if res.Artists, err = queryArtists(ctx, s.conn, queryLiteSelectArtists, size, (page-1)*size); err != nil {
+		return models.ResponseGetArtists{}, err
+	}
+	return res, nil
+}
+
+// ResolveArtist returns artist by canonical name or alias ignoring case
+// of ascii letters.
+func (s *lite) ResolveArtist(ctx context.Context, name string) (models.Artist, error) {
+	var id string
+	if err := s.conn.QueryRowContext(ctx, queryLiteResolveArtist, name).Scan(&id); err != nil {
+		return models.Artist{}, err
+	}
+	return s.GetArtist(ctx, id)
+}
+
+// UpdateArtist replaces artist data and aliases, renamed artist songs
+// get new group saving previous state as revision.
+func (s *lite) UpdateArtist(ctx context.Context, a models.Artist) error {
+	a.Aliases = aliases(a)
+	at := now()
+	err := transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := affected(tx.ExecContext(ctx, queryLiteUpdateArtist,
+			a.ID, a.Name, a.Country, a.Formed, at)); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, queryLiteDeleteNames, a.ID); err != nil {
+			return err
+		}
+		if err := insertNames(ctx, tx, queryLiteInsertArtistName, a); err != nil {
+			return err
+		}
+		if err := reviseRenamed(ctx, tx, liteRevision, a.ID, a.Name); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, queryLiteRenameSongs, a.ID, a.Name, at)
+		return err
+	})
+	if uniqueViolation(err) {
+		return ErrUniqueViolation
+	}
+	return err
+}
+
+// DeleteArtist removes artist not referenced by songs and albums,
+// deleted songs included.
+func (s *lite) DeleteArtist(ctx context.Context, id string) error {
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		var n int
+		if err := tx.QueryRowContext(ctx, queryLiteSelectArtistUsed, id).Scan(&n); err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrArtistInUse
+		}
+		return affected(tx.ExecContext(ctx, queryLiteDeleteArtist, id))
+	})
+}
+
+const (
+	queryLiteInsertAlbum = `
+insert into albums (id, title, artist_id, release_date, cover_link, created_at, updated_at)
+values (?1, ?2, ?3, ?4, ?5, ?6, ?6)
+`
+	queryLiteSelectAlbum = `
+select ` + albumColumns + ` from albums al join artists a on a.id=al.artist_id where al.id=?
+`
+	queryLiteSelectAlbums = `
+select ` + albumColumns + ` from albums al join artists a on a.id=al.artist_id
+where (?1='' or al.artist_id=?1) order by al.title, al.id limit ?2 offset ?3
+`
+	queryLiteCountAlbums  = `select count(*) from albums where (?1='' or artist_id=?1)`
+	queryLiteSelectTracks = `
+select ` + songColumns + ` from songs where album_id=? and deleted=false order by track
+`
+	queryLiteAttachTrack = `
+update songs set album_id=?2, track=?3, version=version+1, updated_at=?4
+where id=?1 and deleted=false and exists (select 1 from albums where id=?2)
+`
+)
+
+// AddAlbum creates album of artist, album title taken by other album of
+// artist returns ErrUniqueViolation.
+func (s *lite) AddAlbum(ctx context.Context, d models.Album) (models.Album, error) {
+	d.ID = uuid.New().String()
+	d.CreatedAt = now()
+	d.UpdatedAt = d.CreatedAt
+	_, err := s.conn.ExecContext(ctx, queryLiteInsertAlbum,
+		d.ID, d.Title, d.ArtistID, nullDate(d.ReleaseDate), d.CoverLink, d.CreatedAt)
+	if uniqueViolation(err) {
+		return models.Album{}, ErrUniqueViolation
+	} else if err != nil {
+		return models.Album{}, err
+	}
+	return s.GetAlbum(ctx, d.ID)
+}
+
+// GetAlbum returns album.
+func (s *lite) GetAlbum(ctx context.Context, id string) (models.Album, error) {
+	return scanAlbum(s.conn.QueryRowContext(ctx, queryLiteSelectAlbum, id))
+}
+
+// GetAlbums returns albums page ordered by title, empty artist id lists
+// albums of all artists.
+func (s *lite) GetAlbums(ctx context.Context, artistID string,
+	page, size int) (models.ResponseGetAlbums, error) {
+	res := models.ResponseGetAlbums{Page: page, Size: size}
+	if err := s.conn.QueryRowContext(ctx, queryLiteCountAlbums, artistID).Scan(&res.Total); err != nil {
+		return models.ResponseGetAlbums{}, err
+	}
+	res.Pages = pages(res.Total, size)
+	var err error
+	if res.Albums, err = queryAlbums(ctx, s.conn, queryLiteSelectAlbums,
+		artistID, size, (page-1)*size); err != nil {
+		return models.ResponseGetAlbums{}, err
+	}
+	return res, nil
+}
+
+// GetTracks returns not deleted album songs ordered by track number.
+func (s *lite) GetTracks(ctx context.Context, id string) ([]models.Song, error) {
+	return querySongs(ctx, s.conn, scanLiteSong, queryLiteSelectTracks, id)
+}
+
+// AttachTrack places song on album position moving it from previous
+// album saving song state as revision, position taken by other song
+// returns ErrUniqueViolation.
+func (s *lite) AttachTrack(ctx context.Context, id, songID string, track int) error {
+	err := transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, liteRevision, songID, models.ActionUpdate, 0, false); err != nil {
+			return err
+		}
+		return affected(tx.ExecContext(ctx, queryLiteAttachTrack, songID, id, track, now()))
+	})
+	if uniqueViolation(err) {
+		return ErrUniqueViolation
+	}
+	return err
+}
+
+const (
+	queryLiteTouchSong     = `update songs set version=version+1, updated_at=?2 where id=?1 and deleted=false`
+	queryLiteInsertTag     = `insert into tags (id, name, kind, created_at) values (?, ?, ?, ?) on conflict do nothing`
+	queryLiteInsertSongTag = `
+insert into song_tags (song_id, tag_id) select ?1, id from tags where name=?2 on conflict do nothing
+`
+	queryLiteDeleteSongTag = `
+delete from song_tags where song_id=?1 and tag_id in (select id from tags where name=?2)
+`
+	queryLiteSelectTags = `
+select t.name, t.kind, count(s.id) from tags t
+left join song_tags st on st.tag_id=t.id
+left join songs s on s.id=st.song_id and s.deleted=false
+where (?1='' or t.kind=?1)
+group by t.id, t.name, t.kind order by t.name
+`
+)
+
+// AddTags tags not deleted song creating missing tags of kind, existing
+// tags keep their kind, song state is saved as revision.
+func (s *lite) AddTags(ctx context.Context, id, kind string, names []string) error {
+	at := now()
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, liteRevision, id, models.ActionUpdate, 0, false); err != nil {
+			return err
+		}
+		if err := affected(tx.ExecContext(ctx, queryLiteTouchSong, id, at)); err != nil {
+			return err
+		}
+		for _, name := range tagNames(names) {
+			if _, err := tx.ExecContext(ctx, queryLiteInsertTag,
+				uuid.New().String(), name, kind, at); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, queryLiteInsertSongTag, id, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveTags untags not deleted song saving its state as revision, tags
+// are kept.
+func (s *lite) RemoveTags(ctx context.Context, id string, names []string) error {
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, liteRevision, id, models.ActionUpdate, 0, false); err != nil {
+			return err
+		}
+		if err := affected(tx.ExecContext(ctx, queryLiteTouchSong, id, now())); err != nil {
+			return err
+		}
+		for _, name := range tagNames(names) {
+			if _, err := tx.ExecContext(ctx, queryLiteDeleteSongTag, id, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetTags returns tags of kind ordered by name with numbers of not
+// deleted songs tagged, empty kind lists tags of all kinds.
+func (s *lite) GetTags(ctx context.Context, kind string) ([]models.Tag, error) {
+	return queryTags(ctx, s.conn, queryLiteSelectTags, kind)
+}
+
+// litePlaylist holds sqlite playlist songs order queries.
+var litePlaylist = playlistQueries{
+	touch:  `update playlists set updated_at=?2 where id=?1`,
+	song:   `select count(*) from songs where id=? and deleted=false`,
+	listed: `select count(*) from playlist_songs where playlist_id=? and song_id=?`,
+	slot: `
+select p.position from playlist_songs p join songs s on s.id=p.song_id
+where p.playlist_id=?1 and s.deleted=false order by p.position limit 1 offset ?2
+`,
+	last:   `select coalesce(max(position), 0) from playlist_songs where playlist_id=?`,
+	shift:  `update playlist_songs set position=position+?4 where playlist_id=?1 and position between ?2 and ?3`,
+	insert: `insert into playlist_songs (playlist_id, song_id, position) values (?, ?, ?)`,
+	current: `
+select p.position from playlist_songs p join songs s on s.id=p.song_id
+where p.playlist_id=?1 and p.song_id=?2 and s.deleted=false
+`,
+	move:   `update playlist_songs set position=?3 where playlist_id=?1 and song_id=?2`,
+	remove: `delete from playlist_songs where playlist_id=?1 and song_id=?2 returning position`,
+}
+
+const (
+	queryLiteInsertPlaylist  = `insert into playlists (id, name, created_at, updated_at) values (?1, ?2, ?3, ?3)`
+	queryLiteSelectPlaylist  = `select id, name, created_at, updated_at from playlists where id=?`
+	queryLiteSelectPlaylists = `
+select id, name, created_at, updated_at from playlists order by name, id limit ? offset ?
+`
+	queryLiteRenamePlaylist = `update playlists set name=?2, updated_at=?3 where id=?1`
+	queryLiteDeletePlaylist = `delete from playlists where id=?`
+	// playlist_songs has no columns named as songs ones
+	queryLiteCountPlaylistSongs = `
+select count(*) from playlist_songs p join songs on songs.id=p.song_id where p.playlist_id=? and deleted=false
+`
+	queryLiteSelectPlaylistSongs = `
+select ` + songColumns + ` from playlist_songs p join songs on songs.id=p.song_id
+where p.playlist_id=? and deleted=false order by p.position limit ? offset ?
+`
+)
+
+// AddPlaylist creates empty playlist.
+func (s *lite) AddPlaylist(ctx context.Context, d models.Playlist) (models.Playlist, error) {
+	d.ID = uuid.New().String()
+	d.CreatedAt = now()
+	d.UpdatedAt = d.CreatedAt
+	if _, err := s.conn.ExecContext(ctx, queryLiteInsertPlaylist, d.ID, d.Name, d.CreatedAt); err != nil {
+		return models.Playlist{}, err
+	}
+	return d, nil
+}
+
+// GetPlaylist returns playlist.
+func (s *lite) GetPlaylist(ctx context.Context, id string) (models.Playlist, error) {
+	return scanPlaylist(s.conn.QueryRowContext(ctx, queryLiteSelectPlaylist, id))
+}
+
+// GetPlaylists returns playlists page ordered by name.
+func (s *lite) GetPlaylists(ctx context.Context, page, size int) (models.ResponseGetPlaylists, error) {
+	res := models.ResponseGetPlaylists{Page: page, Size: size}
+	if err := s.conn.QueryRowContext(ctx, queryCountPlaylists).Scan(&res.Total); err != nil {
+		return models.ResponseGetPlaylists{}, err
+	}
+	res.Pages = pages(res.Total, size)
+	var err error
+	if res.Playlists, err = queryPlaylists(ctx, s.conn, queryLiteSelectPlaylists,
+		size, (page-1)*size); err != nil {
+		return models.ResponseGetPlaylists{}, err
+	}
+	return res, nil
+}
+
+// RenamePlaylist changes playlist name.
+func (s *lite) RenamePlaylist(ctx context.Context, id, name string) error {
+	return affected(s.conn.ExecContext(ctx, queryLiteRenamePlaylist, id, name, now()))
+}
+
+// DeletePlaylist removes playlist, songs are kept.
+func (s *lite) DeletePlaylist(ctx context.Context, id string) error {
+	return affected(s.conn.ExecContext(ctx, queryLiteDeletePlaylist, id))
+}
+
+// GetPlaylistSongs returns page of not deleted playlist songs in playlist
+// order.
+func (s *lite) GetPlaylistSongs(ctx context.Context, id string,
+	page, size int) (models.ResponseGetPlaylist, error) {
+	res := models.ResponseGetPlaylist{Page: page, Size: size}
+	if err := s.conn.QueryRowContext(ctx, queryLiteCountPlaylistSongs, id).Scan(&res.Total); err != nil {
+		return models.ResponseGetPlaylist{}, err
+	}
+	res.Pages = pages(res.Total, size)
+	var err error
+	if res.Songs, err = querySongs(ctx, s.conn, scanLiteSong, queryLiteSelectPlaylistSongs,
+		id, size, (page-1)*size); err != nil {
+		return models.ResponseGetPlaylist{}, err
+	}
+	return res, nil
+}
+
+// AddPlaylistSong puts not deleted song on playlist position, song
+// already in playlist returns ErrUniqueViolation.
+func (s *lite) AddPlaylistSong(ctx context.Context, id, songID string, position int) error {
+	return addPlaylistSong(ctx, s.conn, litePlaylist, id, songID, position)
+}
+
+// MovePlaylistSong moves not deleted playlist song to position.
+func (s *lite) MovePlaylistSong(ctx context.Context, id, songID string, position int) error {
+	return movePlaylistSong(ctx, s.conn, litePlaylist, id, songID, position)
+}
+
+// RemovePlaylistSong removes song from playlist.
+func (s *lite) RemovePlaylistSong(ctx context.Context, id, songID string) error {
+	return removePlaylistSong(ctx, s.conn, litePlaylist, id, songID)
+}
+
+const (
+	queryLiteInsertAPIKey  = `insert into api_keys (id, name, role, hash, created_at) values (?, ?, ?, ?, ?)`
+	queryLiteSelectAPIKey  = `select id, name, role, hash, created_at from api_keys where hash=?`
+	queryLiteSelectAPIKeys = `select id, name, role, hash, created_at from api_keys order by created_at, id`
+	queryLiteDeleteAPIKey  = `delete from api_keys where id=?`
+)
+
+// AddAPIKey keeps API key hash.
+func (s *lite) AddAPIKey(ctx context.Context, d models.APIKey) (models.APIKey, error) {
+	d.ID = uuid.New().String()
+	d.CreatedAt = now()
+	if _, err := s.conn.ExecContext(ctx, queryLiteInsertAPIKey, d.ID, d.Name, d.Role, d.Hash, d.CreatedAt); err != nil {
+		return models.APIKey{}, err
+	}
+	return d, nil
+}
+
+// GetAPIKey returns API key by its hash.
+func (s *lite) GetAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	return scanAPIKey(s.conn.QueryRowContext(ctx, queryLiteSelectAPIKey, hash))
+}
+
+// GetAPIKeys returns API keys in creation order.
+func (s *lite) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return queryAPIKeys(ctx, s.conn, queryLiteSelectAPIKeys)
+}
+
+// DeleteAPIKey revokes API key.
+func (s *lite) DeleteAPIKey(ctx context.Context, id string) error {
+	return affected(s.conn.ExecContext(ctx, queryLiteDeleteAPIKey, id))
+}
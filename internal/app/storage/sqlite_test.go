@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/migrations"
+)
+
+func Test_liteDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want string
+	}{
+		{name: "positive test #1", uri: "sqlite://songs.db", want: "songs.db?_time_format=sqlite&_pragma=foreign_keys(1)"},
+		{name: "positive test #2", uri: "sqlite://file:songs.db?mode=ro", want: "file:songs.db?mode=ro&_time_format=sqlite&_pragma=foreign_keys(1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := liteDSN(tt.uri); got != tt.want {
+				t.Errorf("liteDSN() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_uniqueViolation(t *testing.T) {
+	s, err := Open(context.Background(), &config.Config{
+		DBDriver: config.DriverSQLite,
+		DBURI:    "sqlite://" + filepath.Join(t.TempDir(), "songs.db")})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer s.Close()
+	conn := s.(*lite).conn
+	song := models.Song{Group: "Muse", Song: "Supermassive Black Hole"}
+	if _, err := s.Add(context.Background(), song); err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	_, dup := conn.Exec(queryLiteInsertSong, "id", song.Group, song.Song, nil, "", "", time.Now(), nil)
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "positive test #1", err: dup, want: true},
+		{name: "negative test #1", err: errors.New("test"), want: false},
+		{name: "negative test #2", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniqueViolation(tt.err); got != tt.want {
+				t.Errorf("uniqueViolation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_openLite(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		wantErr bool
+	}{
+		{name: "positive test #1", uri: "sqlite://" + filepath.Join(t.TempDir(), "songs.db")},
+		{name: "negative test #1", uri: "sqlite://" + filepath.Join(t.TempDir(), "missing", "songs.db"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := openLite(&config.Config{DBDriver: config.DriverSQLite, DBURI: tt.uri})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("openLite() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if s != nil {
+				s.Close()
+			}
+		})
+	}
+}
+
+func Test_liteArtistsBackfill(t *testing.T) {
+	conn, err := sql.Open(config.DriverSQLite, liteDSN("sqlite://"+filepath.Join(t.TempDir(), "songs.db")))
+	require.NoError(t, err)
+	defer conn.Close()
+	driver, err := sqlite.WithInstance(conn, &sqlite.Config{})
+	require.NoError(t, err)
+	src, err := iofs.New(migrations.FS, "sqlite")
+	require.NoError(t, err)
+	m, err := migrate.NewWithInstance("iofs", src, config.DriverSQLite, driver)
+	require.NoError(t, err)
+	require.NoError(t, m.Migrate(20241228120000))
+	for i, g := range []string{"The Beatles", "the beatles", "Beatles"} {
+		_, err := conn.Exec(`insert into songs (id, "group", song, created_at, updated_at) values (?1, ?2, ?3, ?4, ?4)`,
+			string(rune('a'+i)), g, "Help!", time.Now())
+		require.NoError(t, err)
+	}
+	require.NoError(t, m.Up())
+
+	s := newLite(&config.Config{}, conn)
+	list, err := s.GetArtists(context.Background(), 1, 10)
+	require.NoError(t, err)
+	require.Len(t, list.Artists, 2)
+	assert.Equal(t, "Beatles", list.Artists[0].Name)
+	assert.Equal(t, "The Beatles", list.Artists[1].Name)
+	a, err := s.ResolveArtist(context.Background(), "THE BEATLES")
+	require.NoError(t, err)
+	for id, want := range map[string]string{"a": a.ID, "b": a.ID, "c": list.Artists[0].ID} {
+		var got string
+		require.NoError(t, conn.QueryRow(`select artist_id from songs where id=?`, id).Scan(&got))
+		assert.Equal(t, want, got, id)
+	}
+}
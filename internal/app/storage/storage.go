@@ -2,39 +2,89 @@
 package storage
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"go.uber.org/zap"
 
 	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/cursor"
 	"github.com/xEgorka/project4/internal/app/logger"
 	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/internal/app/trgm"
+	"github.com/xEgorka/project4/migrations"
 )
 
 // Storage describes methods required to implement Storage.
 type Storage interface {
 	Add(ctx context.Context, d models.Song) (models.Song, error)
-	Update(ctx context.Context, id string, data models.RequestUpdateSong) error
-	Delete(ctx context.Context, id string) error
+	Get(ctx context.Context, id string) (models.Song, error)
+	Update(ctx context.Context, id string, version int, data models.RequestUpdateSong) error
+	Patch(ctx context.Context, id string, version int, data models.RequestPatchSong) error
+	Delete(ctx context.Context, id string, version int) error
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, id string, version int) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	GetRevisions(ctx context.Context, id string) ([]models.Revision, error)
+	GetRevision(ctx context.Context, id string, rev int) (models.Revision, error)
 	GetText(ctx context.Context, id string, page, size int) (models.ResponseGetSongText, error)
-	GetSongs(ctx context.Context, d models.Song, page, size int) (models.ResponseGetSongs, error)
+	GetSongs(ctx context.Context, r models.RequestGetSongs) (models.ResponseGetSongs, error)
+	Search(ctx context.Context, r models.RequestSearch) (models.ResponseSearch, error)
+	Suggest(ctx context.Context, q string, limit int) ([]models.Suggestion, error)
+	AddArtist(ctx context.Context, a models.Artist) (models.Artist, error)
+	GetArtist(ctx context.Context, id string) (models.Artist, error)
+	GetArtists(ctx context.Context, page, size int) (models.ResponseGetArtists, error)
+	ResolveArtist(ctx context.Context, name string) (models.Artist, error)
+	UpdateArtist(ctx context.Context, a models.Artist) error
+	DeleteArtist(ctx context.Context, id string) error
+	AddAlbum(ctx context.Context, a models.Album) (models.Album, error)
+	GetAlbum(ctx context.Context, id string) (models.Album, error)
+	GetAlbums(ctx context.Context, artistID string, page, size int) (models.ResponseGetAlbums, error)
+	GetTracks(ctx context.Context, id string) ([]models.Song, error)
+	AttachTrack(ctx context.Context, id, songID string, track int) error
+	AddTags(ctx context.Context, id, kind string, names []string) error
+	RemoveTags(ctx context.Context, id string, names []string) error
+	GetTags(ctx context.Context, kind string) ([]models.Tag, error)
+	AddPlaylist(ctx context.Context, p models.Playlist) (models.Playlist, error)
+	GetPlaylist(ctx context.Context, id string) (models.Playlist, error)
+	GetPlaylists(ctx context.Context, page, size int) (models.ResponseGetPlaylists, error)
+	RenamePlaylist(ctx context.Context, id, name string) error
+	DeletePlaylist(ctx context.Context, id string) error
+	GetPlaylistSongs(ctx context.Context, id string, page, size int) (models.ResponseGetPlaylist, error)
+	AddPlaylistSong(ctx context.Context, id, songID string, position int) error
+	MovePlaylistSong(ctx context.Context, id, songID string, position int) error
+	RemovePlaylistSong(ctx context.Context, id, songID string) error
+	AddAPIKey(ctx context.Context, k models.APIKey) (models.APIKey, error)
+	GetAPIKey(ctx context.Context, hash string) (models.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) error
 	Ping() error
 	Close() error
 }
 
 // Open initializes Storage.
 func Open(ctx context.Context, cfg *config.Config) (Storage, error) {
+	switch cfg.DBDriver {
+	case config.DriverMemory:
+		logger.Log.Info("opening in-memory storage...")
+		return newMemory(cfg), nil
+	case config.DriverSQLite:
+		return openLite(cfg)
+	}
 	logger.Log.Info("opening database...", zap.String("conninfo", cfg.DBURI))
 	conn, err := sql.Open(cfg.DBDriver, cfg.DBURI)
 	if err != nil {
@@ -48,7 +98,7 @@ func Open(ctx context.Context, cfg *config.Config) (Storage, error) {
 
 func open(cfg *config.Config, conn *sql.DB) (Storage, error) {
 	db := new(cfg, conn)
-	if err := bootstrap(conn); err != nil {
+	if err := bootstrap(conn, cfg.DBDriver); err != nil {
 		logger.Log.Error("failed bootstrap", zap.Error(err))
 		return nil, err
 	}
@@ -62,19 +112,28 @@ type db struct {
 
 func new(config *config.Config, conn *sql.DB) *db { return &db{cfg: config, conn: conn} }
 
-func bootstrap(conn *sql.DB) error {
+func bootstrap(conn *sql.DB, dbDriver string) error {
 	logger.Log.Info("running migrations...")
-	driver, err := postgres.WithInstance(conn, &postgres.Config{})
+	var driver database.Driver
+	var err error
+	dir := "."
+	if dbDriver == config.DriverSQLite {
+		driver, err = sqlite.WithInstance(conn, &sqlite.Config{})
+		dir = "sqlite"
+	} else {
+		driver, err = postgres.WithInstance(conn, &postgres.Config{})
+	}
 	if err != nil {
 		logger.Log.Error("failed init driver", zap.Error(err))
 		return err
 	}
 
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
+	src, err := iofs.New(migrations.FS, dir)
+	if err != nil {
+		logger.Log.Error("failed init source", zap.Error(err))
+		return err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, dbDriver, driver)
 	if err != nil {
 		logger.Log.Error("failed init migrate", zap.Error(err))
 		return err
@@ -93,6 +152,10 @@ func bootstrap(conn *sql.DB) error {
 	return nil
 }
 
+// now returns current UTC time truncated to microseconds postgres keeps,
+// so times returned by any storage equal ones read back later.
+func now() time.Time { return time.Now().UTC().Truncate(time.Microsecond) }
+
 // Ping checks database connection.
 func (s *db) Ping() error { return s.conn.Ping() }
 
@@ -101,7 +164,8 @@ func (s *db) Close() error { return s.conn.Close() }
 
 const (
 	queryInsertSong = `
-insert into songs (id, "group", song, release_date, text, link) values ($1, $2, $3, $4, $5, $6)
+insert into songs (id, "group", song, release_date, text, link, created_at, updated_at, artist_id)
+values ($1, $2, $3, $4, $5, $6, $7, $7, $8)
 `
 	querySelectSong = `select id from songs where "group"=$1 and song=$2 and deleted=False`
 )
@@ -109,11 +173,23 @@ insert into songs (id, "group", song, release_date, text, link) values ($1, $2,
 // ErrUniqueViolation indicates song unique constraint violation.
 var ErrUniqueViolation = errors.New(`ERROR: duplicate key value violates unique constraint "songs_idx" (SQLSTATE 23505)`)
 
-// Add creates song in database.
+// Add creates song in database, song without artist gets artist of group
+// created if missing.
 func (s *db) Add(ctx context.Context, song models.Song) (models.Song, error) {
 	id := uuid.New().String()
-	_, err := s.conn.ExecContext(ctx, queryInsertSong, id,
-		song.Group, song.Song, song.ReleaseDate, song.Text, song.Link)
+	at := now()
+	err := transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if len(song.ArtistID) == 0 && len(song.Group) > 0 {
+			a, err := songArtist(ctx, tx, pgSongArtist, song.Group)
+			if err != nil {
+				return err
+			}
+			song.Group, song.ArtistID = a.Name, a.ID
+		}
+		_, err := tx.ExecContext(ctx, queryInsertSong, id,
+			song.Group, song.Song, song.ReleaseDate, song.Text, song.Link, at, nullString(song.ArtistID))
+		return err
+	})
 	if err != nil && err.Error() == ErrUniqueViolation.Error() {
 		row := s.conn.QueryRowContext(ctx, querySelectSong, song.Group, song.Song)
 		var id string
@@ -124,18 +200,46 @@ func (s *db) Add(ctx context.Context, song models.Song) (models.Song, error) {
 	} else if err != nil {
 		return models.Song{}, err
 	}
-	song.ID = id
+	song.ID, song.Version, song.CreatedAt, song.UpdatedAt = id, 1, at, at
 	return song, nil
 }
 
-const queryUpdateSong = `update songs set release_date=$2, text=$3, link=$4 where id=$1 and deleted=False`
+// songColumns lists songs table columns read by scanSong.
+const songColumns = `id, "group", song, release_date, text, link, deleted, deleted_at, version, created_at, updated_at,
+    coalesce(artist_id, ''), coalesce(album_id, ''), coalesce(track, 0), coalesce((select string_agg(t.name, ',' order by t.name)
+    from song_tags st join tags t on t.id=st.tag_id where st.song_id=songs.id), '')`
+
+// scanSong reads song from query result row.
+func scanSong(row interface{ Scan(dest ...any) error }) (models.Song, error) {
+	var d models.Song
+	var releaseDate, tags string
+	var deletedAt sql.NullTime
+	if err := row.Scan(&d.ID, &d.Group, &d.Song, &releaseDate, &d.Text, &d.Link,
+		&d.Deleted, &deletedAt, &d.Version, &d.CreatedAt, &d.UpdatedAt, &d.ArtistID,
+		&d.AlbumID, &d.Track, &tags); err != nil {
+		return models.Song{}, err
+	}
+	var err error
+	if d.ReleaseDate, err = time.Parse(time.RFC3339, releaseDate); err != nil {
+		return models.Song{}, err
+	}
+	d.DeletedAt, d.Tags = nullTime(deletedAt), splitTags(tags)
+	d.CreatedAt, d.UpdatedAt = d.CreatedAt.UTC(), d.UpdatedAt.UTC()
+	return d, nil
+}
+
+const querySelectSongByID = `select ` + songColumns + ` from songs where id=$1 and deleted=False`
+
+// Get returns not deleted song.
+func (s *db) Get(ctx context.Context, id string) (models.Song, error) {
+	return scanSong(s.conn.QueryRowContext(ctx, querySelectSongByID, id))
+}
 
 // ErrNotAffected indicates no row affected as a result of the query.
 var ErrNotAffected = errors.New(`not affected`)
 
-// Update updates song in library.
-func (s *db) Update(ctx context.Context, id string, d models.RequestUpdateSong) error {
-	res, err := s.conn.ExecContext(ctx, queryUpdateSong, id, d.ReleaseDate, d.Text, d.Link)
+// affected converts query result without affected rows to ErrNotAffected.
+func affected(res sql.Result, err error) error {
 	if err != nil {
 		return err
 	}
@@ -149,76 +253,392 @@ func (s *db) Update(ctx context.Context, id string, d models.RequestUpdateSong)
 	return nil
 }
 
-const queryDeleteSong = `update songs set deleted=True where id=$1 and deleted=False`
+// ErrNotSupported indicates storage driver lacks requested feature.
+var ErrNotSupported = errors.New(`not supported`)
+
+// ErrVersionMismatch indicates song version differs from expected one.
+var ErrVersionMismatch = errors.New(`version mismatch`)
+
+// checkVersion reads song version from row, zero version matches any.
+func checkVersion(row *sql.Row, version int) error {
+	var v int
+	if err := row.Scan(&v); err == sql.ErrNoRows {
+		return ErrNotAffected
+	} else if err != nil {
+		return err
+	}
+	if version != 0 && v != version {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+const querySelectSongVersion = `select version from songs where id=$1 and deleted=False`
+
+// change saves current song state as revision and applies change in
+// single transaction, zero version skips version check.
+func (s *db) change(ctx context.Context, id, action string, version int,
+	apply func(tx *sql.Tx) (sql.Result, error)) error {
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, pgRevision, id, action, version, false); err != nil {
+			return err
+		}
+		return affected(apply(tx))
+	})
+}
+
+const queryUpdateSong = `
+update songs set release_date=$2, text=$3, link=$4, version=version+1, updated_at=now()
+where id=$1 and deleted=False
+`
+
+// Update updates song in library.
+func (s *db) Update(ctx context.Context, id string, version int, d models.RequestUpdateSong) error {
+	return s.change(ctx, id, models.ActionUpdate, version, func(tx *sql.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, queryUpdateSong, id, d.ReleaseDate, d.Text, d.Link)
+	})
+}
+
+// patchColumns returns quoted columns and values set by patch.
+func patchColumns(d models.RequestPatchSong) ([]string, []any) {
+	var cols []string
+	var args []any
+	if d.Group != nil {
+		cols, args = append(cols, `"group"`), append(args, *d.Group)
+	}
+	if d.Song != nil {
+		cols, args = append(cols, `song`), append(args, *d.Song)
+	}
+	if d.ReleaseDate != nil {
+		cols, args = append(cols, `release_date`), append(args, date(*d.ReleaseDate))
+	}
+	if d.Text != nil {
+		cols, args = append(cols, `text`), append(args, *d.Text)
+	}
+	if d.Link != nil {
+		cols, args = append(cols, `link`), append(args, *d.Link)
+	}
+	if d.ArtistID != nil {
+		cols, args = append(cols, `artist_id`), append(args, nullString(*d.ArtistID))
+	}
+	return cols, args
+}
+
+// Patch updates song columns set by patch, group without artist gets
+// artist created if missing, renaming onto existing song returns
+// ErrUniqueViolation.
+func (s *db) Patch(ctx context.Context, id string, version int, d models.RequestPatchSong) error {
+	if d == (models.RequestPatchSong{}) {
+		return checkVersion(s.conn.QueryRowContext(ctx, querySelectSongVersion, id), version)
+	}
+	err := s.change(ctx, id, models.ActionUpdate, version, func(tx *sql.Tx) (sql.Result, error) {
+		if d.Group != nil && d.ArtistID == nil {
+			a, err := songArtist(ctx, tx, pgSongArtist, *d.Group)
+			if err != nil {
+				return nil, err
+			}
+			d.Group, d.ArtistID = &a.Name, &a.ID
+		}
+		cols, args := patchColumns(d)
+		q := `update songs set `
+		for i, col := range cols {
+			if i > 0 {
+				q += `, `
+			}
+			q += fmt.Sprintf(`%s=$%d`, col, i+2)
+		}
+		q += `, version=version+1, updated_at=now() where id=$1 and deleted=False`
+		logger.Log.Debug("executing", zap.String("query", q))
+		return tx.ExecContext(ctx, q, append([]any{id}, args...)...)
+	})
+	if err != nil && err.Error() == ErrUniqueViolation.Error() {
+		return ErrUniqueViolation
+	}
+	return err
+}
+
+const queryDeleteSong = `
+update songs set deleted=True, deleted_at=now(), version=version+1, updated_at=now()
+where id=$1 and deleted=False
+`
 
 // Delete soft deletes song from library.
-func (s *db) Delete(ctx context.Context, id string) error {
-	res, err := s.conn.ExecContext(ctx, queryDeleteSong, id)
+func (s *db) Delete(ctx context.Context, id string, version int) error {
+	return s.change(ctx, id, models.ActionDelete, version, func(tx *sql.Tx) (sql.Result, error) {
+		return tx.ExecContext(ctx, queryDeleteSong, id)
+	})
+}
+
+const queryRestoreSong = `
+update songs set deleted=False, deleted_at=null, version=version+1, updated_at=now()
+where id=$1 and deleted=True
+`
+
+// Restore brings soft deleted song back to library saving deleted state
+// as revision.
+func (s *db) Restore(ctx context.Context, id string) error {
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, pgRevision, id, models.ActionRestore, 0, true); err != nil {
+			return err
+		}
+		return affected(tx.ExecContext(ctx, queryRestoreSong, id))
+	})
+}
+
+const (
+	queryPurgeSong        = `delete from songs where id=$1 and ($2=0 or version=$2)`
+	querySelectAnyVersion = `select version from songs where id=$1`
+)
+
+// Purge permanently removes song from library.
+func (s *db) Purge(ctx context.Context, id string, version int) error {
+	err := affected(s.conn.ExecContext(ctx, queryPurgeSong, id, version))
+	if err == ErrNotAffected && version != 0 {
+		if err := checkVersion(s.conn.QueryRowContext(ctx, querySelectAnyVersion, id), version); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+const queryPurgeDeleted = `delete from songs where deleted=True and deleted_at<$1`
+
+// PurgeDeleted permanently removes songs deleted before specified time
+// and returns their count.
+func (s *db) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.conn.ExecContext(ctx, queryPurgeDeleted, before)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	row, e := res.RowsAffected()
-	if e != nil {
-		return e
+	return res.RowsAffected()
+}
+
+const (
+	querySelectSongID    = `select id from songs where id=$1`
+	querySelectRevisions = `
+select rev, "group", song, release_date, text, link, action, created_at
+from song_revisions where song_id=$1 order by rev
+`
+	querySelectRevision = `
+select rev, "group", song, release_date, text, link, action, created_at
+from song_revisions where song_id=$1 and rev=$2
+`
+)
+
+// scanRevision reads revision from query result row.
+func scanRevision(row interface{ Scan(dest ...any) error }) (models.Revision, error) {
+	var r models.Revision
+	if err := row.Scan(&r.Rev, &r.Group, &r.Song,
+		&r.ReleaseDate, &r.Text, &r.Link, &r.Action, &r.CreatedAt); err != nil {
+		return models.Revision{}, err
 	}
-	if row < 1 {
-		return ErrNotAffected
+	r.ReleaseDate, r.CreatedAt = r.ReleaseDate.UTC(), r.CreatedAt.UTC()
+	return r, nil
+}
+
+// GetRevisions returns song revisions including deleted songs ones.
+func (s *db) GetRevisions(ctx context.Context, id string) ([]models.Revision, error) {
+	if err := s.conn.QueryRowContext(ctx, querySelectSongID, id).Scan(&id); err != nil {
+		return nil, err // ErrNoRows if song not found
 	}
-	return nil
+	rows, err := s.conn.QueryContext(ctx, querySelectRevisions, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	revs := make([]models.Revision, 0)
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return revs, nil
 }
 
-const querySelectSongText = `select "group", song, text from songs where id=$1 and deleted=False`
+// GetRevision returns song revision.
+func (s *db) GetRevision(ctx context.Context, id string, rev int) (models.Revision, error) {
+	return scanRevision(s.conn.QueryRowContext(ctx, querySelectRevision, id, rev))
+}
+
+const querySelectSongText = `select "group", song, text, version from songs where id=$1 and deleted=False`
 
 // GetText returns song text.
 func (s *db) GetText(ctx context.Context, id string,
 	page, size int) (models.ResponseGetSongText, error) {
 	row := s.conn.QueryRowContext(ctx, querySelectSongText, id)
 	var group, song, text string
-	if err := row.Scan(&group, &song, &text); err != nil {
+	var version int
+	if err := row.Scan(&group, &song, &text, &version); err != nil {
 		return models.ResponseGetSongText{}, err
 	}
+	return verses(models.ResponseGetSongText{
+		ID:      id,
+		Group:   group,
+		Song:    song,
+		Page:    page,
+		Size:    size,
+		Version: version,
+	}, text), nil
+}
 
+// verses splits song text by verses and fills requested page.
+func verses(d models.ResponseGetSongText, text string) models.ResponseGetSongText {
 	v := strings.Split(text, "\n\n")
 	total := len(v)
-	d := models.ResponseGetSongText{
-		ID:    id,
-		Group: group,
-		Song:  song,
-		Total: total,
-		Page:  page,
-		Size:  size,
-	}
-	beg := (page - 1) * size
+	d.Total = total
+	beg := (d.Page - 1) * d.Size
 	if beg > total {
-		return d, nil
+		return d
 	}
-	end := beg + size
+	end := beg + d.Size
 	if end > total {
 		end = total
 	}
 	d.Verses = v[beg:end]
-	return d, nil
+	return d
+}
+
+// nullTime converts nullable time to pointer, nil for NULL.
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time.UTC()
+	return &v
+}
+
+// nullString converts empty string to NULL.
+func nullString(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ``} }
+
+// whereDeleted returns songs query condition for deleted listing mode.
+func whereDeleted(mode string) string {
+	switch mode {
+	case models.DeletedInclude:
+		return ` where true`
+	case models.DeletedOnly:
+		return ` where deleted=True`
+	}
+	return ` where deleted=False`
+}
+
+// likeEscaper escapes like pattern wildcards and escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// match returns condition of column matching value in mode with
+// placeholder p and its argument.
+func match(column, mode, v, p string) (string, any) {
+	switch mode {
+	case models.MatchPrefix:
+		return column + ` like ` + p + ` escape '\'`, likeEscaper.Replace(v) + "%"
+	case models.MatchContains:
+		return column + ` like ` + p + ` escape '\'`, "%" + likeEscaper.Replace(v) + "%"
+	case models.MatchCI:
+		return column + ` ilike ` + p + ` escape '\'`, "%" + likeEscaper.Replace(v) + "%"
+	}
+	return column + `=` + p, v
+}
+
+// pages returns number of pages of size needed to fit total rows.
+func pages(total, size int) int { return (total + size - 1) / size }
+
+// next trims extra song fetched in cursor mode and sets cursor of next
+// page if there is one.
+func next(r models.RequestGetSongs, res models.ResponseGetSongs) models.ResponseGetSongs {
+	more := r.Page*r.Size < res.Total
+	if r.Cursor != nil {
+		more = len(res.Songs) > r.Size
+		res.Songs = res.Songs[:min(len(res.Songs), r.Size)]
+	}
+	if more && len(res.Songs) > 0 {
+		res.NextCursor = cursor.Encode(cursor.After(r.Sort, res.Songs[len(res.Songs)-1]))
+	}
+	return res
 }
 
-// GetSongs returns filtered and paginated songs.
-func (s *db) GetSongs(ctx context.Context, d models.Song,
-	page, size int) (models.ResponseGetSongs, error) {
-	q := `select id, "group", song, release_date, text, link from songs where deleted=False`
-	args := make([]interface{}, 0)
-	num := 1
+// column returns quoted sort column.
+func column(name string) string {
+	if name == models.SortGroup {
+		return `"group"`
+	}
+	return name
+}
+
+// orderBy returns songs order clause for sort ended by id.
+func orderBy(sort []models.SortKey) string {
+	q := ` order by `
+	for _, k := range cursor.Order(sort) {
+		q += column(k.Column)
+		if k.Desc {
+			q += ` desc`
+		}
+		q += `, `
+	}
+	return q + `id`
+}
+
+// keyset returns condition selecting songs following cursor in sort
+// order, p holds placeholders of cursor keys followed by id one.
+func keyset(sort []models.SortKey, p []string) string {
+	sort = cursor.Order(sort)
+	or := make([]string, 0, len(sort)+1)
+	for i := range len(sort) + 1 {
+		and := make([]string, 0, i+1)
+		for j := range i {
+			and = append(and, column(sort[j].Column)+`=`+p[j])
+		}
+		switch {
+		case i == len(sort):
+			and = append(and, `id>`+p[i])
+		case sort[i].Desc:
+			and = append(and, column(sort[i].Column)+`<`+p[i])
+		default:
+			and = append(and, column(sort[i].Column)+`>`+p[i])
+		}
+		or = append(or, `(`+strings.Join(and, ` and `)+`)`)
+	}
+	return ` and (` + strings.Join(or, ` or `) + `)`
+}
+
+// filterSongs returns songs query condition and arguments for filter,
+// num is number of first placeholder.
+func filterSongs(r models.RequestGetSongs) (q string, args []any, num int) {
+	d := r.Filter
+	q = whereDeleted(r.Deleted)
+	num = 1
 	if d.ID != `` {
 		q += fmt.Sprintf(` and id=$%d`, num)
 		args = append(args, d.ID)
 		num += 1
 	}
+	if d.ArtistID != `` {
+		q += fmt.Sprintf(` and artist_id=$%d`, num)
+		args = append(args, d.ArtistID)
+		num += 1
+	}
+	if d.AlbumID != `` {
+		q += fmt.Sprintf(` and album_id=$%d`, num)
+		args = append(args, d.AlbumID)
+		num += 1
+	}
 	if d.Group != `` {
-		q += fmt.Sprintf(` and "group"=$%d`, num)
-		args = append(args, d.Group)
+		cond, arg := match(`"group"`, cmp.Or(r.Match.Group, models.MatchExact), d.Group, fmt.Sprintf(`$%d`, num))
+		q += ` and ` + cond
+		args = append(args, arg)
 		num += 1
 	}
 	if d.Song != `` {
-		q += fmt.Sprintf(` and song=$%d`, num)
-		args = append(args, d.Song)
+		cond, arg := match(`song`, cmp.Or(r.Match.Song, models.MatchExact), d.Song, fmt.Sprintf(`$%d`, num))
+		q += ` and ` + cond
+		args = append(args, arg)
 		num += 1
 	}
 	if !d.ReleaseDate.IsZero() {
@@ -226,9 +646,20 @@ func (s *db) GetSongs(ctx context.Context, d models.Song,
 		args = append(args, d.ReleaseDate)
 		num += 1
 	}
+	if !r.ReleasedFrom.IsZero() {
+		q += fmt.Sprintf(` and release_date>=$%d`, num)
+		args = append(args, r.ReleasedFrom)
+		num += 1
+	}
+	if !r.ReleasedTo.IsZero() {
+		q += fmt.Sprintf(` and release_date<$%d`, num)
+		args = append(args, r.ReleasedTo)
+		num += 1
+	}
 	if d.Text != `` {
-		q += fmt.Sprintf(` and text like $%d`, num)
-		args = append(args, "%"+d.Text+"%")
+		cond, arg := match(`text`, cmp.Or(r.Match.Text, models.MatchContains), d.Text, fmt.Sprintf(`$%d`, num))
+		q += ` and ` + cond
+		args = append(args, arg)
 		num += 1
 	}
 	if d.Link != `` {
@@ -236,10 +667,49 @@ func (s *db) GetSongs(ctx context.Context, d models.Song,
 		args = append(args, d.Link)
 		num += 1
 	}
-	q += fmt.Sprintf(` offset $%d limit $%d`, num, num+1)
-	args = append(args, (page-1)*size)
-	args = append(args, size)
+	if tt := tagNames(d.Tags); len(tt) > 0 {
+		p := make([]string, len(tt))
+		for i, v := range tt {
+			p[i] = fmt.Sprintf(`$%d`, num)
+			args = append(args, v)
+			num += 1
+		}
+		q += ` and ` + tagged(r.Match.Tags, p)
+	}
+	return q, args, num
+}
+
+// GetSongs returns filtered and paginated songs with total count of
+// filtered songs.
+func (s *db) GetSongs(ctx context.Context,
+	r models.RequestGetSongs) (models.ResponseGetSongs, error) {
+	page, size := r.Page, r.Size
+	where, args, num := filterSongs(r)
+
+	q := `select count(*) from songs` + where
+	logger.Log.Debug("executing", zap.String("query", q))
+	var total int
+	if err := s.conn.QueryRowContext(ctx, q, args...).Scan(&total); err != nil {
+		return models.ResponseGetSongs{}, err
+	}
+	res := models.ResponseGetSongs{Page: page, Size: size, Total: total, Pages: pages(total, size)}
+	if r.CountOnly {
+		return res, nil
+	}
 
+	q = `select ` + songColumns + ` from songs` + where
+	if c := r.Cursor; c != nil {
+		p := make([]string, len(c.Keys)+1)
+		for i := range p {
+			p[i] = fmt.Sprintf(`$%d`, num+i)
+		}
+		q += keyset(r.Sort, p) + orderBy(r.Sort) + fmt.Sprintf(` limit $%d`, num+len(p))
+		args = append(append(args, cursor.Values(*c, r.Sort)...), c.ID,
+			size+1) // extra song tells next page exists
+	} else {
+		q += orderBy(r.Sort) + fmt.Sprintf(` offset $%d limit $%d`, num, num+1)
+		args = append(args, (page-1)*size, size)
+	}
 	logger.Log.Debug("executing", zap.String("query", q))
 	rows, err := s.conn.QueryContext(ctx, q, args...)
 	if err != nil {
@@ -251,28 +721,156 @@ func (s *db) GetSongs(ctx context.Context, d models.Song,
 		}
 	}()
 
-	var dd []models.Song
 	for rows.Next() {
-		var id, group, song, text, releaseDateStr, link string
-		if err = rows.Scan(&id, &group, &song,
-			&releaseDateStr, &text, &link); err != nil {
+		d, err := scanSong(rows)
+		if err != nil {
 			return models.ResponseGetSongs{}, err
 		}
-		releaseDate, e := time.Parse(time.RFC3339, releaseDateStr)
-		if e != nil {
-			return models.ResponseGetSongs{}, e
-		}
-		dd = append(dd, models.Song{
-			ID:          id,
-			Group:       group,
-			Song:        song,
-			ReleaseDate: releaseDate,
-			Text:        text,
-			Link:        link})
+		res.Songs = append(res.Songs, d)
 	}
 	if err = rows.Err(); err != nil {
 		return models.ResponseGetSongs{}, err
 	}
 
-	return models.ResponseGetSongs{Songs: dd, Page: page, Size: size}, nil
+	return next(r, res), nil
+}
+
+// searchColumns maps search language to songs tsvector column.
+var searchColumns = map[string]string{
+	models.LangEnglish: "tsv_english",
+	models.LangRussian: "tsv_russian",
+}
+
+// headline marks matched words, language and column are taken from
+// searchColumns so formatting is safe.
+const (
+	headlineOptions = `StartSel=<mark>, StopSel=</mark>`
+
+	querySearchCount = `
+select count(*) from songs s cross join websearch_to_tsquery('%[1]s', $1) q
+where s.deleted=False and s.%[2]s @@ q
+`
+	// first verse matching query is highlighted, group and song only
+	// matches fall back to headline of whole lyrics
+	querySearchSongs = `
+select s.id, s."group", s.song, ts_rank(s.%[2]s, q) as rank,
+    coalesce(h.headline, ts_headline('%[1]s', coalesce(s.text, ''), q, '%[3]s'))
+from songs s cross join websearch_to_tsquery('%[1]s', $1) q
+left join lateral (
+    select ts_headline('%[1]s', v, q, 'HighlightAll=true, %[3]s') as headline
+    from regexp_split_to_table(s.text, E'\n\n') with ordinality as t(v, n)
+    where to_tsvector('%[1]s', v) @@ q
+    order by n limit 1
+) h on true
+where s.deleted=False and s.%[2]s @@ q
+order by rank desc, s.id
+offset $2 limit $3
+`
+)
+
+// Search finds songs by lyrics, group and song full text query ranked by
+// relevance with highlighted matched verse.
+func (s *db) Search(ctx context.Context, r models.RequestSearch) (models.ResponseSearch, error) {
+	column, ok := searchColumns[r.Lang]
+	if !ok {
+		return models.ResponseSearch{}, ErrNotSupported
+	}
+	res := models.ResponseSearch{Query: r.Query, Lang: r.Lang, Page: r.Page, Size: r.Size}
+	q := fmt.Sprintf(querySearchCount, r.Lang, column)
+	if err := s.conn.QueryRowContext(ctx, q, r.Query).Scan(&res.Total); err != nil {
+		return models.ResponseSearch{}, err
+	}
+
+	q = fmt.Sprintf(querySearchSongs, r.Lang, column, headlineOptions)
+	rows, err := s.conn.QueryContext(ctx, q, r.Query, (r.Page-1)*r.Size, r.Size)
+	if err != nil {
+		return models.ResponseSearch{}, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	for rows.Next() {
+		var d models.SearchHit
+		if err := rows.Scan(&d.ID, &d.Group, &d.Song, &d.Rank, &d.Headline); err != nil {
+			return models.ResponseSearch{}, err
+		}
+		res.Hits = append(res.Hits, d)
+	}
+	if err = rows.Err(); err != nil {
+		return models.ResponseSearch{}, err
+	}
+	return res, nil
+}
+
+// fold returns expression folding homoglyphs and case like trgm.Fold,
+// indexes are built on same expressions.
+func fold(expr string) string {
+	from, to := trgm.Homoglyphs()
+	return `lower(translate(` + expr + `, '` + from + `', '` + to + `'))`
+}
+
+// score returns greatest similarity of folded query to song group, song
+// and both of them.
+func score(q string, d models.Suggestion) float64 {
+	return max(trgm.Similarity(q, trgm.Fold(d.Group)),
+		trgm.Similarity(q, trgm.Fold(d.Song)),
+		trgm.Similarity(q, trgm.Fold(d.Group+" "+d.Song)))
+}
+
+// suggest scores songs by similarity to query and returns limit most
+// similar ones above threshold like querySuggest does.
+func suggest(q string, dd []models.Suggestion, limit int) []models.Suggestion {
+	q = trgm.Fold(q)
+	var res []models.Suggestion
+	for _, d := range dd {
+		if d.Score = score(q, d); d.Score >= trgm.Threshold {
+			res = append(res, d)
+		}
+	}
+	slices.SortFunc(res, func(a, b models.Suggestion) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.ID, b.ID))
+	})
+	return res[:min(len(res), limit)]
+}
+
+var (
+	foldGroup = fold(`"group"`)
+	foldSong  = fold(`song`)
+	foldName  = fold(`"group" || ' ' || song`)
+
+	// querySuggest finds songs by trigram similarity, % operator uses
+	// pg_trgm.similarity_threshold equal to trgm.Threshold by default
+	querySuggest = fmt.Sprintf(`
+select id, "group", song,
+    greatest(similarity(%[1]s, $1), similarity(%[2]s, $1), similarity(%[3]s, $1)) as score
+from songs
+where deleted=False and (%[1]s %% $1 or %[2]s %% $1 or %[3]s %% $1)
+order by score desc, id
+limit $2
+`, foldGroup, foldSong, foldName)
+)
+
+// Suggest returns songs with group or song names similar to query, most
+// similar first.
+func (s *db) Suggest(ctx context.Context, q string, limit int) ([]models.Suggestion, error) {
+	rows, err := s.conn.QueryContext(ctx, querySuggest, trgm.Fold(q), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	var dd []models.Suggestion
+	for rows.Next() {
+		var d models.Suggestion
+		if err := rows.Scan(&d.ID, &d.Group, &d.Song, &d.Score); err != nil {
+			return nil, err
+		}
+		dd = append(dd, d)
+	}
+	return dd, rows.Err()
 }
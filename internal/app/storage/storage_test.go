@@ -3,9 +3,12 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"fmt"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,10 +16,13 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/cursor"
 	"github.com/xEgorka/project4/internal/app/models"
+	"github.com/xEgorka/project4/migrations"
 )
 
 func TestOpen(t *testing.T) {
+	memoryCfg := &config.Config{DBDriver: config.DriverMemory}
 	type args struct {
 		ctx context.Context
 		cfg *config.Config
@@ -29,6 +35,7 @@ func TestOpen(t *testing.T) {
 	}{
 		{name: "negative test #1", args: args{ctx: context.Background(), cfg: &config.Config{DBDriver: "bad"}}, wantErr: true},
 		{name: "negative test #2", args: args{ctx: context.Background(), cfg: &config.Config{DBDriver: "pgx"}}, wantErr: true},
+		{name: "positive test #1", args: args{ctx: context.Background(), cfg: memoryCfg}, want: newMemory(memoryCfg)},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -158,22 +165,132 @@ func Test_db_Add(t *testing.T) {
 			fields:  fields{conn: conn, cfg: &config.Config{}},
 			wantErr: false,
 		},
+		{
+			name:    "positive test #2",
+			args:    args{ctx: context.Background(), song: models.Song{Group: "muse", Song: "Hysteria"}},
+			fields:  fields{conn: conn, cfg: &config.Config{}},
+			wantErr: false,
+		},
+		{
+			name:    "negative test #2",
+			args:    args{ctx: context.Background(), song: models.Song{Group: "Muse", Song: "Hysteria"}},
+			fields:  fields{conn: conn, cfg: &config.Config{}},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := db{conn: tt.fields.conn, cfg: tt.fields.cfg}
+			mock.ExpectBegin()
 			if tt.name == "negative test #1" {
 				mock.ExpectExec(regexp.QuoteMeta(queryInsertSong)).WillReturnError(errors.New("test"))
+				mock.ExpectRollback()
 			}
 			if tt.name == "positive test #1" {
 				res := sqlmock.NewResult(1, 1)
 				mock.ExpectExec(regexp.QuoteMeta(queryInsertSong)).WillReturnResult(res)
+				mock.ExpectCommit()
+			}
+			if tt.name == "positive test #2" {
+				expectSongArtist(mock, "muse", "Muse")
+				mock.ExpectExec(regexp.QuoteMeta(queryInsertSong)).
+					WithArgs(sqlmock.AnyArg(), "Muse", "Hysteria", sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), "9c3f6f1e-8d2b-4b8a-a6f4-1b6c2d7e8f90").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			}
+			if tt.name == "negative test #2" { // new artist is rolled back with song
+				mock.ExpectExec(regexp.QuoteMeta(pgSongArtist.lock)).WithArgs("Muse").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(pgSongArtist.resolve)).WithArgs("Muse").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec(regexp.QuoteMeta(queryInsertArtist)).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta(queryInsertArtistName)).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta(queryInsertSong)).WillReturnError(errors.New(ErrUniqueViolation.Error()))
+				mock.ExpectRollback()
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSong)).WithArgs("Muse", "Hysteria").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("0824f9fb-7397-4f19-95d5-f9ce8bec75de"))
 			}
 			_, err := s.Add(tt.args.ctx, tt.args.song)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("db.Add() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+// expectSongArtist expects group resolved to existing artist of name.
+func expectSongArtist(mock sqlmock.Sqlmock, group, name string) {
+	mock.ExpectExec(regexp.QuoteMeta(pgSongArtist.lock)).WithArgs(group).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(pgSongArtist.resolve)).WithArgs(group).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("9c3f6f1e-8d2b-4b8a-a6f4-1b6c2d7e8f90", name))
+}
+
+func Test_db_Get(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	created := time.Date(2024, 12, 24, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		want    models.Song
+		wantErr bool
+	}{
+		{
+			name: "positive test #1",
+			want: models.Song{
+				ID:          id,
+				Group:       "Muse",
+				Song:        "Hysteria",
+				ReleaseDate: time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC),
+				Text:        "It's bugging me",
+				Link:        "https://youtu.be/3dm_5qWWDV8",
+				Version:     2,
+				CreatedAt:   created,
+				UpdatedAt:   created.Add(time.Hour),
+				ArtistID:    "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11",
+				Tags:        []string{"rock", "english"},
+			},
+		},
+		{name: "negative test #1", wantErr: true},
+		{name: "negative test #2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := db{conn: conn, cfg: &config.Config{}}
+			rows := sqlmock.NewRows([]string{"id", "group", "song", "release_date", "text", "link",
+				"deleted", "deleted_at", "version", "created_at", "updated_at", "artist_id", "album_id", "track", "tags"})
+			if tt.name == "positive test #1" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongByID)).WithArgs(id).
+					WillReturnRows(rows.AddRow(id, "Muse", "Hysteria", "2003-12-01T00:00:00Z",
+						"It's bugging me", "https://youtu.be/3dm_5qWWDV8", false, nil, 2,
+						created, created.Add(time.Hour), "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11", "", 0, "rock,english"))
+			}
+			if tt.name == "negative test #1" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongByID)).WithArgs(id).
+					WillReturnError(sql.ErrNoRows)
+			}
+			if tt.name == "negative test #2" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongByID)).WithArgs(id).
+					WillReturnRows(rows.AddRow(id, "Muse", "Hysteria", "01.12.2003",
+						"", "", false, nil, 1, created, created, "", "", 0, ""))
+			}
+			got, err := s.Get(context.Background(), id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("db.Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("db.Get() = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
@@ -229,16 +346,24 @@ func Test_db_Update(t *testing.T) {
 			}
 			if tt.name == "positive test #1" {
 				res := sqlmock.NewResult(1, 1)
+				mock.ExpectBegin()
+				expectRevise(mock, tt.args.id, models.ActionUpdate, 1, false)
 				mock.ExpectExec(regexp.QuoteMeta(queryUpdateSong)).WillReturnResult(res)
+				mock.ExpectCommit()
 			}
 			if tt.name == "negative test #1" {
+				mock.ExpectBegin()
+				expectRevise(mock, tt.args.id, models.ActionUpdate, 1, false)
 				mock.ExpectExec(regexp.QuoteMeta(queryUpdateSong)).WillReturnError(errors.New("test"))
+				mock.ExpectRollback()
 			}
 			if tt.name == "negative test #2" {
-				res := sqlmock.NewResult(1, 0)
-				mock.ExpectExec(regexp.QuoteMeta(queryUpdateSong)).WillReturnResult(res)
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(pgRevision.lock)).WithArgs(tt.args.id).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			}
-			err := s.Update(tt.args.ctx, tt.args.id, tt.args.req)
+			err := s.Update(tt.args.ctx, tt.args.id, 0, tt.args.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("db.Update() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -247,6 +372,86 @@ func Test_db_Update(t *testing.T) {
 	}
 }
 
+// expectRevise expects song lock returning version and deleted flag
+// followed by revision of action.
+func expectRevise(mock sqlmock.Sqlmock, id, action string, version int, deleted bool) {
+	mock.ExpectQuery(regexp.QuoteMeta(pgRevision.lock)).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "deleted"}).AddRow(version, deleted))
+	mock.ExpectExec(regexp.QuoteMeta(pgRevision.insert)).WithArgs(id, action, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func Test_db_Patch(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	group, link := "Muse", "https://youtu.be/3dm_5qWWDV8"
+	patch := models.RequestPatchSong{Group: &group, Link: &link}
+	const queryPatch = `update songs set "group"=$2, link=$3, artist_id=$4, version=version+1, updated_at=now() where id=$1 and deleted=False`
+	artistID := "9c3f6f1e-8d2b-4b8a-a6f4-1b6c2d7e8f90"
+	tests := []struct {
+		name    string
+		version int
+		patch   models.RequestPatchSong
+		wantErr error
+	}{
+		{name: "positive test #1", patch: patch},
+		{name: "positive test #2", version: 3},
+		{name: "negative test #1", patch: patch, wantErr: ErrUniqueViolation},
+		{name: "negative test #2", wantErr: ErrNotAffected},
+		{name: "negative test #3", version: 2, patch: patch, wantErr: ErrVersionMismatch},
+		{name: "negative test #4", version: 2, wantErr: ErrVersionMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := db{conn: conn, cfg: &config.Config{}}
+			if tt.name == "positive test #1" {
+				mock.ExpectBegin()
+				expectRevise(mock, id, models.ActionUpdate, 1, false)
+				expectSongArtist(mock, group, group)
+				mock.ExpectExec(regexp.QuoteMeta(queryPatch)).WithArgs(id, group, link, artistID).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			}
+			if tt.name == "positive test #2" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongVersion)).WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+			}
+			if tt.name == "negative test #1" {
+				mock.ExpectBegin()
+				expectRevise(mock, id, models.ActionUpdate, 1, false)
+				expectSongArtist(mock, group, group)
+				mock.ExpectExec(regexp.QuoteMeta(queryPatch)).WithArgs(id, group, link, artistID).
+					WillReturnError(errors.New(ErrUniqueViolation.Error()))
+				mock.ExpectRollback()
+			}
+			if tt.name == "negative test #2" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongVersion)).WithArgs(id).
+					WillReturnError(sql.ErrNoRows)
+			}
+			if tt.name == "negative test #3" {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(pgRevision.lock)).WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "deleted"}).AddRow(3, false))
+				mock.ExpectRollback()
+			}
+			if tt.name == "negative test #4" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongVersion)).WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+			}
+			if err := s.Patch(context.Background(), id, tt.version, tt.patch); err != tt.wantErr {
+				t.Errorf("db.Patch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
 func Test_db_Delete(t *testing.T) {
 	conn, mock, err := sqlmock.New()
 	if err != nil {
@@ -297,16 +502,24 @@ func Test_db_Delete(t *testing.T) {
 			}
 			if tt.name == "positive test #1" {
 				res := sqlmock.NewResult(1, 1)
+				mock.ExpectBegin()
+				expectRevise(mock, tt.args.id, models.ActionDelete, 1, false)
 				mock.ExpectExec(regexp.QuoteMeta(queryDeleteSong)).WillReturnResult(res)
+				mock.ExpectCommit()
 			}
 			if tt.name == "negative test #1" {
+				mock.ExpectBegin()
+				expectRevise(mock, tt.args.id, models.ActionDelete, 1, false)
 				mock.ExpectExec(regexp.QuoteMeta(queryDeleteSong)).WillReturnError(errors.New("test"))
+				mock.ExpectRollback()
 			}
 			if tt.name == "negative test #2" {
-				res := sqlmock.NewResult(1, 0)
-				mock.ExpectExec(regexp.QuoteMeta(queryDeleteSong)).WillReturnResult(res)
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(pgRevision.lock)).WithArgs(tt.args.id).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			}
-			err := s.Delete(tt.args.ctx, tt.args.id)
+			err := s.Delete(tt.args.ctx, tt.args.id, 0)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("db.Delete() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -315,6 +528,240 @@ func Test_db_Delete(t *testing.T) {
 	}
 }
 
+func Test_db_Restore(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "positive test #1", wantErr: false},
+		{name: "negative test #1", wantErr: true},
+		{name: "negative test #2", wantErr: true},
+	}
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := db{conn: conn, cfg: &config.Config{}}
+			mock.ExpectBegin()
+			if tt.name == "positive test #1" {
+				expectRevise(mock, id, models.ActionRestore, 1, true)
+				mock.ExpectExec(regexp.QuoteMeta(queryRestoreSong)).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			}
+			if tt.name == "negative test #1" {
+				expectRevise(mock, id, models.ActionRestore, 1, true)
+				mock.ExpectExec(regexp.QuoteMeta(queryRestoreSong)).WillReturnError(errors.New("test"))
+				mock.ExpectRollback()
+			}
+			if tt.name == "negative test #2" { // song is not deleted
+				mock.ExpectQuery(regexp.QuoteMeta(pgRevision.lock)).WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "deleted"}).AddRow(1, false))
+				mock.ExpectRollback()
+			}
+			if err := s.Restore(context.Background(), id); (err != nil) != tt.wantErr {
+				t.Errorf("db.Restore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func Test_db_Purge(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "positive test #1", wantErr: false},
+		{name: "negative test #1", wantErr: true},
+		{name: "negative test #2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := db{conn: conn, cfg: &config.Config{}}
+			if tt.name == "positive test #1" {
+				mock.ExpectExec(regexp.QuoteMeta(queryPurgeSong)).WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+			if tt.name == "negative test #1" {
+				mock.ExpectExec(regexp.QuoteMeta(queryPurgeSong)).WillReturnError(errors.New("test"))
+			}
+			if tt.name == "negative test #2" {
+				mock.ExpectExec(regexp.QuoteMeta(queryPurgeSong)).WillReturnResult(sqlmock.NewResult(1, 0))
+			}
+			if err := s.Purge(context.Background(), "0824f9fb-7397-4f19-95d5-f9ce8bec75de", 0); (err != nil) != tt.wantErr {
+				t.Errorf("db.Purge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_db_PurgeDeleted(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	before := time.Date(2024, 12, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		want    int64
+		wantErr bool
+	}{
+		{name: "positive test #1", want: 3, wantErr: false},
+		{name: "negative test #1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := db{conn: conn, cfg: &config.Config{}}
+			if tt.name == "positive test #1" {
+				mock.ExpectExec(regexp.QuoteMeta(queryPurgeDeleted)).WithArgs(before).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+			}
+			if tt.name == "negative test #1" {
+				mock.ExpectExec(regexp.QuoteMeta(queryPurgeDeleted)).WithArgs(before).
+					WillReturnError(errors.New("test"))
+			}
+			got, err := s.PurgeDeleted(context.Background(), before)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("db.PurgeDeleted() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("db.PurgeDeleted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_db_GetRevisions(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	created := time.Date(2024, 12, 20, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		want    []models.Revision
+		wantErr bool
+	}{
+		{
+			name: "positive test #1",
+			want: []models.Revision{{
+				Rev:         1,
+				Group:       "Muse",
+				Song:        "Hysteria",
+				ReleaseDate: time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC),
+				Text:        "It's bugging me",
+				Link:        "https://youtu.be/3dm_5qWWDV8",
+				Action:      models.ActionUpdate,
+				CreatedAt:   created}},
+			wantErr: false,
+		},
+		{name: "negative test #1", wantErr: true},
+		{name: "negative test #2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := db{conn: conn, cfg: &config.Config{}}
+			if tt.name == "positive test #1" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongID)).WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectRevisions)).WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"rev", "group", "song",
+						"release_date", "text", "link", "action", "created_at"}).
+						AddRow(1, "Muse", "Hysteria", time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC),
+							"It's bugging me", "https://youtu.be/3dm_5qWWDV8",
+							models.ActionUpdate, created))
+			}
+			if tt.name == "negative test #1" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongID)).WithArgs(id).
+					WillReturnError(sql.ErrNoRows)
+			}
+			if tt.name == "negative test #2" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongID)).WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectRevisions)).WithArgs(id).
+					WillReturnError(errors.New("test"))
+			}
+			got, err := s.GetRevisions(context.Background(), id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("db.GetRevisions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("db.GetRevisions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_db_GetRevision(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	created := time.Date(2024, 12, 20, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		want    models.Revision
+		wantErr bool
+	}{
+		{
+			name: "positive test #1",
+			want: models.Revision{
+				Rev:         2,
+				Group:       "Muse",
+				Song:        "Hysteria",
+				ReleaseDate: time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC),
+				Text:        "It's bugging me",
+				Link:        "https://youtu.be/3dm_5qWWDV8",
+				Action:      models.ActionDelete,
+				CreatedAt:   created},
+			wantErr: false,
+		},
+		{name: "negative test #1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := db{conn: conn, cfg: &config.Config{}}
+			if tt.name == "positive test #1" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectRevision)).WithArgs(id, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"rev", "group", "song",
+						"release_date", "text", "link", "action", "created_at"}).
+						AddRow(2, "Muse", "Hysteria", time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC),
+							"It's bugging me", "https://youtu.be/3dm_5qWWDV8",
+							models.ActionDelete, created))
+			}
+			if tt.name == "negative test #1" {
+				mock.ExpectQuery(regexp.QuoteMeta(querySelectRevision)).WithArgs(id, 2).
+					WillReturnError(sql.ErrNoRows)
+			}
+			got, err := s.GetRevision(context.Background(), id, 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("db.GetRevision() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("db.GetRevision() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_db_GetText(t *testing.T) {
 	conn, mock, err := sqlmock.New()
 	if err != nil {
@@ -368,8 +815,8 @@ func Test_db_GetText(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			s := db{conn: tt.fields.conn, cfg: tt.fields.cfg}
 			mockRows := sqlmock.NewRows(
-				[]string{"group", "song", "text"}).
-				AddRow("Muse", "Supermassive Black Hole", "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight")
+				[]string{"group", "song", "text", "version"}).
+				AddRow("Muse", "Supermassive Black Hole", "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight", 1)
 			if tt.name == "negative test #1" {
 				mock.ExpectQuery(regexp.QuoteMeta(querySelectSongText)).
 					WithArgs(tt.args.id).WillReturnError(errors.New("test"))
@@ -520,66 +967,401 @@ func Test_db_GetSongs(t *testing.T) {
 			fields:  fields{conn: conn, cfg: &config.Config{}},
 			wantErr: true,
 		},
+		{
+			name: "negative test #2",
+			args: args{ctx: context.Background(),
+				song: models.Song{
+					ID:          "0824f9fb-7397-4f19-95d5-f9ce8bec75de",
+					Group:       "Muse",
+					Song:        "Super Muse Black Hole",
+					ReleaseDate: releaseDate,
+					Text:        "baby",
+					Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+				},
+				page: 1,
+				size: 10,
+			},
+			fields:  fields{conn: conn, cfg: &config.Config{}},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := db{conn: tt.fields.conn, cfg: tt.fields.cfg}
-			q := `select id, "group", song, release_date, text, link from songs where deleted=False`
+			q := `select ` + songColumns + ` from songs where deleted=False`
 			mockRows := sqlmock.NewRows(
-				[]string{"id", "group", "song", "release_date", "text", "link"}).
-				AddRow("0824f9fb-7397-4f19-95d5-f9ce8bec75de", "Muse", "Supermassive Black Hole", "2006-07-16T00:00:00Z", "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight", "https://www.youtube.com/watch?v=Xsp3_a-PMTw")
-			if tt.name == "positive test #1" {
-				query := q + " and id=$1"
-				mock.ExpectQuery(regexp.QuoteMeta(query)).
-					WithArgs(tt.args.song.ID, tt.args.page-1, tt.args.size).WillReturnRows(mockRows)
+				[]string{"id", "group", "song", "release_date", "text", "link", "deleted", "deleted_at", "version", "created_at", "updated_at", "artist_id", "album_id", "track", "tags"}).
+				AddRow("0824f9fb-7397-4f19-95d5-f9ce8bec75de", "Muse", "Supermassive Black Hole", "2006-07-16T00:00:00Z", "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight", "https://www.youtube.com/watch?v=Xsp3_a-PMTw", false, nil, 1, time.Time{}, time.Time{}, "", "", 0, "")
+			d := tt.args.song
+			var cond string
+			var args []driver.Value
+			switch tt.name {
+			case "positive test #1":
+				cond, args = ` and id=$1`, []driver.Value{d.ID}
+			case "positive test #2":
+				cond, args = ` and id=$1 and "group"=$2`, []driver.Value{d.ID, d.Group}
+			case "positive test #3":
+				cond = ` and id=$1 and "group"=$2 and song=$3`
+				args = []driver.Value{d.ID, d.Group, d.Song}
+			case "positive test #4":
+				cond = ` and id=$1 and "group"=$2 and song=$3 and release_date=$4`
+				args = []driver.Value{d.ID, d.Group, d.Song, d.ReleaseDate}
+			case "positive test #5":
+				cond = ` and id=$1 and "group"=$2 and song=$3 and release_date=$4 and text like $5 escape '\'`
+				args = []driver.Value{d.ID, d.Group, d.Song, d.ReleaseDate, "%" + d.Text + "%"}
+			default:
+				cond = ` and id=$1 and "group"=$2 and song=$3 and release_date=$4 and text like $5 escape '\' and link=$6`
+				args = []driver.Value{d.ID, d.Group, d.Song, d.ReleaseDate, "%" + d.Text + "%", d.Link}
 			}
-			if tt.name == "positive test #2" {
-				query := q + ` and id=$1 and "group"=$2`
-				mock.ExpectQuery(regexp.QuoteMeta(query)).
-					WithArgs(tt.args.song.ID, tt.args.song.Group, tt.args.page-1, tt.args.size).
-					WillReturnRows(mockRows)
-			}
-			if tt.name == "positive test #3" {
-				query := q + ` and id=$1 and "group"=$2 and song=$3`
-				mock.ExpectQuery(regexp.QuoteMeta(query)).
-					WithArgs(tt.args.song.ID, tt.args.song.Group, tt.args.song.Song,
-						tt.args.page-1, tt.args.size).
-					WillReturnRows(mockRows)
-			}
-			if tt.name == "positive test #4" {
-				query := q + ` and id=$1 and "group"=$2 and song=$3 and release_date=$4`
-				mock.ExpectQuery(regexp.QuoteMeta(query)).
-					WithArgs(tt.args.song.ID, tt.args.song.Group, tt.args.song.Song,
-						tt.args.song.ReleaseDate, tt.args.page-1, tt.args.size).
-					WillReturnRows(mockRows)
-			}
-			if tt.name == "positive test #5" {
-				query := q + ` and id=$1 and "group"=$2 and song=$3 and release_date=$4 and text like $5`
-				mock.ExpectQuery(regexp.QuoteMeta(query)).
-					WithArgs(tt.args.song.ID, tt.args.song.Group, tt.args.song.Song,
-						tt.args.song.ReleaseDate, "%"+tt.args.song.Text+"%", tt.args.page-1, tt.args.size).
-					WillReturnRows(mockRows)
-			}
-			if tt.name == "positive test #6" {
-				query := q + ` and id=$1 and "group"=$2 and song=$3 and release_date=$4 and text like $5 and link=$6`
-				mock.ExpectQuery(regexp.QuoteMeta(query)).
-					WithArgs(tt.args.song.ID, tt.args.song.Group, tt.args.song.Song,
-						tt.args.song.ReleaseDate, "%"+tt.args.song.Text+"%", tt.args.song.Link,
-						tt.args.page-1, tt.args.size).WillReturnRows(mockRows)
+			count := mock.ExpectQuery(regexp.QuoteMeta(`select count(*) from songs where deleted=False` + cond)).
+				WithArgs(args...)
+			if tt.name == "negative test #2" {
+				count.WillReturnError(errors.New("test"))
+			} else {
+				count.WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
 			}
+			query := mock.ExpectQuery(regexp.QuoteMeta(q + cond)).
+				WithArgs(append(args, tt.args.page-1, tt.args.size)...)
 			if tt.name == "negative test #1" {
-				query := q + ` and id=$1 and "group"=$2 and song=$3 and release_date=$4 and text like $5 and link=$6`
-				mock.ExpectQuery(regexp.QuoteMeta(query)).
-					WithArgs(tt.args.song.ID, tt.args.song.Group, tt.args.song.Song,
-						tt.args.song.ReleaseDate, "%"+tt.args.song.Text+"%", tt.args.song.Link,
-						tt.args.page-1, tt.args.size).WillReturnError(errors.New("test"))
+				query.WillReturnError(errors.New("test"))
+			} else {
+				query.WillReturnRows(mockRows)
 			}
 
-			_, err := s.GetSongs(tt.args.ctx, tt.args.song, tt.args.page, tt.args.size)
+			got, err := s.GetSongs(tt.args.ctx, models.RequestGetSongs{
+				Filter: tt.args.song, Page: tt.args.page, Size: tt.args.size})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("db.GetSongs() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if err == nil && (got.Total != 11 || got.Pages != 2 || len(got.Songs) != 1) {
+				t.Errorf("db.GetSongs() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_db_GetSongs_countOnly(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	mock.ExpectQuery(regexp.QuoteMeta(`select count(*) from songs where deleted=False and "group"=$1`)).
+		WithArgs("Muse").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(20))
+	got, err := s.GetSongs(context.Background(), models.RequestGetSongs{
+		Filter: models.Song{Group: "Muse"}, Page: 1, Size: 10, CountOnly: true})
+	if err != nil {
+		t.Fatalf("db.GetSongs() error = %v", err)
+	}
+	want := models.ResponseGetSongs{Page: 1, Size: 10, Total: 20, Pages: 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("db.GetSongs() = %v, want %v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_db_GetSongs_cursor(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	created := time.Date(2024, 12, 24, 12, 0, 0, 0, time.UTC)
+	c := cursor.After(nil, models.Song{ID: "a", CreatedAt: created})
+	columns := []string{"id", "group", "song", "release_date", "text", "link",
+		"deleted", "deleted_at", "version", "created_at", "updated_at", "artist_id", "album_id", "track", "tags"}
+	tests := []struct {
+		name string
+		rows int
+		want string
+	}{
+		{name: "positive test #1", rows: 3, want: "c"},
+		{name: "positive test #2", rows: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(`select count(*) from songs where deleted=False`)).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+			rows := sqlmock.NewRows(columns)
+			for i := 0; i < tt.rows; i++ {
+				id := string(rune('b' + i))
+				rows.AddRow(id, id, id, "2006-07-16T00:00:00Z", "", "", false, nil, 1,
+					created.Add(time.Duration(i+1)*time.Second), created, "", "", 0, "")
+			}
+			mock.ExpectQuery(regexp.QuoteMeta(`select `+songColumns+
+				` from songs where deleted=False and ((created_at>$1) or (created_at=$1 and id>$2)) order by created_at, id limit $3`)).
+				WithArgs(created, "a", 3).WillReturnRows(rows)
+			got, err := s.GetSongs(context.Background(), models.RequestGetSongs{Page: 1, Size: 2, Cursor: &c})
+			if err != nil {
+				t.Fatalf("db.GetSongs() error = %v", err)
+			}
+			if len(got.Songs) != 2 {
+				t.Errorf("db.GetSongs() songs = %v, want 2", len(got.Songs))
+			}
+			var next string
+			if len(got.NextCursor) > 0 {
+				d, err := cursor.Decode(got.NextCursor, nil)
+				if err != nil {
+					t.Fatalf("cursor.Decode() error = %v", err)
+				}
+				next = d.ID
+			}
+			if next != tt.want {
+				t.Errorf("db.GetSongs() next cursor id = %v, want %v", next, tt.want)
+			}
+		})
+	}
+}
+
+func Test_filterSongs(t *testing.T) {
+	from := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		r        models.RequestGetSongs
+		wantQ    string
+		wantArgs []any
+		wantNum  int
+	}{
+		{name: "positive test #1", wantQ: ` where deleted=False`, wantNum: 1},
+		{
+			name:     "positive test #2",
+			r:        models.RequestGetSongs{Filter: models.Song{Group: "Queen"}, ReleasedFrom: from, ReleasedTo: to},
+			wantQ:    ` where deleted=False and "group"=$1 and release_date>=$2 and release_date<$3`,
+			wantArgs: []any{"Queen", from, to},
+			wantNum:  4,
+		},
+		{
+			name:     "positive test #3",
+			r:        models.RequestGetSongs{ReleasedTo: to},
+			wantQ:    ` where deleted=False and release_date<$1`,
+			wantArgs: []any{to},
+			wantNum:  2,
+		},
+		{
+			name: "positive test #4",
+			r: models.RequestGetSongs{Filter: models.Song{Link: "l", Tags: []string{"rock", "Rock", "english"}},
+				Match: models.Match{Tags: models.MatchAll}},
+			wantQ: ` where deleted=False and link=$1 and id in (select st.song_id from song_tags st join tags t on t.id=st.tag_id
+    where lower(t.name) in (lower($2), lower($3)) group by st.song_id having count(*)=2)`,
+			wantArgs: []any{"l", "rock", "english"},
+			wantNum:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, args, num := filterSongs(tt.r)
+			if q != tt.wantQ || !reflect.DeepEqual(args, tt.wantArgs) || num != tt.wantNum {
+				t.Errorf("filterSongs() = %v, %v, %v, want %v, %v, %v",
+					q, args, num, tt.wantQ, tt.wantArgs, tt.wantNum)
+			}
+		})
+	}
+}
+
+func Test_match(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		v        string
+		wantCond string
+		wantArg  any
+	}{
+		{name: "positive test #1", mode: models.MatchExact, v: "Muse", wantCond: `"group"=$1`, wantArg: "Muse"},
+		{name: "positive test #2", mode: models.MatchPrefix, v: "Mu_", wantCond: `"group" like $1 escape '\'`, wantArg: `Mu\_%`},
+		{name: "positive test #3", mode: models.MatchContains, v: "100%", wantCond: `"group" like $1 escape '\'`, wantArg: `%100\%%`},
+		{name: "positive test #4", mode: models.MatchCI, v: `a\b`, wantCond: `"group" ilike $1 escape '\'`, wantArg: `%a\\b%`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, arg := match(`"group"`, tt.mode, tt.v, `$1`)
+			if cond != tt.wantCond || arg != tt.wantArg {
+				t.Errorf("match() = %v, %v, want %v, %v", cond, arg, tt.wantCond, tt.wantArg)
+			}
+		})
+	}
+}
+
+func Test_orderBy(t *testing.T) {
+	tests := []struct {
+		name string
+		sort []models.SortKey
+		want string
+	}{
+		{name: "positive test #1", want: ` order by created_at, id`},
+		{
+			name: "positive test #2",
+			sort: []models.SortKey{{Column: models.SortReleaseDate, Desc: true},
+				{Column: models.SortGroup}, {Column: models.SortSong}},
+			want: ` order by release_date desc, "group", song, id`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderBy(tt.sort); got != tt.want {
+				t.Errorf("orderBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_keyset(t *testing.T) {
+	tests := []struct {
+		name string
+		sort []models.SortKey
+		want string
+	}{
+		{name: "positive test #1", want: ` and ((created_at>$1) or (created_at=$1 and id>$2))`},
+		{
+			name: "positive test #2",
+			sort: []models.SortKey{{Column: models.SortReleaseDate, Desc: true}, {Column: models.SortGroup}},
+			want: ` and ((release_date<$1) or (release_date=$1 and "group">$2)` +
+				` or (release_date=$1 and "group"=$2 and id>$3))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyset(tt.sort, []string{"$1", "$2", "$3"}); got != tt.want {
+				t.Errorf("keyset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_db_Search(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	r := models.RequestSearch{Query: `"soul alight"`, Lang: models.LangRussian, Page: 2, Size: 5}
+	tests := []struct {
+		name    string
+		r       models.RequestSearch
+		err     error
+		wantErr bool
+	}{
+		{name: "positive test #1", r: r},
+		{name: "negative test #1", r: r, err: errors.New("test"), wantErr: true},
+		{name: "negative test #2", r: models.RequestSearch{Lang: "german"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.r.Lang == models.LangRussian {
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(querySearchCount, "russian", "tsv_russian"))).
+					WithArgs(tt.r.Query).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+				q := mock.ExpectQuery(regexp.QuoteMeta(
+					fmt.Sprintf(querySearchSongs, "russian", "tsv_russian", headlineOptions))).
+					WithArgs(tt.r.Query, 5, 5)
+				if tt.err != nil {
+					q.WillReturnError(tt.err)
+				} else {
+					q.WillReturnRows(sqlmock.NewRows([]string{"id", "group", "song", "rank", "headline"}).
+						AddRow("0824f9fb-7397-4f19-95d5-f9ce8bec75de", "Muse", "Supermassive Black Hole",
+							0.6, "You set my <mark>soul</mark> <mark>alight</mark>"))
+				}
+			}
+			got, err := s.Search(context.Background(), tt.r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("db.Search() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (got.Total != 6 || len(got.Hits) != 1 || got.Hits[0].Rank != 0.6) {
+				t.Errorf("db.Search() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_db_Suggest(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "positive test #1"},
+		{name: "negative test #1", err: errors.New("test"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := mock.ExpectQuery(regexp.QuoteMeta(querySuggest)).WithArgs("muse", 5)
+			if tt.err != nil {
+				q.WillReturnError(tt.err)
+			} else {
+				q.WillReturnRows(sqlmock.NewRows([]string{"id", "group", "song", "score"}).
+					AddRow("0824f9fb-7397-4f19-95d5-f9ce8bec75de", "Muse", "Supermassive Black Hole", 1.0))
+			}
+			got, err := s.Suggest(context.Background(), "Musе", 5) // cyrillic е
+			if (err != nil) != tt.wantErr {
+				t.Errorf("db.Suggest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (len(got) != 1 || got[0].Score != 1) {
+				t.Errorf("db.Suggest() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_trgmMigration(t *testing.T) {
+	b, err := migrations.FS.ReadFile("20250102120000_add_songs_trgm.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expr := range []string{foldGroup, foldSong, foldName} {
+		if !strings.Contains(string(b), expr) {
+			t.Errorf("migration has no index on %v", expr)
+		}
+	}
+}
+
+func Test_whereDeleted(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+		want string
+	}{
+		{name: "positive test #1", mode: "", want: ` where deleted=False`},
+		{name: "positive test #2", mode: models.DeletedExclude, want: ` where deleted=False`},
+		{name: "positive test #3", mode: models.DeletedInclude, want: ` where true`},
+		{name: "positive test #4", mode: models.DeletedOnly, want: ` where deleted=True`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := whereDeleted(tt.mode); got != tt.want {
+				t.Errorf("whereDeleted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_nullTime(t *testing.T) {
+	v := time.Date(2024, 12, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		t    sql.NullTime
+		want *time.Time
+	}{
+		{name: "positive test #1", t: sql.NullTime{Time: v, Valid: true}, want: &v},
+		{name: "positive test #2", t: sql.NullTime{}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nullTime(tt.t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nullTime() = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
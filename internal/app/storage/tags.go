@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/xEgorka/project4/internal/app/logger"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+// tagNames returns tag names without duplicates differing by case only.
+func tagNames(names []string) []string {
+	res := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, v := range names {
+		if k := strings.ToLower(v); !seen[k] {
+			seen[k] = true
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// splitTags splits comma separated song tags aggregated by songColumns.
+func splitTags(s string) []string {
+	if s == `` {
+		return nil
+	}
+	return strings.Split(s, `,`)
+}
+
+// tagged returns songs condition of having any or all of tags passed in
+// placeholders p, tag names are compared ignoring case.
+func tagged(mode string, p []string) string {
+	in := make([]string, len(p))
+	for i, v := range p {
+		in[i] = `lower(` + v + `)`
+	}
+	q := `id in (select st.song_id from song_tags st join tags t on t.id=st.tag_id
+    where lower(t.name) in (` + strings.Join(in, `, `) + `)`
+	if mode == models.MatchAll {
+		q += fmt.Sprintf(` group by st.song_id having count(*)=%d`, len(p))
+	}
+	return q + `)`
+}
+
+// queryTags runs tags query and reads tags with song counts.
+func queryTags(ctx context.Context, conn *sql.DB, q string, args ...any) ([]models.Tag, error) {
+	rows, err := conn.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			logger.Log.Error("failed close rows", zap.Error(err))
+		}
+	}()
+	res := make([]models.Tag, 0)
+	for rows.Next() {
+		var d models.Tag
+		if err := rows.Scan(&d.Name, &d.Kind, &d.Songs); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+const (
+	queryTouchSong     = `update songs set version=version+1, updated_at=now() where id=$1 and deleted=False`
+	queryInsertTag     = `insert into tags (id, name, kind) values ($1, $2, $3) on conflict do nothing`
+	queryInsertSongTag = `
+insert into song_tags (song_id, tag_id) select $1, id from tags where lower(name)=lower($2) on conflict do nothing
+`
+	queryDeleteSongTag = `
+delete from song_tags where song_id=$1 and tag_id in (select id from tags where lower(name)=lower($2))
+`
+	querySelectTags = `
+select t.name, t.kind, count(s.id) from tags t
+left join song_tags st on st.tag_id=t.id
+left join songs s on s.id=st.song_id and s.deleted=False
+where ($1='' or t.kind=$1)
+group by t.id, t.name, t.kind order by t.name
+`
+)
+
+// AddTags tags not deleted song creating missing tags of kind, existing
+// tags keep their kind, song state is saved as revision.
+func (s *db) AddTags(ctx context.Context, id, kind string, names []string) error {
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, pgRevision, id, models.ActionUpdate, 0, false); err != nil {
+			return err
+		}
+		if err := affected(tx.ExecContext(ctx, queryTouchSong, id)); err != nil {
+			return err
+		}
+		for _, name := range tagNames(names) {
+			if _, err := tx.ExecContext(ctx, queryInsertTag, uuid.New().String(), name, kind); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, queryInsertSongTag, id, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveTags untags not deleted song saving its state as revision, tags
+// are kept.
+func (s *db) RemoveTags(ctx context.Context, id string, names []string) error {
+	return transact(ctx, s.conn, func(tx *sql.Tx) error {
+		if err := revise(ctx, tx, pgRevision, id, models.ActionUpdate, 0, false); err != nil {
+			return err
+		}
+		if err := affected(tx.ExecContext(ctx, queryTouchSong, id)); err != nil {
+			return err
+		}
+		for _, name := range tagNames(names) {
+			if _, err := tx.ExecContext(ctx, queryDeleteSongTag, id, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetTags returns tags of kind ordered by name with numbers of not
+// deleted songs tagged, empty kind lists tags of all kinds.
+func (s *db) GetTags(ctx context.Context, kind string) ([]models.Tag, error) {
+	return queryTags(ctx, s.conn, querySelectTags, kind)
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/xEgorka/project4/internal/app/config"
+	"github.com/xEgorka/project4/internal/app/models"
+)
+
+func Test_tagNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{name: "positive test #1", names: nil, want: []string{}},
+		{name: "positive test #2", names: []string{"rock", "Rock", "english", "ROCK"}, want: []string{"rock", "english"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tagNames(tt.names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tagNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_db_AddTags(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	id := "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+	tests := []struct {
+		name     string
+		affected int64
+		err      error
+		wantErr  error
+	}{
+		{name: "positive test #1", affected: 1},
+		{name: "negative test #1", wantErr: ErrNotAffected},
+		{name: "negative test #2", affected: 1, err: errors.New("test"), wantErr: errors.New("test")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			expectRevise(mock, id, models.ActionUpdate, 1, false)
+			mock.ExpectExec(regexp.QuoteMeta(queryTouchSong)).WithArgs(id).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if tt.affected > 0 {
+				mock.ExpectExec(regexp.QuoteMeta(queryInsertTag)).
+					WithArgs(sqlmock.AnyArg(), "rock", models.TagGenre).WillReturnResult(sqlmock.NewResult(0, 1))
+				q := mock.ExpectExec(regexp.QuoteMeta(queryInsertSongTag)).WithArgs(id, "rock")
+				if tt.err != nil {
+					q.WillReturnError(tt.err)
+				} else {
+					q.WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+			}
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+			err := s.AddTags(context.Background(), id, models.TagGenre, []string{"rock", "Rock"})
+			if (err != nil) != (tt.wantErr != nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("db.AddTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_db_GetTags(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected", err)
+	}
+	defer conn.Close()
+	s := db{conn: conn, cfg: &config.Config{}}
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		want    []models.Tag
+		wantErr bool
+	}{
+		{
+			name: "positive test #1",
+			rows: sqlmock.NewRows([]string{"name", "kind", "count"}).
+				AddRow("calm", models.TagMood, 0).AddRow("rock", models.TagGenre, 2),
+			want: []models.Tag{{Name: "calm", Kind: models.TagMood}, {Name: "rock", Kind: models.TagGenre, Songs: 2}},
+		},
+		{
+			name:    "negative test #1",
+			rows:    sqlmock.NewRows([]string{"name", "kind", "count"}).AddRow("rock", models.TagGenre, "bad"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(regexp.QuoteMeta(querySelectTags)).WithArgs("").WillReturnRows(tt.rows)
+			got, err := s.GetTags(context.Background(), "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("db.GetTags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("db.GetTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
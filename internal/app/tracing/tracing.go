@@ -0,0 +1,107 @@
+// Package tracing sets up OpenTelemetry tracing of server.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace exporters.
+const (
+	// ExporterOTLP sends spans by OTLP over HTTP to endpoint set by
+	// OTEL_EXPORTER_OTLP_ENDPOINT, localhost:4318 by default.
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes spans to stdout as JSON lines.
+	ExporterStdout = "stdout"
+	// filePrefix starts path of file spans are appended to as JSON lines.
+	filePrefix = "file://"
+)
+
+const (
+	instrumentation = "github.com/xEgorka/project4"
+	serviceName     = "online-song-library"
+)
+
+// Start starts span as child of span in ctx, tracer is taken from current
+// provider as tracers taken before are bound to first provider set. Span not
+// recorded carries trace context of ctx, so ctx is kept unchanged.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	spanCtx, span := otel.Tracer(instrumentation).Start(ctx, name, opts...)
+	if !span.IsRecording() {
+		return ctx, span
+	}
+	return spanCtx, span
+}
+
+// End records err in span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Setup installs W3C trace context propagator and tracer provider exporting
+// spans to exporter, empty exporter only propagates trace context. Returned
+// shutdown flushes exported spans.
+func Setup(ctx context.Context, exporter string) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+	if len(exporter) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+	exp, err := newExporter(ctx, exporter)
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// fileExporter closes file of wrapped exporter on shutdown.
+type fileExporter struct {
+	sdktrace.SpanExporter
+	f *os.File
+}
+
+// Shutdown shuts wrapped exporter down and closes file.
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.f.Close())
+}
+
+func newExporter(ctx context.Context, exporter string) (sdktrace.SpanExporter, error) {
+	switch {
+	case exporter == ExporterOTLP:
+		return otlptracehttp.New(ctx)
+	case exporter == ExporterStdout:
+		return stdouttrace.New()
+	case strings.HasPrefix(exporter, filePrefix):
+		f, err := os.OpenFile(strings.TrimPrefix(exporter, filePrefix), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			return nil, errors.Join(err, f.Close())
+		}
+		return &fileExporter{SpanExporter: exp, f: f}, nil
+	}
+	return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+}
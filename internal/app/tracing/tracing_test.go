@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSetup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	tests := []struct {
+		name     string
+		exporter string
+		wantErr  bool
+	}{
+		{name: "positive test #1"},
+		{name: "positive test #2", exporter: "file://" + path},
+		{name: "negative test #1", exporter: "zipkin", wantErr: true},
+		{name: "negative test #2", exporter: "file://" + filepath.Join(path, "missing", "traces.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), tt.exporter)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), "file://"+path)
+	require.NoError(t, err)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("test"))
+	End(parent, nil)
+	h := make(propagation.HeaderCarrier)
+	otel.GetTextMapPropagator().Inject(ctx, h)
+	assert.Contains(t, h.Get("traceparent"), parent.SpanContext().TraceID().String())
+
+	require.NoError(t, shutdown(context.Background()))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"Name":"child"`)
+	assert.Contains(t, string(b), `"Name":"parent"`)
+	assert.Contains(t, string(b), `"Description":"test"`)
+}
+
+func TestStart(t *testing.T) {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	ctx := context.Background()
+	got, span := Start(ctx, "test")
+	defer span.End()
+	assert.False(t, span.IsRecording())
+	assert.Equal(t, ctx, got)
+}
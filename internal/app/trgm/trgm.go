@@ -0,0 +1,65 @@
+// Package trgm measures names similarity the way postgres pg_trgm does
+// after folding cyrillic letters which look like latin ones.
+package trgm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Cyrillic homoglyphs and latin letters replacing them, rune by rune.
+const (
+	cyrillic = "АаВЕеКМНОоРрСсТУуХхІіЈјЅѕ"
+	latin    = "AaBEeKMHOoPpCcTYyXxIiJjSs"
+)
+
+// Threshold is minimal similarity of similar names, same as default
+// pg_trgm.similarity_threshold.
+const Threshold = 0.3
+
+var folder = func() *strings.Replacer {
+	from, to := []rune(cyrillic), []rune(latin)
+	pairs := make([]string, 0, 2*len(from))
+	for i := range from {
+		pairs = append(pairs, string(from[i]), string(to[i]))
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// Homoglyphs returns cyrillic letters looking like latin ones and latin
+// letters replacing them in postgres translate function arguments order.
+func Homoglyphs() (from, to string) { return cyrillic, latin }
+
+// Fold replaces cyrillic homoglyphs by latin letters and lowers case.
+func Fold(s string) string { return strings.ToLower(folder.Replace(s)) }
+
+// trigrams returns set of trigrams of words padded by two spaces in
+// front and one space behind.
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			set[string(r[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// Similarity returns share of common trigrams of a and b from 0 to 1.
+func Similarity(a, b string) float64 {
+	x, y := trigrams(a), trigrams(b)
+	if len(x) == 0 || len(y) == 0 {
+		return 0
+	}
+	common := 0
+	for t := range x {
+		if _, ok := y[t]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(x)+len(y)-common)
+}
@@ -0,0 +1,55 @@
+package trgm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "positive test #1", s: "Muse", want: "muse"},
+		{name: "positive test #2", s: "Musе", want: "muse"}, // cyrillic e
+		{name: "positive test #3", s: "АВВА", want: "abba"},
+		{name: "positive test #4", s: "Кино", want: "kинo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fold(tt.s); got != tt.want {
+				t.Errorf("Fold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHomoglyphs(t *testing.T) {
+	from, to := Homoglyphs()
+	if len([]rune(from)) != len([]rune(to)) {
+		t.Errorf("Homoglyphs() = %v, %v, want same length", from, to)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		// pg_trgm documentation example and hand counted trigrams
+		{name: "positive test #1", a: "word", b: "two words", want: 0.36363637},
+		{name: "positive test #2", a: "Muse", b: "muse", want: 1},
+		{name: "positive test #3", a: "Supermasive Black Hole", b: "Supermassive Black Hole", want: 0.88},
+		{name: "positive test #4", a: "", b: "muse", want: 0},
+		{name: "positive test #5", a: "Queen", b: "Muse", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("Similarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
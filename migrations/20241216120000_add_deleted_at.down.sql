@@ -0,0 +1 @@
+alter table songs drop column deleted_at;
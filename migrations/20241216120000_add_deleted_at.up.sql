@@ -0,0 +1,3 @@
+alter table songs add column deleted_at timestamptz;
+
+update songs set deleted_at=now() where deleted;
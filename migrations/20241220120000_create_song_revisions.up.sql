@@ -0,0 +1,12 @@
+create table song_revisions (
+    song_id varchar not null references songs (id) on delete cascade,
+    rev integer not null,
+    "group" varchar not null,
+    song varchar not null,
+    release_date date,
+    text text,
+    link varchar,
+    action varchar not null,
+    created_at timestamptz not null default now(),
+    primary key (song_id, rev)
+);
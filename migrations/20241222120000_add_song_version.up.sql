@@ -0,0 +1 @@
+alter table songs add column version integer not null default 1;
@@ -0,0 +1,2 @@
+alter table songs drop column updated_at;
+alter table songs drop column created_at;
@@ -0,0 +1,2 @@
+alter table songs add column created_at timestamptz not null default now();
+alter table songs add column updated_at timestamptz not null default now();
@@ -0,0 +1 @@
+create index songs_created_idx on songs (created_at, id);
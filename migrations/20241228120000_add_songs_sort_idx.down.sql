@@ -0,0 +1,3 @@
+drop index songs_updated_idx;
+drop index songs_release_date_idx;
+drop index songs_song_idx;
@@ -0,0 +1,3 @@
+create index songs_song_idx on songs (song, id);
+create index songs_release_date_idx on songs (release_date, id);
+create index songs_updated_idx on songs (updated_at, id);
@@ -0,0 +1,4 @@
+drop index songs_tsv_russian_idx;
+drop index songs_tsv_english_idx;
+
+alter table songs drop column tsv_russian, drop column tsv_english;
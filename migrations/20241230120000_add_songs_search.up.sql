@@ -0,0 +1,10 @@
+alter table songs
+    add column tsv_english tsvector generated always as (
+        setweight(to_tsvector('english', coalesce("group", '') || ' ' || coalesce(song, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(text, '')), 'B')) stored,
+    add column tsv_russian tsvector generated always as (
+        setweight(to_tsvector('russian', coalesce("group", '') || ' ' || coalesce(song, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(text, '')), 'B')) stored;
+
+create index songs_tsv_english_idx on songs using gin (tsv_english);
+create index songs_tsv_russian_idx on songs using gin (tsv_russian);
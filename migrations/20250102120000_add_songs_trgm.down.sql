@@ -0,0 +1,3 @@
+drop index songs_name_trgm_idx;
+drop index songs_song_trgm_idx;
+drop index songs_group_trgm_idx;
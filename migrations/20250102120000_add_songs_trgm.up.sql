@@ -0,0 +1,6 @@
+create extension if not exists pg_trgm;
+
+-- expressions match storage fold, cyrillic homoglyphs are folded to latin
+create index songs_group_trgm_idx on songs using gin ((lower(translate("group", 'АаВЕеКМНОоРрСсТУуХхІіЈјЅѕ', 'AaBEeKMHOoPpCcTYyXxIiJjSs'))) gin_trgm_ops);
+create index songs_song_trgm_idx on songs using gin ((lower(translate(song, 'АаВЕеКМНОоРрСсТУуХхІіЈјЅѕ', 'AaBEeKMHOoPpCcTYyXxIiJjSs'))) gin_trgm_ops);
+create index songs_name_trgm_idx on songs using gin ((lower(translate("group" || ' ' || song, 'АаВЕеКМНОоРрСсТУуХхІіЈјЅѕ', 'AaBEeKMHOoPpCcTYyXxIiJjSs'))) gin_trgm_ops);
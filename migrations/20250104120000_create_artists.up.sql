@@ -0,0 +1,29 @@
+create table artists (
+    id varchar primary key,
+    name varchar not null,
+    country varchar not null default '',
+    formed_year integer not null default 0,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+-- canonical names and aliases share namespace, any of them resolves to artist
+create table artist_names (
+    artist_id varchar not null references artists (id) on delete cascade,
+    name varchar not null,
+    primary key (artist_id, name)
+);
+
+create unique index artist_names_idx on artist_names (lower(name));
+
+alter table songs add column artist_id varchar references artists (id);
+
+create index songs_artist_idx on songs (artist_id);
+
+-- group values differing by case only become single artist
+insert into artists (id, name)
+select gen_random_uuid()::varchar, min("group") from songs group by lower("group");
+
+insert into artist_names (artist_id, name) select id, name from artists;
+
+update songs set artist_id=a.id from artists a where lower(a.name)=lower(songs."group");
@@ -0,0 +1,3 @@
+drop index songs_track_idx;
+alter table songs drop column track, drop column album_id;
+drop table albums;
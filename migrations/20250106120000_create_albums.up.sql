@@ -0,0 +1,15 @@
+create table albums (
+    id varchar primary key,
+    title varchar not null,
+    artist_id varchar not null references artists (id),
+    release_date date,
+    cover_link varchar not null default '',
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now()
+);
+
+create unique index albums_idx on albums (artist_id, title);
+
+alter table songs add column album_id varchar references albums (id), add column track integer;
+
+create unique index songs_track_idx on songs (album_id, track);
@@ -0,0 +1,16 @@
+create table tags (
+    id varchar primary key,
+    name varchar not null,
+    kind varchar not null default 'custom',
+    created_at timestamptz not null default now()
+);
+
+create unique index tags_idx on tags (lower(name));
+
+create table song_tags (
+    song_id varchar not null references songs (id) on delete cascade,
+    tag_id varchar not null references tags (id) on delete cascade,
+    primary key (song_id, tag_id)
+);
+
+create index song_tags_tag_idx on song_tags (tag_id);
@@ -0,0 +1,7 @@
+-- only key hashes are kept
+create table api_keys (
+    id varchar primary key,
+    name varchar not null,
+    hash varchar not null unique,
+    created_at timestamptz not null default now()
+);
@@ -0,0 +1,3 @@
+-- keys added before roles keep full access
+alter table api_keys add column role varchar not null default 'admin';
+alter table api_keys alter column role drop default;
@@ -0,0 +1,10 @@
+// Package migrations embeds database migrations into server binary.
+package migrations
+
+import "embed"
+
+// FS contains postgres migrations in root directory and sqlite
+// migrations in sqlite directory.
+//
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,11 @@
+create table songs (
+    id text primary key,
+    "group" text not null,
+    song text not null,
+    release_date date,
+    text text,
+    link text,
+    deleted boolean not null default false
+);
+
+create unique index songs_idx on songs ("group", song);
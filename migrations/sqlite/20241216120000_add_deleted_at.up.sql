@@ -0,0 +1,3 @@
+alter table songs add column deleted_at datetime;
+
+update songs set deleted_at=strftime('%Y-%m-%d %H:%M:%S+00:00', 'now') where deleted;
@@ -0,0 +1,12 @@
+create table song_revisions (
+    song_id text not null references songs (id) on delete cascade,
+    rev integer not null,
+    "group" text not null,
+    song text not null,
+    release_date date,
+    text text,
+    link text,
+    action text not null,
+    created_at datetime not null,
+    primary key (song_id, rev)
+);
@@ -0,0 +1,4 @@
+alter table songs add column created_at datetime;
+alter table songs add column updated_at datetime;
+
+update songs set created_at=strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'), updated_at=strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
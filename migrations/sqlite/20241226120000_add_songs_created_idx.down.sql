@@ -0,0 +1 @@
+drop index songs_created_idx;
@@ -0,0 +1,4 @@
+drop index songs_artist_idx;
+alter table songs drop column artist_id;
+drop table artist_names;
+drop table artists;
@@ -0,0 +1,34 @@
+create table artists (
+    id text primary key,
+    name text not null,
+    country text not null default '',
+    formed_year integer not null default 0,
+    created_at datetime not null,
+    updated_at datetime not null
+);
+
+-- canonical names and aliases share namespace, any of them resolves to
+-- artist, nocase folds ascii letters only
+create table artist_names (
+    artist_id text not null references artists (id) on delete cascade,
+    name text not null collate nocase,
+    primary key (artist_id, name)
+);
+
+create unique index artist_names_idx on artist_names (name);
+
+-- no foreign key, sqlite can not drop referencing column
+alter table songs add column artist_id text;
+
+create index songs_artist_idx on songs (artist_id);
+
+-- group values differing by case only become single artist
+insert into artists (id, name, created_at, updated_at)
+select lower(substr(h, 1, 8) || '-' || substr(h, 9, 4) || '-' || substr(h, 13, 4) || '-' ||
+        substr(h, 17, 4) || '-' || substr(h, 21)), name,
+    strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%S+00:00', 'now')
+from (select hex(randomblob(16)) as h, min("group") as name from songs group by "group" collate nocase);
+
+insert into artist_names (artist_id, name) select id, name from artists;
+
+update songs set artist_id=(select artist_id from artist_names n where n.name=songs."group");
@@ -0,0 +1,4 @@
+drop index songs_track_idx;
+alter table songs drop column track;
+alter table songs drop column album_id;
+drop table albums;
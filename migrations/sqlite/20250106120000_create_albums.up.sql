@@ -0,0 +1,17 @@
+create table albums (
+    id text primary key,
+    title text not null,
+    artist_id text not null references artists (id),
+    release_date date,
+    cover_link text not null default '',
+    created_at datetime not null,
+    updated_at datetime not null
+);
+
+create unique index albums_idx on albums (artist_id, title);
+
+-- no foreign key, sqlite can not drop referencing column
+alter table songs add column album_id text;
+alter table songs add column track integer;
+
+create unique index songs_track_idx on songs (album_id, track);
@@ -0,0 +1,2 @@
+drop table song_tags;
+drop table tags;
@@ -0,0 +1,17 @@
+-- nocase folds ascii letters only
+create table tags (
+    id text primary key,
+    name text not null collate nocase,
+    kind text not null default 'custom',
+    created_at datetime not null
+);
+
+create unique index tags_idx on tags (name);
+
+create table song_tags (
+    song_id text not null references songs (id) on delete cascade,
+    tag_id text not null references tags (id) on delete cascade,
+    primary key (song_id, tag_id)
+);
+
+create index song_tags_tag_idx on song_tags (tag_id);
@@ -0,0 +1,2 @@
+drop table playlist_songs;
+drop table playlists;
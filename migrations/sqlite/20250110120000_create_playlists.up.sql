@@ -0,0 +1,16 @@
+create table playlists (
+    id text primary key,
+    name text not null,
+    created_at datetime not null,
+    updated_at datetime not null
+);
+
+-- deleted songs keep their positions, restored songs come back in place
+create table playlist_songs (
+    playlist_id text not null references playlists (id) on delete cascade,
+    song_id text not null references songs (id) on delete cascade,
+    position integer not null,
+    primary key (playlist_id, song_id)
+);
+
+create index playlist_songs_position_idx on playlist_songs (playlist_id, position);
@@ -0,0 +1,7 @@
+-- only key hashes are kept
+create table api_keys (
+    id text primary key,
+    name text not null,
+    hash text not null unique,
+    created_at datetime not null
+);
@@ -0,0 +1,2 @@
+-- keys added before roles keep full access
+alter table api_keys add column role text not null default 'admin';
@@ -17,55 +17,71 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/song": {
-            "post": {
-                "description": "Add song to library",
-                "consumes": [
-                    "application/json"
+        "/albums": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get albums ordered by title for certain page and page size",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Add song",
+                "summary": "Get albums",
                 "parameters": [
                     {
-                        "description": "Add song",
-                        "name": "song",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RequestAddSong"
-                        }
+                        "type": "string",
+                        "description": "Artist id",
+                        "name": "artist_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Song added",
+                        "description": "Albums list",
                         "schema": {
-                            "$ref": "#/definitions/models.Song"
+                            "$ref": "#/definitions/models.ResponseGetAlbums"
                         }
                     },
                     "400": {
                         "description": "Bad request"
                     },
-                    "409": {
-                        "description": "Song already exists"
-                    },
-                    "410": {
-                        "description": "Song already deleted"
-                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
-            }
-        },
-        "/song/{id}": {
-            "put": {
-                "description": "Update song in library",
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add album of artist, artist alias is resolved and missing artist is created",
                 "consumes": [
                     "application/json"
                 ],
@@ -73,63 +89,81 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Update song",
+                "summary": "Add album",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Song id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Update song",
-                        "name": "song",
+                        "description": "Add album",
+                        "name": "album",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.RequestUpdateSong"
+                            "$ref": "#/definitions/models.RequestAddAlbum"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Song updated"
-                    },
-                    "204": {
-                        "description": "Song not found"
+                    "201": {
+                        "description": "Album added",
+                        "schema": {
+                            "$ref": "#/definitions/models.Album"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Album URI"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad request"
                     },
+                    "409": {
+                        "description": "Album of artist already exists"
+                    },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete song from library",
+            }
+        },
+        "/albums/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get album with artist name",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Delete song",
+                "summary": "Get album",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Song id",
+                        "description": "Album id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Song deleted"
+                    "200": {
+                        "description": "Album",
+                        "schema": {
+                            "$ref": "#/definitions/models.Album"
+                        }
                     },
                     "204": {
-                        "description": "Song not found"
+                        "description": "Album not found"
                     },
                     "500": {
                         "description": "Internal server error"
@@ -137,106 +171,121 @@ const docTemplate = `{
                 }
             }
         },
-        "/song/{id}/text": {
+        "/albums/{id}/tracks": {
             "get": {
-                "description": "Get song text for certain page and page size",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get album with songs ordered by track number",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Get song text",
+                "summary": "Get album tracks",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Song id",
+                        "description": "Album id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 3,
-                        "description": "Page size",
-                        "name": "size",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Song text",
+                        "description": "Album tracks",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseGetSongText"
+                            "$ref": "#/definitions/models.ResponseGetTracks"
                         }
                     },
                     "204": {
-                        "description": "Song not found"
-                    },
-                    "400": {
-                        "description": "Bad request"
+                        "description": "Album not found"
                     },
                     "500": {
                         "description": "Internal server error"
                     }
                 }
-            }
-        },
-        "/songs": {
-            "get": {
-                "description": "Get filtered songs list for certain page and page size",
-                "produces": [
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Place existing song on album position, song is moved from its previous album",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "Songs"
+                    "Albums"
                 ],
-                "summary": "Get songs",
+                "summary": "Attach album track",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Song id",
+                        "description": "Album id",
                         "name": "id",
-                        "in": "query"
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Group",
-                        "name": "group",
-                        "in": "query"
+                        "description": "Attach track",
+                        "name": "track",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestAttachTrack"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Track attached"
                     },
-                    {
-                        "type": "string",
-                        "description": "Song",
-                        "name": "song",
-                        "in": "query"
+                    "204": {
+                        "description": "Album or song not found"
                     },
-                    {
-                        "type": "string",
-                        "default": "16.07.2006",
-                        "description": "Release date",
-                        "name": "release_date",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad request"
                     },
-                    {
-                        "type": "string",
-                        "description": "Text",
-                        "name": "text",
-                        "in": "query"
+                    "409": {
+                        "description": "Album position taken by other song"
                     },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/artists": {
+            "get": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Link",
-                        "name": "link",
-                        "in": "query"
+                        "ApiKeyAuth": []
                     },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get artists ordered by name for certain page and page size",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Get artists",
+                "parameters": [
                     {
                         "type": "integer",
                         "default": 1,
@@ -254,9 +303,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Songs list",
+                        "description": "Artists list",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseGetSongs"
+                            "$ref": "#/definitions/models.ResponseGetArtists"
                         }
                     },
                     "400": {
@@ -266,38 +315,2174 @@ const docTemplate = `{
                         "description": "Internal server error"
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "models.RequestAddSong": {
-            "type": "object",
-            "properties": {
-                "group": {
-                    "type": "string",
-                    "example": "Muse"
-                },
-                "song": {
-                    "type": "string",
-                    "example": "Supermassive Black Hole"
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add artist with aliases, names and aliases are unique ignoring case",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Add artist",
+                "parameters": [
+                    {
+                        "description": "Add artist",
+                        "name": "artist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestArtist"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Artist added",
+                        "schema": {
+                            "$ref": "#/definitions/models.Artist"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Artist URI"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "Artist name or alias already exists"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
                 }
             }
         },
-        "models.RequestUpdateSong": {
-            "type": "object",
-            "properties": {
-                "link": {
-                    "type": "string",
-                    "example": "https://www.youtube.com/watch?v=Xsp3_a-PMTw"
-                },
-                "release_date": {
+        "/artists/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get artist with aliases",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Get artist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Artist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Artist",
+                        "schema": {
+                            "$ref": "#/definitions/models.Artist"
+                        }
+                    },
+                    "204": {
+                        "description": "Artist not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace artist data and aliases, songs of renamed artist get new group",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Update artist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Artist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update artist",
+                        "name": "artist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestArtist"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Artist updated"
+                    },
+                    "204": {
+                        "description": "Artist not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "Artist name, alias or renamed song already exists"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete artist without songs, deleted songs included",
+                "tags": [
+                    "Artists"
+                ],
+                "summary": "Delete artist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Artist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Artist deleted"
+                    },
+                    "204": {
+                        "description": "Artist not found"
+                    },
+                    "409": {
+                        "description": "Artist has songs"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/playlists": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get playlists ordered by name for certain page and page size",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Get playlists",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlists list",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseGetPlaylists"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add empty playlist",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Add playlist",
+                "parameters": [
+                    {
+                        "description": "Add playlist",
+                        "name": "playlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestPlaylist"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Playlist added",
+                        "schema": {
+                            "$ref": "#/definitions/models.Playlist"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Playlist URI"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/playlists/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get playlist with songs in playlist order for certain page and page size, deleted songs are not listed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Get playlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist songs",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseGetPlaylist"
+                        }
+                    },
+                    "204": {
+                        "description": "Playlist not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change playlist name",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Rename playlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rename playlist",
+                        "name": "playlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestPlaylist"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Playlist renamed"
+                    },
+                    "204": {
+                        "description": "Playlist not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete playlist, its songs are kept",
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Delete playlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Playlist deleted"
+                    },
+                    "204": {
+                        "description": "Playlist not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/playlists/{id}/songs": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Put song on one based playlist position shifting following songs, missing position appends song",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Add playlist song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Add playlist song",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestPlaylistSong"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song added"
+                    },
+                    "204": {
+                        "description": "Playlist or song not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "Song already in playlist"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/playlists/{id}/songs/{song_id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move song to one based playlist position, position past last song moves song to the end",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Move playlist song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Move playlist song",
+                        "name": "position",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestMovePlaylistSong"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song moved"
+                    },
+                    "204": {
+                        "description": "Playlist or song not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove song from playlist shifting following songs, song is kept",
+                "tags": [
+                    "Playlists"
+                ],
+                "summary": "Remove playlist song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Playlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "song_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song removed"
+                    },
+                    "204": {
+                        "description": "Playlist or song not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Full text search by lyrics, group and song ranked by relevance, supports \"quoted phrases\", or and -excluded words",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Search songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"soul alight\" or glaciers",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "english",
+                            "russian"
+                        ],
+                        "type": "string",
+                        "default": "english",
+                        "description": "Search language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Found songs with highlighted verses",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    },
+                    "501": {
+                        "description": "Not implemented by storage"
+                    }
+                }
+            }
+        },
+        "/song": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add song to library, group alias is replaced with artist name and missing artist is created",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Add song",
+                "parameters": [
+                    {
+                        "description": "Add song",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestAddSong"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Add song despite near duplicates",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Song added",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Song URI"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "Song already exists, near duplicates are listed unless force is set",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseNearDuplicates"
+                        }
+                    },
+                    "410": {
+                        "description": "Song already deleted"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/song/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get song from library",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Get song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cached song ETag",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song",
+                        "schema": {
+                            "$ref": "#/definitions/models.Song"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Song version"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Song not found"
+                    },
+                    "304": {
+                        "description": "Song not modified"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update song in library",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Update song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update song",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestUpdateSong"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected song ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song updated"
+                    },
+                    "204": {
+                        "description": "Song not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "412": {
+                        "description": "Song version mismatch or song not found with If-Match"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete song from library, purged song is removed permanently",
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Delete song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Remove permanently",
+                        "name": "purge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected song ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song deleted"
+                    },
+                    "204": {
+                        "description": "Song not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "412": {
+                        "description": "Song version mismatch or song not found with If-Match"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Partially update song in library with JSON merge patch, renaming included",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Patch song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Patch song",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestPatchSong"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected song ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song updated"
+                    },
+                    "204": {
+                        "description": "Song not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "Song already exists"
+                    },
+                    "412": {
+                        "description": "Song version mismatch or song not found with If-Match"
+                    },
+                    "415": {
+                        "description": "Unsupported media type"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/song/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore deleted song to library",
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Restore song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song restored"
+                    },
+                    "204": {
+                        "description": "Deleted song not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/song/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get song states replaced by updates, deletes and restores",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Revisions"
+                ],
+                "summary": "Get song revisions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song revisions",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseGetRevisions"
+                        }
+                    },
+                    "204": {
+                        "description": "Song not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/song/{id}/revisions/{rev}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get song state replaced by update, delete or restore",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Revisions"
+                ],
+                "summary": "Get song revision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song revision",
+                        "schema": {
+                            "$ref": "#/definitions/models.Revision"
+                        }
+                    },
+                    "204": {
+                        "description": "Revision not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/song/{id}/revisions/{rev}/diff": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get line based lyrics diff from revision to other revision or current song",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Revisions"
+                ],
+                "summary": "Get lyrics diff",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Other revision number, current song if omitted",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lyrics diff",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseGetDiff"
+                        }
+                    },
+                    "204": {
+                        "description": "Revision not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/song/{id}/revisions/{rev}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Roll song group, name, release date, text and link back to revision, current state is saved as new revision",
+                "tags": [
+                    "Revisions"
+                ],
+                "summary": "Revert song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song reverted"
+                    },
+                    "204": {
+                        "description": "Song or revision not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "409": {
+                        "description": "Song with reverted name already exists"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/song/{id}/tags": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add tags to song, missing tags are created of request kind, custom by default",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Tag song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Song tags",
+                        "name": "tags",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestSongTags"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song tagged"
+                    },
+                    "204": {
+                        "description": "Song not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove tags from song, tags are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Untag song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Song tags",
+                        "name": "tags",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestSongTags"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Song untagged"
+                    },
+                    "204": {
+                        "description": "Song not found"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/song/{id}/text": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get song text for certain page and page size",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Get song text",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 3,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cached song ETag",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Song text",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseGetSongText"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Song version"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "Song not found"
+                    },
+                    "304": {
+                        "description": "Song not modified"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/songs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get filtered songs list for certain page and page size",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Get songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Song id",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group, exact match resolves artist aliases",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Artist id",
+                        "name": "artist_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Album id",
+                        "name": "album_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Song",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "16.07.2006",
+                        "description": "Release date, dd.mm.yyyy or yyyy-mm-dd",
+                        "name": "release_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Released after date, dd.mm.yyyy or yyyy-mm-dd",
+                        "name": "released_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Released before date, dd.mm.yyyy or yyyy-mm-dd",
+                        "name": "released_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Release year",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1990,
+                        "description": "Release decade first year",
+                        "name": "decade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "prefix",
+                            "contains",
+                            "ci"
+                        ],
+                        "type": "string",
+                        "default": "exact",
+                        "description": "Group match mode, ci is case insensitive contains",
+                        "name": "group_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "prefix",
+                            "contains",
+                            "ci"
+                        ],
+                        "type": "string",
+                        "default": "exact",
+                        "description": "Song match mode, ci is case insensitive contains",
+                        "name": "song_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "prefix",
+                            "contains",
+                            "ci"
+                        ],
+                        "type": "string",
+                        "default": "contains",
+                        "description": "Text match mode, ci is case insensitive contains",
+                        "name": "text_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated tags",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "Tags match mode, songs having any or all of tags",
+                        "name": "tags_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exclude",
+                            "include",
+                            "only"
+                        ],
+                        "type": "string",
+                        "default": "exclude",
+                        "description": "Deleted songs",
+                        "name": "deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count songs without fetching them",
+                        "name": "count_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "Sort columns group, song, release_date, created_at, updated_at, minus prefix for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Next page cursor, replaces page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cached song ETag, used with id",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Songs list",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseGetSongs"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Song version, set with id"
+                            },
+                            "Link": {
+                                "type": "string",
+                                "description": "Next and previous pages links"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Song not modified"
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/songs/suggest": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Typo tolerant lookup of songs by group and song names using trigram similarity, cyrillic letters looking like latin ones are treated as latin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Songs"
+                ],
+                "summary": "Suggest songs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "Supermasive",
+                        "description": "Group or song name",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Maximum number of suggestions",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Similar songs, most similar first",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSuggest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get tags ordered by name with numbers of songs tagged",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Get tags",
+                "parameters": [
+                    {
+                        "enum": [
+                            "genre",
+                            "mood",
+                            "language",
+                            "custom"
+                        ],
+                        "type": "string",
+                        "description": "Tag kind",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tags list",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseGetTags"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.Album": {
+            "type": "object",
+            "properties": {
+                "artist": {
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "artist_id": {
+                    "type": "string",
+                    "example": "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"
+                },
+                "cover_link": {
+                    "type": "string",
+                    "example": "https://example.com/covers/bhar.jpg"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2025-01-06T12:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9c3f6f1e-8d2b-4b8a-a6f4-1b6c2d7e8f90"
+                },
+                "release_date": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2006-07-03T00:00:00Z"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Black Holes and Revelations"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2025-01-06T12:00:00Z"
+                }
+            }
+        },
+        "models.Artist": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Beatles",
+                        "the beatles"
+                    ]
+                },
+                "country": {
+                    "type": "string",
+                    "example": "GB"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2025-01-04T12:00:00Z"
+                },
+                "formed_year": {
+                    "type": "integer",
+                    "example": 1960
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "The Beatles"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2025-01-04T12:00:00Z"
+                }
+            }
+        },
+        "models.DiffLine": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "equal",
+                        "insert",
+                        "delete"
+                    ],
+                    "example": "insert"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Ooh baby, can you hear me moan?"
+                }
+            }
+        },
+        "models.Playlist": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2025-01-10T12:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f2b9c1d-6a4e-4c7b-8e2f-5d1a9b0c7e64"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Road trip"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2025-01-10T12:00:00Z"
+                }
+            }
+        },
+        "models.RequestAddAlbum": {
+            "type": "object",
+            "properties": {
+                "artist": {
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "cover_link": {
+                    "type": "string",
+                    "example": "https://example.com/covers/bhar.jpg"
+                },
+                "release_date": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2006-07-03T00:00:00Z"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Black Holes and Revelations"
+                }
+            }
+        },
+        "models.RequestAddSong": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "song": {
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                }
+            }
+        },
+        "models.RequestArtist": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Beatles",
+                        "the beatles"
+                    ]
+                },
+                "country": {
+                    "type": "string",
+                    "example": "GB"
+                },
+                "formed_year": {
+                    "type": "integer",
+                    "example": 1960
+                },
+                "name": {
+                    "type": "string",
+                    "example": "The Beatles"
+                }
+            }
+        },
+        "models.RequestAttachTrack": {
+            "type": "object",
+            "properties": {
+                "song_id": {
+                    "type": "string",
+                    "example": "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+                },
+                "track": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.RequestMovePlaylistSong": {
+            "type": "object",
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.RequestPatchSong": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "link": {
+                    "type": "string",
+                    "example": "https://www.youtube.com/watch?v=Xsp3_a-PMTw"
+                },
+                "release_date": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2006-07-16T00:00:00Z"
+                },
+                "song": {
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Ooh baby, don't you know I suffer?"
+                }
+            }
+        },
+        "models.RequestPlaylist": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Road trip"
+                }
+            }
+        },
+        "models.RequestPlaylistSong": {
+            "type": "object",
+            "properties": {
+                "position": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "song_id": {
+                    "type": "string",
+                    "example": "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+                }
+            }
+        },
+        "models.RequestSongTags": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "genre",
+                        "mood",
+                        "language",
+                        "custom"
+                    ],
+                    "example": "genre"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rock",
+                        "english"
+                    ]
+                }
+            }
+        },
+        "models.RequestUpdateSong": {
+            "type": "object",
+            "properties": {
+                "link": {
+                    "type": "string",
+                    "example": "https://www.youtube.com/watch?v=Xsp3_a-PMTw"
+                },
+                "release_date": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2006-07-16T00:00:00Z"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight"
+                }
+            }
+        },
+        "models.ResponseGetAlbums": {
+            "type": "object",
+            "properties": {
+                "albums": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Album"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pages": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.ResponseGetArtists": {
+            "type": "object",
+            "properties": {
+                "artists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Artist"
+                    }
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pages": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.ResponseGetDiff": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
                     "type": "string",
-                    "format": "RFC3339",
-                    "example": "2006-07-16T00:00:00Z"
+                    "example": "ca1da5fa-50ee-4d00-82e9-d6a578419ad7"
                 },
-                "text": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DiffLine"
+                    }
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.ResponseGetPlaylist": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pages": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "playlist": {
+                    "$ref": "#/definitions/models.Playlist"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.ResponseGetPlaylists": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pages": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "playlists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Playlist"
+                    }
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.ResponseGetRevisions": {
+            "type": "object",
+            "properties": {
+                "id": {
                     "type": "string",
-                    "example": "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight"
+                    "example": "ca1da5fa-50ee-4d00-82e9-d6a578419ad7"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Revision"
+                    }
                 }
             }
         },
@@ -337,16 +2522,35 @@ const docTemplate = `{
                         "Ooh baby don't you know I suffer?\nOoh baby can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?",
                         "Ooh\nYou set my soul alight\nOoh\nYou set my soul alight"
                     ]
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
         "models.ResponseGetSongs": {
             "type": "object",
             "properties": {
+                "next": {
+                    "type": "string",
+                    "example": "/api/songs?page=2\u0026size=10"
+                },
+                "next_cursor": {
+                    "description": "NextCursor is opaque cursor of next page, empty on last page.",
+                    "type": "string"
+                },
                 "page": {
                     "type": "integer",
                     "example": 1
                 },
+                "pages": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "prev": {
+                    "type": "string"
+                },
                 "size": {
                     "type": "integer",
                     "example": 10
@@ -356,12 +2560,180 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/models.Song"
                     }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "models.ResponseGetTags": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Tag"
+                    }
+                }
+            }
+        },
+        "models.ResponseGetTracks": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "$ref": "#/definitions/models.Album"
+                },
+                "tracks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Song"
+                    }
+                }
+            }
+        },
+        "models.ResponseNearDuplicates": {
+            "type": "object",
+            "properties": {
+                "near_duplicates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Suggestion"
+                    }
+                }
+            }
+        },
+        "models.ResponseSearch": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SearchHit"
+                    }
+                },
+                "lang": {
+                    "type": "string",
+                    "example": "english"
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "query": {
+                    "type": "string",
+                    "example": "\"soul alight\""
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.ResponseSuggest": {
+            "type": "object",
+            "properties": {
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Suggestion"
+                    }
+                }
+            }
+        },
+        "models.Revision": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2024-12-20T12:00:00Z"
+                },
+                "group": {
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "link": {
+                    "type": "string",
+                    "example": "https://www.youtube.com/watch?v=Xsp3_a-PMTw"
+                },
+                "release_date": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2006-07-16T00:00:00Z"
+                },
+                "rev": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "song": {
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?"
+                }
+            }
+        },
+        "models.SearchHit": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "headline": {
+                    "type": "string",
+                    "example": "Ooh \u003cmark\u003ebaby\u003c/mark\u003e, don't you know I suffer?"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+                },
+                "rank": {
+                    "type": "number",
+                    "example": 0.6079271
+                },
+                "song": {
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
                 }
             }
         },
         "models.Song": {
             "type": "object",
             "properties": {
+                "album_id": {
+                    "type": "string",
+                    "example": "9c3f6f1e-8d2b-4b8a-a6f4-1b6c2d7e8f90"
+                },
+                "artist_id": {
+                    "type": "string",
+                    "example": "5b0e8a4c-2f4e-4f55-9b8e-2a7e7a5c9d11"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2024-12-24T12:00:00Z"
+                },
+                "deleted": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2024-12-16T12:00:00Z"
+                },
                 "group": {
                     "type": "string",
                     "example": "Muse"
@@ -383,17 +2755,116 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Supermassive Black Hole"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rock",
+                        "english"
+                    ]
+                },
                 "text": {
                     "type": "string",
                     "example": "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight"
+                },
+                "track": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "RFC3339",
+                    "example": "2024-12-24T12:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.Suggestion": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "example": "Muse"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0824f9fb-7397-4f19-95d5-f9ce8bec75de"
+                },
+                "score": {
+                    "type": "number",
+                    "example": 0.88
+                },
+                "song": {
+                    "type": "string",
+                    "example": "Supermassive Black Hole"
+                }
+            }
+        },
+        "models.Tag": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "type": "string",
+                    "example": "genre"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "rock"
+                },
+                "songs": {
+                    "type": "integer",
+                    "example": 42
                 }
             }
         }
     },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key of reader, editor or admin role, calls not allowed for role get 403.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "\"Bearer\" followed by JWT verified by configured JWKS, role claim grants reader, editor or admin role.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
+    },
     "tags": [
         {
             "description": "\"Songs requests group.\"",
             "name": "Songs"
+        },
+        {
+            "description": "\"Song revisions requests group.\"",
+            "name": "Revisions"
+        },
+        {
+            "description": "\"Lyrics search requests group.\"",
+            "name": "Search"
+        },
+        {
+            "description": "\"Artists requests group.\"",
+            "name": "Artists"
+        },
+        {
+            "description": "\"Albums requests group.\"",
+            "name": "Albums"
+        },
+        {
+            "description": "\"Song tags requests group.\"",
+            "name": "Tags"
+        },
+        {
+            "description": "\"Playlists requests group.\"",
+            "name": "Playlists"
         }
     ]
 }`